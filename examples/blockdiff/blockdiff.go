@@ -0,0 +1,161 @@
+// Command blockdiff, compare two files with blocks and text
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/inetio"
+)
+
+var description = `
+Compare two files with records of blocks and text (separated by comma) and print the differences:
+
++ block text                      added
+- block text                      removed
+~ block old text -> new text      renamed, same block with new text
+> old block -> new block text     resized, overlapping block with same text
+
+Exit status is 0 if the files are equivalent, 1 if there are differences and 2 on errors,
+e.g. for CI validation of IP plan changes.
+`
+
+type record struct {
+	b inet.Block
+	t string
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 2 {
+		usage()
+	}
+
+	before := mustRead(flag.Arg(0))
+	after := mustRead(flag.Arg(1))
+
+	lines := diff(before, after)
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+
+	if len(lines) > 0 {
+		os.Exit(1)
+	}
+}
+
+// diff returns the differences between before and after, sorted by block
+func diff(before, after map[inet.Block]string) []string {
+	var added, removed []record
+	var out []record
+	var lines = make(map[record]string)
+
+	for b, t := range before {
+		nt, ok := after[b]
+		switch {
+		case !ok:
+			removed = append(removed, record{b, t})
+		case nt != t:
+			r := record{b, t}
+			out = append(out, r)
+			lines[r] = fmt.Sprintf("~ %s %s -> %s", b, t, nt)
+		}
+	}
+
+	for b, t := range after {
+		if _, ok := before[b]; !ok {
+			added = append(added, record{b, t})
+		}
+	}
+
+	sortRecords(removed)
+	sortRecords(added)
+
+	// pair removed and added blocks with same text and overlapping space as resized
+	for _, r := range removed {
+		resized := false
+		for i, a := range added {
+			if a.t == r.t && !a.b.IsDisjunct(r.b) {
+				lines[r] = fmt.Sprintf("> %s -> %s %s", r.b, a.b, a.t)
+				added = append(added[:i], added[i+1:]...)
+				resized = true
+				break
+			}
+		}
+		if !resized {
+			lines[r] = fmt.Sprintf("- %s %s", r.b, r.t)
+		}
+		out = append(out, r)
+	}
+
+	for _, a := range added {
+		lines[a] = fmt.Sprintf("+ %s %s", a.b, a.t)
+		out = append(out, a)
+	}
+
+	sortRecords(out)
+
+	var result []string
+	for _, r := range out {
+		result = append(result, strings.TrimSpace(lines[r]))
+	}
+	return result
+}
+
+func sortRecords(rs []record) {
+	sort.Slice(rs, func(i, j int) bool { return rs[i].b.Less(rs[j].b) })
+}
+
+// mustRead reads the file as block/text map, exits with status 2 on errors
+func mustRead(name string) map[inet.Block]string {
+	f, err := os.Open(name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		os.Exit(2)
+	}
+	defer f.Close()
+
+	m, err := readData(f)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %s: %v\n", name, err)
+		os.Exit(2)
+	}
+	return m
+}
+
+// input records as CSV data:
+// block, text...
+//
+// Duplicate blocks and read errors are fatal, only malformed records are skipped.
+func readData(in io.Reader) (map[inet.Block]string, error) {
+	recs, errs := inetio.ReadBlocksCSV(in, inetio.WithDuplicates(inetio.RejectDups))
+	for _, err := range errs {
+		if errors.Is(err, inetio.ErrDuplicate) || errors.Is(err, inetio.ErrRead) {
+			return nil, err
+		}
+		log.Printf("skip record: %v", err)
+	}
+
+	out := make(map[inet.Block]string, len(recs))
+	for _, r := range recs {
+		out[r.Block] = r.Text
+	}
+	return out, nil
+}
+
+// just the usage
+func usage() {
+	w := flag.CommandLine.Output()
+	fmt.Fprintf(w, "Usage: %s old.csv new.csv\n\n", os.Args[0])
+	flag.PrintDefaults()
+	fmt.Fprintln(w, description)
+	os.Exit(2)
+}
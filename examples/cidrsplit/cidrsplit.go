@@ -0,0 +1,144 @@
+// Command cidrsplit, split a CIDR into subnets of equal size
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+)
+
+var flagCount = flag.Int("n", 0, "split into at least `N` subnets")
+var flagHosts = flag.Uint64("H", 0, "split into subnets holding at least `hosts` usable hosts each")
+
+var description = `
+Split the CIDR into 2^bits subnets of equal size and print them, one per line.
+With more bits, the subnets are split again level by level and printed indented.
+Instead of bits, the split may also be defined by the flags:
+  -n N       the smallest split into at least N subnets
+  -H hosts   the biggest split into subnets with at least 'hosts' usable hosts each
+
+Example:
+$ cidrsplit 10.0.0.0/24 2
+10.0.0.0/26
+10.0.0.64/26
+10.0.0.128/26
+10.0.0.192/26
+
+$ cidrsplit 10.0.0.0/24 1 1
+10.0.0.0/25
+  10.0.0.0/26
+  10.0.0.64/26
+10.0.0.128/25
+  10.0.0.128/26
+  10.0.0.192/26
+
+$ cidrsplit -H 50 10.0.0.0/24
+10.0.0.0/26
+10.0.0.64/26
+10.0.0.128/26
+10.0.0.192/26
+`
+
+func main() {
+	block, bits := checkCmdline()
+
+	subnets, err := block.SplitRecursive(bits...)
+	if err != nil {
+		fatal(err)
+	}
+
+	// indent by level, the prefix length grows with every level
+	level := make(map[int]int)
+	for i, n := 0, block.Bits(); i < len(bits); i++ {
+		n += bits[i]
+		level[n] = i
+	}
+	for _, b := range subnets {
+		fmt.Println(strings.Repeat("  ", level[b.Bits()]) + b.String())
+	}
+}
+
+// splitBits returns the number of bits needed for at least count subnets
+func splitBits(count int) int {
+	return bits.Len(uint(count - 1))
+}
+
+// hostBits returns the biggest number of bits for subnets with at least hosts usable hosts
+func hostBits(b inet.Block, hosts uint64) (int, error) {
+	want := new(big.Int).SetUint64(hosts)
+	if b.UsableHosts().Cmp(want) < 0 {
+		return 0, fmt.Errorf("%v has less than %d usable hosts", b, hosts)
+	}
+
+	// halve the first subnet until it gets too small
+	n := 0
+	for sub := b; ; n++ {
+		halves, err := sub.SplitCIDR(1)
+		if err != nil || halves[0].UsableHosts().Cmp(want) < 0 {
+			return n, nil
+		}
+		sub = halves[0]
+	}
+}
+
+// check flags and arguments
+func checkCmdline() (inet.Block, []int) {
+	flag.Usage = usage
+	flag.Parse()
+
+	modes := 0
+	for _, set := range []bool{*flagCount > 0, *flagHosts > 0, flag.NArg() >= 2} {
+		if set {
+			modes++
+		}
+	}
+	if flag.NArg() < 1 || modes != 1 {
+		usage()
+	}
+
+	block, err := inet.ParseBlock(flag.Arg(0))
+	if err != nil || !block.IsCIDR() {
+		fatal(fmt.Errorf("wrong CIDR '%s'", flag.Arg(0)))
+	}
+
+	switch {
+	case *flagCount > 0:
+		return block, []int{splitBits(*flagCount)}
+	case *flagHosts > 0:
+		n, err := hostBits(block, *flagHosts)
+		if err != nil {
+			fatal(err)
+		}
+		return block, []int{n}
+	}
+
+	var bits []int
+	for _, arg := range flag.Args()[1:] {
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			fatal(fmt.Errorf("wrong bits '%s'", arg))
+		}
+		bits = append(bits, n)
+	}
+	return block, bits
+}
+
+func fatal(err error) {
+	fmt.Fprintf(flag.CommandLine.Output(), "ERROR: %v\n", err)
+	os.Exit(1)
+}
+
+// just the usage
+func usage() {
+	w := flag.CommandLine.Output()
+	fmt.Fprintf(w, "Usage: %s [-n N | -H hosts] CIDR [bits]...\n\n", os.Args[0])
+	flag.PrintDefaults()
+	fmt.Fprintln(w, description)
+	os.Exit(1)
+}
@@ -0,0 +1,93 @@
+// Command free, print the free CIDRs under an outer block
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/inetio"
+)
+
+var flagMin = flag.String("min", "", "print only free CIDRs of at least this size, e.g. /24")
+
+var description = `
+Read allocated blocks, one record per line with the block as first CSV field, from STDIN
+and print the free CIDRs under the outer block, one per line in ascending order.
+
+Example:
+$ printf "10.0.0.0/26\n10.0.0.128/25\n" | free -min /27 10.0.0.0/24
+10.0.0.64/26
+`
+
+func main() {
+	outer, minBits := checkCmdline()
+
+	allocated := readData(os.Stdin)
+	inet.SortBlocks(allocated)
+
+	for _, gap := range inet.Gaps(outer, allocated) {
+		for _, cidr := range gap.CIDRs() {
+			if cidr.Bits() <= minBits {
+				fmt.Println(cidr)
+			}
+		}
+	}
+}
+
+// input records as CSV data:
+// block, text...
+func readData(in io.Reader) []inet.Block {
+	recs, errs := inetio.ReadBlocksCSV(in)
+	for _, err := range errs {
+		log.Printf("skip record: %v", err)
+	}
+
+	out := make([]inet.Block, 0, len(recs))
+	for _, r := range recs {
+		out = append(out, r.Block)
+	}
+	return out
+}
+
+// check flags and arguments, returns the outer block and the max prefix length
+func checkCmdline() (inet.Block, int) {
+	flag.Usage = usage
+	flag.Parse()
+	w := flag.CommandLine.Output()
+
+	if flag.NArg() != 1 {
+		usage()
+	}
+
+	outer, err := inet.ParseBlock(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintf(w, "ERROR: wrong outer block '%s': %v\n\n", flag.Arg(0), err)
+		usage()
+	}
+
+	minBits := 128
+	if *flagMin != "" {
+		minBits, err = strconv.Atoi(strings.TrimPrefix(*flagMin, "/"))
+		if err != nil || minBits < 0 || minBits > 128 {
+			fmt.Fprintf(w, "ERROR: wrong minimum size '%s'\n\n", *flagMin)
+			usage()
+		}
+	}
+
+	return outer, minBits
+}
+
+// just the usage
+func usage() {
+	w := flag.CommandLine.Output()
+	fmt.Fprintf(w, "Usage: %s [-min /bits] outerBlock\n\n", os.Args[0])
+	flag.PrintDefaults()
+	fmt.Fprintln(w, description)
+	os.Exit(1)
+}
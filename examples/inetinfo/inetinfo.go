@@ -0,0 +1,258 @@
+// Command inetinfo, print information about IP addresses and blocks
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+)
+
+var flagJSON = flag.Bool("j", false, "print a JSON object per input")
+
+var description = `
+Print information about IP addresses and blocks given as arguments.
+Without arguments, inetinfo reads one IP address or block per line from STDIN (batch mode),
+empty lines and lines starting with '#' are skipped.
+With the flag -j, a JSON object per input is printed, one per line.
+
+CIDRs are shown with a hint on the alignment of the prefix length:
+nibble aligned (IPv4: octet aligned) as significant part of the expanded base '<</bits',
+else as expanded base '~~/bits'.
+
+IP addresses are classified (loopback, private, cgnat, link-local, multicast, documentation,
+global or special) and the entry of the IANA special-purpose registries is shown.
+
+Example:
+$ inetinfo 10.0.0.3-10.0.0.17 2001:db8::1
+input:     10.0.0.3-10.0.0.17
+version:   4
+block:     10.0.0.3-10.0.0.17
+base:      10.0.0.3
+last:      10.0.0.17
+size:      15
+special:   10.0.0.0/8 Private-Use [RFC1918]
+cidrs:     10.0.0.3/32 10.0.0.4/30 10.0.0.8/29 10.0.0.16/31
+
+input:     2001:db8::1
+version:   6
+ip:        2001:db8::1
+expanded:  2001:0db8:0000:0000:0000:0000:0000:0001
+reverse:   1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2
+ptr:       1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.
+class:     documentation
+net64:     2001:db8::/64
+net48:     2001:db8::/48
+special:   2001:db8::/32 Documentation [RFC3849]
+`
+
+// info is the report for a single input
+type info struct {
+	Input    string   `json:"input"`
+	Error    string   `json:"error,omitempty"`
+	Version  int      `json:"version,omitempty"`
+	IP       string   `json:"ip,omitempty"`
+	Expanded string   `json:"expanded,omitempty"`
+	Reverse  string   `json:"reverse,omitempty"`
+	PTR      string   `json:"ptr,omitempty"`
+	Class    string   `json:"class,omitempty"`
+	Special  string   `json:"special,omitempty"`
+	Net64    string   `json:"net64,omitempty"`
+	Net48    string   `json:"net48,omitempty"`
+	Block    string   `json:"block,omitempty"`
+	Hint     string   `json:"hint,omitempty"`
+	Base     string   `json:"base,omitempty"`
+	Last     string   `json:"last,omitempty"`
+	Size     string   `json:"size,omitempty"`
+	CIDRs    []string `json:"cidrs,omitempty"`
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	failed := false
+	report := func(s string) {
+		i := analyze(s)
+		if i.Error != "" {
+			failed = true
+		}
+		write(os.Stdout, i)
+	}
+
+	if flag.NArg() > 0 {
+		for _, s := range flag.Args() {
+			report(s)
+		}
+	} else {
+		if err := readLines(os.Stdin, report); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// readLines calls fn for every input line, skips empty lines and comments
+func readLines(in io.Reader, fn func(string)) error {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fn(line)
+	}
+	return scanner.Err()
+}
+
+// analyze the input as IP address or block
+func analyze(s string) info {
+	i := info{Input: s}
+
+	if ip, err := inet.ParseIP(s); err == nil {
+		i.IP = ip.String()
+		i.Version = version(ip.Is4())
+		i.Expanded = ip.Expand()
+		i.Reverse = ip.Reverse()
+		i.PTR = ptr(ip)
+		i.Class = classify(ip)
+		i.Special = special(inet.ParseBlock(s))
+		if ip.Is6() {
+			i.Net64 = covering(ip, 64)
+			i.Net48 = covering(ip, 48)
+		}
+		return i
+	}
+
+	b, err := inet.ParseBlock(s)
+	if err != nil {
+		i.Error = err.Error()
+		return i
+	}
+
+	i.Block = b.String()
+	i.Version = version(b.Is4())
+	i.Base = b.Base().String()
+	i.Last = b.Last().String()
+	i.Size = b.Size().String()
+	i.Special = special(b, nil)
+	if b.IsCIDR() {
+		i.Hint = b.HintString()
+	} else {
+		for _, c := range b.CIDRs() {
+			i.CIDRs = append(i.CIDRs, c.String())
+		}
+	}
+	return i
+}
+
+// classify ip, the first matching class wins
+func classify(ip inet.IP) string {
+	switch {
+	case ip.IsLoopback():
+		return "loopback"
+	case ip.IsPrivate():
+		return "private"
+	case ip.IsCGNAT():
+		return "cgnat"
+	case ip.IsLinkLocal():
+		return "link-local"
+	case ip.IsMulticast():
+		return "multicast"
+	case ip.IsDocumentation():
+		return "documentation"
+	case ip.IsGlobal():
+		return "global"
+	}
+	return "special"
+}
+
+// special returns the IANA special-purpose registry entry for b
+func special(b inet.Block, err error) string {
+	if err != nil {
+		return ""
+	}
+	if s, ok := inet.SpecialPurpose(b); ok {
+		return s.String()
+	}
+	return ""
+}
+
+// ptr returns the PTR name for ip
+func ptr(ip inet.IP) string {
+	if ip.Is4() {
+		return ip.Reverse() + ".in-addr.arpa."
+	}
+	return ip.Reverse() + ".ip6.arpa."
+}
+
+// covering returns the CIDR with prefix length bits containing ip
+func covering(ip inet.IP, bits int) string {
+	b, err := ip.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+	return b.String()
+}
+
+func version(is4 bool) int {
+	if is4 {
+		return 4
+	}
+	return 6
+}
+
+// write a record per input, as text or as JSON object
+func write(w io.Writer, i info) {
+	if *flagJSON {
+		if err := json.NewEncoder(w).Encode(i); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	line := func(k, v string) {
+		if v != "" {
+			fmt.Fprintf(w, "%-10s %s\n", k+":", v)
+		}
+	}
+
+	line("input", i.Input)
+	line("error", i.Error)
+	if i.Version != 0 {
+		line("version", fmt.Sprint(i.Version))
+	}
+	line("ip", i.IP)
+	line("expanded", i.Expanded)
+	line("reverse", i.Reverse)
+	line("ptr", i.PTR)
+	line("class", i.Class)
+	line("net64", i.Net64)
+	line("net48", i.Net48)
+	line("block", i.Block)
+	line("hint", i.Hint)
+	line("base", i.Base)
+	line("last", i.Last)
+	line("size", i.Size)
+	line("special", i.Special)
+	line("cidrs", strings.Join(i.CIDRs, " "))
+	fmt.Fprintln(w)
+}
+
+// just the usage
+func usage() {
+	w := flag.CommandLine.Output()
+	fmt.Fprintf(w, "Usage: %s [-j] [ip|block]...\n\n", os.Args[0])
+	flag.PrintDefaults()
+	fmt.Fprintln(w, description)
+	os.Exit(1)
+}
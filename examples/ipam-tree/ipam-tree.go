@@ -1,33 +1,101 @@
-// Command ipam-tree, read blocks from Stdin, print sorted Tree
+// Command ipam-tree, read blocks from Stdin, files or URLs, print sorted Tree
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/csv"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"log"
+	"math/big"
+	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/inetio"
 	"github.com/gaissmai/go-inet/v2/inettree"
 	"github.com/gaissmai/go-inet/v2/tree"
 )
 
 var flagFree = flag.Bool("f", false, "show also free blocks under startBlock")
+var flagOutput = flag.String("o", "text", "output format: text, json or csv")
+var flagUtil = flag.Bool("u", false, "annotate blocks with utilization and free space beneath (text output)")
+var flagWatch = flag.Duration("w", 0, "watch the inputs, re-read them every `interval` and re-render on change, needs -i")
 
-var startBlock inet.Block
+var startBlocks []inet.Block
+var excludes blockList
+var inputs stringList
+
+func init() {
+	flag.Var(&excludes, "x", "exclude blocks covered by `block`, may be repeated")
+	flag.Var(&inputs, "i", "read records from `file or URL`, may be repeated, - is STDIN (default)")
+}
+
+// stringList implements flag.Value for repeated string flags
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// blockList implements flag.Value for repeated block flags
+type blockList []inet.Block
+
+func (l *blockList) String() string {
+	return fmt.Sprint(*l)
+}
+
+func (l *blockList) Set(s string) error {
+	b, err := inet.ParseBlock(s)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, b)
+	return nil
+}
 
 type record struct {
-	b inet.Block
-	t string
+	b    inet.Block
+	t    string
+	free bool
+	util string
+}
+
+// row is the machine-readable form of a tree node
+type row struct {
+	Block  string `json:"block"`
+	Parent string `json:"parent"`
+	Depth  int    `json:"depth"`
+	Text   string `json:"text"`
+	Free   bool   `json:"free"`
 }
 
 var description = `
 Read records with blocks and text (separated by comma) from STDIN and prints the tree representation.
-If a startBlock is defined as argument, the tree is restricted to blocks covered by startBlock.
+With the flag -i, the records are read from files or http(s) URLs instead, the flag may be repeated.
+The inputs are merged, a block already read from a previous input is skipped.
+Besides plain CSV, the output of -o json and -o csv is accepted as input, free blocks are skipped.
+With the flag -w, e.g. -w 2s, the inputs are watched and the screen is re-rendered on change,
+a live dashboard of the IP plan, stop it with Ctrl-C.
+If startBlocks are defined as arguments, the tree is restricted to blocks covered by any startBlock.
+With the flag -x, blocks covered by the excluded block are skipped, the flag may be repeated.
+Excluded space is never reported as free, the utilization of -u is relative to the space not excluded.
 With the flag -f, free blocks are marked as FREE and also printed.
+With the flag -u, every block is annotated with the percentage of its space
+covered by its children and the number and size of the free CIDRs beneath it.
+With the flag -o json or -o csv, the tree is printed as list of nodes with
+block, parent, depth, text and free flag in pre-order, e.g. for dashboards.
 
 Input:
 10.0.0.0/8, RFC-1918
@@ -39,108 +107,458 @@ fd02:b25f:2cb0::/48, my ULA
 
 Output:
 ▼
-├─ 10.0.0.0/8 .................... RFC-1918
-│  └─ 10.0.0.0/24 ................... my home network
-├─ 127.0.0.1/32 .................. home sweet home
-├─ ::1/128 ....................... home sweet home
-├─ 2001:db8::/32 ................. documentation only
-└─ fd02:b25f:2cb0::/48 ........... my ULA
+├─ 10.0.0.0/8           RFC-1918
+│  └─ 10.0.0.0/24       my home network
+├─ 127.0.0.1/32         home sweet home
+├─ ::1/128              home sweet home
+├─ 2001:db8::/32        documentation only
+└─ fd02:b25f:2cb0::/48  my ULA
 `
 
 func main() {
 	checkCmdline()
 
+	if *flagWatch > 0 {
+		watch(os.Stdout, *flagWatch)
+	}
+	if err := render(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// render reads the inputs and writes the output
+func render(w io.Writer) error {
 	// input records
-	records := readData(os.Stdin)
+	records, err := readInputs(inputs)
+	if err != nil {
+		return err
+	}
 
-	// filter by startBlock
-	if (startBlock != inet.Block{}) {
-		records = filter(records, startBlock)
+	// filter by startBlocks and excludes
+	if len(startBlocks) > 0 || len(excludes) > 0 {
+		records = filter(records, startBlocks, excludes)
 	}
 
-	// box block and text to inettree.Item, implements tree.Interface
-	items := make([]tree.Interface, 0)
-	for _, r := range records {
-		items = append(items, boxing(r.b, r.t))
+	// annotate utilization, before the free blocks are added as children
+	if *flagUtil {
+		if records, err = utilization(records); err != nil {
+			return err
+		}
 	}
 
 	// find free blocks
 	if *flagFree {
-		items = free(items)
+		fs, err := free(records)
+		if err != nil {
+			return err
+		}
+		records = append(records, fs...)
+	}
+
+	switch *flagOutput {
+	case "json", "csv":
+		rs, err := rows(records)
+		if err != nil {
+			return err
+		}
+		if *flagOutput == "json" {
+			return writeJSON(w, rs)
+		}
+		return writeCSV(w, rs)
+	default:
+		// box block and text to node, implements tree.Interface
+		items := make([]tree.Interface, 0, len(records))
+		for _, r := range records {
+			items = append(items, boxing(r))
+		}
+
+		// print tree
+		t, err := mkTree(tree.New(items))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, t)
+		return err
+	}
+}
+
+// watch polls the inputs every interval and re-renders the screen on change, never returns.
+// Files are re-read if their modification time or size changed, URLs are fetched every interval.
+//
+// Errors, e.g. a half-written file or a failed fetch, are printed above the last good output.
+func watch(w io.Writer, every time.Duration) {
+	var lastStamp, lastOut, lastFrame string
+	for ; ; time.Sleep(every) {
+		stamp, ok := stamps(inputs)
+		if !ok {
+			// e.g. a file replaced by an editor, try again
+			continue
+		}
+		if stamp == lastStamp && !hasURL(inputs) {
+			continue
+		}
+		lastStamp = stamp
+
+		frame := ""
+		buf := new(bytes.Buffer)
+		if err := render(buf); err != nil {
+			// retry on the next poll, also if unchanged
+			lastStamp = ""
+			frame = fmt.Sprintf("ERROR: %v\n\n%s", err, lastOut)
+		} else {
+			lastOut = buf.String()
+			frame = lastOut
+		}
+		if frame == lastFrame {
+			continue
+		}
+		lastFrame = frame
+
+		// clear screen, cursor home
+		fmt.Fprintf(w, "\033[H\033[2J%s\n\n%s", time.Now().Format("15:04:05"), frame)
 	}
+}
 
-	// build tree
-	t, err := tree.New(items)
+// stamps returns the modification times and sizes of the input files, ok is false if a file is missing
+func stamps(names []string) (stamp string, ok bool) {
+	var sb strings.Builder
+	for _, name := range names {
+		if isURL(name) {
+			continue
+		}
+		fi, err := os.Stat(name)
+		if err != nil {
+			return "", false
+		}
+		fmt.Fprintf(&sb, "%s %v %d\n", name, fi.ModTime().UnixNano(), fi.Size())
+	}
+	return sb.String(), true
+}
+
+// hasURL reports whether any input is a URL
+func hasURL(names []string) bool {
+	for _, name := range names {
+		if isURL(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isURL reports whether the input is a http(s) URL
+func isURL(name string) bool {
+	return strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://")
+}
+
+// mkTree checks the result of the tree build, returns the duplicate or overlapping items as error
+func mkTree(t *tree.Tree, err error) (*tree.Tree, error) {
 	if err != nil {
-		fmt.Println("ERROR:", err)
-		log.Fatalf("duplicate blocks: %v", t.Duplicates())
+		if dups := t.Duplicates(); dups != nil {
+			return nil, fmt.Errorf("duplicate blocks: %v", dups)
+		}
+		if overlaps := t.Overlaps(); overlaps != nil {
+			return nil, fmt.Errorf("overlapping blocks: %v", overlaps)
+		}
+		return nil, err
 	}
+	return t, nil
+}
 
-	// print tree
-	fmt.Println(t)
+// itemTree returns the tree of inettree.Items with block and text of the records, see mkTree.
+// The text output boxes the records as node instead, with the columns.
+func itemTree(records []record) (*tree.Tree, error) {
+	recs := make([]inetio.Record, 0, len(records))
+	for _, r := range records {
+		recs = append(recs, inetio.Record{Block: r.b, Text: r.t})
+	}
+	return mkTree(inetio.FromRecords(recs))
 }
 
-// input records as CSV data:
-// block, text...
-func readData(in io.Reader) []record {
-	out := make([]record, 0)
+// utilization annotates the records with the used space and the free CIDRs beneath
+func utilization(records []record) ([]record, error) {
+	t, err := itemTree(records)
+	if err != nil {
+		return nil, err
+	}
+
+	util := make(map[inet.Block]string, len(records))
+	walkFn := func(_ int, item, _ tree.Interface, childs []tree.Interface) error {
+		// type assertions from tree.Interface to inet.Block
+		block := item.(inettree.Item).Block
+
+		// excluded space is neither free nor used, it doesn't count at all
+		excluded := new(big.Int).Sub(block.Size(), gapSize(block, excludes))
+		avail := new(big.Int).Sub(block.Size(), excluded)
+
+		taken := append(assertBlock(childs), excludes...)
+		inet.SortBlocks(taken)
+
+		var cidrs int
+		size := new(big.Int)
+		for _, gap := range inet.Gaps(block, taken) {
+			cidrs += len(gap.CIDRs())
+			size.Add(size, gap.Size())
+		}
+
+		// used fraction of the available space
+		used := 1.0
+		if avail.Sign() > 0 {
+			used, _ = new(big.Float).Quo(new(big.Float).SetInt(new(big.Int).Sub(avail, size)), new(big.Float).SetInt(avail)).Float64()
+		}
+
+		util[block] = fmt.Sprintf("[%.2f%% used, %d free CIDRs, %v free addrs]", 100*used, cidrs, size)
+		return nil
+	}
+
+	if err := t.Walk(walkFn); err != nil {
+		return nil, err
+	}
+
+	for i := range records {
+		records[i].util = util[records[i].b]
+	}
+	return records, nil
+}
 
-	r := csv.NewReader(os.Stdin)
-	r.FieldsPerRecord = -1
+// gapSize returns the number of addresses in block not covered by bs
+func gapSize(block inet.Block, bs []inet.Block) *big.Int {
+	sorted := append([]inet.Block(nil), bs...)
+	inet.SortBlocks(sorted)
 
-	for {
-		fields, err := r.Read()
-		if err == io.EOF {
-			break
+	size := new(big.Int)
+	for _, gap := range inet.Gaps(block, sorted) {
+		size.Add(size, gap.Size())
+	}
+	return size
+}
+
+// rows returns the tree nodes in pre-order, as presented by the text output
+func rows(records []record) ([]row, error) {
+	t, err := itemTree(records)
+	if err != nil {
+		return nil, err
+	}
+
+	isFree := make(map[inet.Block]bool)
+	for _, r := range records {
+		if r.free {
+			isFree[r.b] = true
 		}
+	}
+
+	var out []row
+	walkFn := func(depth int, item, parent tree.Interface, _ []tree.Interface) error {
+		it := item.(inettree.Item)
+		r := row{
+			Block: it.Block.String(),
+			Depth: depth,
+			Text:  it.Text,
+			Free:  isFree[it.Block],
+		}
+		if parent != nil {
+			r.Parent = parent.(inettree.Item).Block.String()
+		}
+		out = append(out, r)
+		return nil
+	}
+
+	if err := t.Walk(walkFn); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
 
+// writeJSON writes the rows as JSON array
+func writeJSON(w io.Writer, rs []row) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rs)
+}
+
+// writeCSV writes the rows as CSV with header
+func writeCSV(w io.Writer, rs []row) error {
+	cw := csv.NewWriter(w)
+	cw.Write(strings.Split(csvHeader, ","))
+	for _, r := range rs {
+		cw.Write([]string{r.Block, r.Parent, strconv.Itoa(r.Depth), r.Text, strconv.FormatBool(r.Free)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvHeader is the header of the csv output, see writeCSV
+const csvHeader = "block,parent,depth,text,free"
+
+// readInputs reads and merges the records of all inputs, STDIN if there are none.
+// Blocks already read from a previous input are skipped.
+func readInputs(names []string) ([]record, error) {
+	if len(names) == 0 {
+		return readInput("-")
+	}
+
+	var out []record
+	seen := make(map[inet.Block]string)
+	for _, name := range names {
+		records, err := readInput(name)
 		if err != nil {
-			log.Printf("skip line: %v", err)
-			continue
+			return nil, err
+		}
+		for _, r := range records {
+			if prev, ok := seen[r.b]; ok {
+				log.Printf("skip record: %s: %v already read from %s", name, r.b, prev)
+				continue
+			}
+			seen[r.b] = name
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// httpClient fetches the URL inputs, a hanging server must not stall the watch mode
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// readInput reads the records from STDIN, a file or a http(s) URL.
+func readInput(name string) ([]record, error) {
+	var in io.Reader
+	switch {
+	case name == "-":
+		in = os.Stdin
+	case isURL(name):
+		resp, err := httpClient.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s: %s", name, resp.Status)
+		}
+		in = resp.Body
+	default:
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
 		}
+		defer f.Close()
+		in = f
+	}
+
+	// sniff the format
+	br := bufio.NewReader(in)
+	head, _ := br.Peek(512)
+	head = bytes.TrimSpace(head)
+
+	if bytes.HasPrefix(head, []byte("[")) {
+		return readJSON(name, br)
+	}
+	return readData(br, bytes.HasPrefix(head, []byte(csvHeader))), nil
+}
 
-		f0 := strings.TrimSpace(fields[0])
+// readJSON reads the rows of the json output, see writeJSON
+func readJSON(name string, in io.Reader) ([]record, error) {
+	var rs []row
+	if err := json.NewDecoder(in).Decode(&rs); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
 
-		block, err := inet.ParseBlock(f0)
+	out := make([]record, 0, len(rs))
+	for i, r := range rs {
+		if r.Free {
+			continue
+		}
+		b, err := inet.ParseBlock(r.Block)
 		if err != nil {
-			log.Printf("skip record: %v (%v)", err, f0)
+			log.Printf("skip record: %s: row %d: %v", name, i+1, err)
 			continue
 		}
+		out = append(out, record{b: b, t: r.Text})
+	}
+	return out, nil
+}
 
-		var text string
-		if len(fields) > 1 {
-			text = strings.Join(fields[1:], " ")
-			text = strings.TrimSpace(text)
-		}
+// input records as CSV data:
+// block, text...
+//
+// or the csv output, the free blocks are skipped
+func readData(in io.Reader, csvOutput bool) []record {
+	var opts []inetio.Option
+	if csvOutput {
+		opts = append(opts, inetio.WithHeader(), inetio.WithTextColumns(3))
+	}
+
+	recs, errs := inetio.ReadBlocksCSV(in, opts...)
+	for _, err := range errs {
+		log.Printf("skip record: %v", err)
+	}
 
-		// save record
-		out = append(out, record{b: block, t: text})
+	out := make([]record, 0, len(recs))
+	for _, r := range recs {
+		// free blocks of the csv output
+		if csvOutput && len(r.Fields) > 4 && r.Fields[4] == "true" {
+			continue
+		}
+		out = append(out, record{b: r.Block, t: r.Text})
 	}
 	return out
 }
 
-// box the inet.Block and text to inettree.Item, implements tree.Interface
-func boxing(b inet.Block, t string) inettree.Item {
-	asStr := b.String()
-	if t != "" {
-		// 10.0.0.0/8 ........................................ RFC-1981
-		t = asStr + " " + strings.Repeat(".", 50-len(asStr)) + " " + t
+// node is the tree.Interface for the text output, the text is printed in aligned columns
+type node struct {
+	inettree.Item
+	cols []string
+}
+
+func (a node) Less(i tree.Interface) bool     { return a.Item.Less(i.(node).Item) }
+func (a node) Equals(i tree.Interface) bool   { return a.Item.Equals(i.(node).Item) }
+func (a node) Covers(i tree.Interface) bool   { return a.Item.Covers(i.(node).Item) }
+func (a node) Overlaps(i tree.Interface) bool { return a.Item.Overlaps(i.(node).Item) }
+
+// Columns implements the tree.Columner interface
+func (a node) Columns() []string { return a.cols }
+
+// box the inet.Block and text to node, implements tree.Interface
+func boxing(r record) node {
+	t := r.t
+	if r.free {
+		t = "FREE"
+	}
+
+	var cols []string
+	if t != "" || r.util != "" {
+		cols = append(cols, t)
 	}
-	return inettree.Item{Block: b, Text: t}
+	if r.util != "" {
+		cols = append(cols, r.util)
+	}
+	return node{Item: inettree.Item{Block: r.b}, cols: cols}
 }
 
-// filters input blocks by startBlock
-func filter(in []record, outer inet.Block) []record {
+// filters input blocks by startBlocks and excludes
+func filter(in []record, starts, excludes []inet.Block) []record {
 	out := make([]record, 0, len(in))
 
 	for _, r := range in {
-		if outer.Covers(r.b) || outer == r.b {
-			out = append(out, r)
+		if len(starts) > 0 && !coveredByAny(r.b, starts) {
+			continue
+		}
+		if coveredByAny(r.b, excludes) {
+			continue
 		}
+		out = append(out, r)
 	}
 	return out
 }
 
+// coveredByAny reports whether b is equal to or covered by any of the outer blocks
+func coveredByAny(b inet.Block, outer []inet.Block) bool {
+	for _, o := range outer {
+		if o.Covers(b) || o == b {
+			return true
+		}
+	}
+	return false
+}
+
 func assertBlock(is []tree.Interface) (bs []inet.Block) {
 	for _, v := range is {
 		bs = append(bs, v.(inettree.Item).Block)
@@ -148,14 +566,12 @@ func assertBlock(is []tree.Interface) (bs []inet.Block) {
 	return
 }
 
-// find free
-func free(is []tree.Interface) []tree.Interface {
-
+// find free blocks, returned as records marked free
+func free(records []record) ([]record, error) {
 	// make tree with input
-	t, err := tree.New(is)
+	t, err := itemTree(records)
 	if err != nil {
-		fmt.Println("ERROR:", err)
-		log.Fatalf("duplicate blocks: %v", t.Duplicates())
+		return nil, err
 	}
 
 	// find free blocks
@@ -170,8 +586,8 @@ func free(is []tree.Interface) []tree.Interface {
 			item := item.(inettree.Item).Block
 			childs := assertBlock(childs)
 
-			// calc free blocks for every item
-			for _, diff := range item.Diff(childs) {
+			// calc free blocks for every item, excluded space isn't free
+			for _, diff := range item.Diff(append(childs, excludes...)) {
 				free = append(free, diff.CIDRs()...)
 			}
 		}
@@ -179,14 +595,15 @@ func free(is []tree.Interface) []tree.Interface {
 	}
 
 	if err := t.Walk(walkFn); err != nil {
-		log.Fatalf("ERROR, in WalkTreeFn: %v", err)
+		return nil, err
 	}
 
+	out := make([]record, 0, len(free))
 	for _, b := range free {
-		is = append(is, boxing(b, "FREE"))
+		out = append(out, record{b: b, free: true})
 	}
 
-	return is
+	return out, nil
 }
 
 // check flags and arguments
@@ -195,20 +612,38 @@ func checkCmdline() {
 	flag.Parse()
 	w := flag.CommandLine.Output()
 
-	if len(flag.Args()) > 0 {
-		block, err := inet.ParseBlock(flag.Arg(0))
+	switch *flagOutput {
+	case "text", "json", "csv":
+	default:
+		fmt.Fprintf(w, "ERROR: wrong output format '%s'\n\n", *flagOutput)
+		usage()
+	}
+
+	if *flagWatch > 0 {
+		stdin := len(inputs) == 0
+		for _, name := range inputs {
+			stdin = stdin || name == "-"
+		}
+		if stdin {
+			fmt.Fprintf(w, "ERROR: watch mode needs files or URLs as input, not STDIN\n\n")
+			usage()
+		}
+	}
+
+	for _, arg := range flag.Args() {
+		block, err := inet.ParseBlock(arg)
 		if err != nil {
-			fmt.Fprintf(w, "ERROR: wrong start block '%s': %v\n\n", flag.Arg(0), err)
+			fmt.Fprintf(w, "ERROR: wrong start block '%s': %v\n\n", arg, err)
 			usage()
 		}
-		startBlock = block
+		startBlocks = append(startBlocks, block)
 	}
 }
 
 // just the usage
 func usage() {
 	w := flag.CommandLine.Output()
-	fmt.Fprintf(w, "Usage: %s [-f] [startBlock]\n\n", os.Args[0])
+	fmt.Fprintf(w, "Usage: %s [-f] [-u] [-o text|json|csv] [-x block]... [-i file|URL]... [-w interval] [startBlock]...\n\n", os.Args[0])
 	flag.PrintDefaults()
 	fmt.Fprintln(w, description)
 	os.Exit(1)
@@ -0,0 +1,125 @@
+// Command lookup, longest-prefix-match queries against a CSV file of blocks
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/inetio"
+	"github.com/gaissmai/go-inet/v2/inettree"
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+var description = `
+Read records with blocks and text (separated by comma) from file and answer the queries
+given as arguments or, without arguments, one query per line from STDIN.
+
+For every query the longest-prefix-match and the full chain of supersets, biggest first,
+is printed, separated by TABs:
+
+query   match   text   chain
+
+Queries without match are printed with '-' as match.
+
+Example:
+$ echo 10.0.0.17 | lookup plan.csv
+10.0.0.17	10.0.0.0/24	my home network	10.0.0.0/8 > 10.0.0.0/24
+`
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+	}
+
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	recs := readData(f)
+	f.Close()
+
+	t, err := inetio.FromRecords(recs)
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		if dups := t.Duplicates(); dups != nil {
+			log.Fatalf("duplicate blocks: %v", dups)
+		}
+		log.Fatalf("overlapping blocks: %v", t.Overlaps())
+	}
+	query := func(s string) {
+		fmt.Println(lookup(t, s))
+	}
+
+	if flag.NArg() > 1 {
+		for _, s := range flag.Args()[1:] {
+			query(s)
+		}
+		return
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		if s := strings.TrimSpace(scanner.Text()); s != "" {
+			query(s)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// lookup the query, returns the output line
+func lookup(t *tree.Tree, s string) string {
+	block, err := inet.ParseBlock(s)
+	if err != nil {
+		log.Printf("skip query: %v", err)
+		return s + "\t-"
+	}
+
+	// the match as stored in the tree, with its text, also for exact matches
+	m := t.Lookup(inettree.Item{Block: block})
+	if m == nil {
+		return s + "\t-"
+	}
+	match := m.(inettree.Item)
+
+	// the superset chain, bottom-up
+	chain := []string{match.Block.String()}
+	for p, ok := t.Parent(match); ok; p, ok = t.Parent(p) {
+		chain = append(chain, p.(inettree.Item).Block.String())
+	}
+
+	// reverse, biggest first
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+
+	return strings.Join([]string{s, match.Block.String(), match.Text, strings.Join(chain, " > ")}, "\t")
+}
+
+// input records as CSV data:
+// block, text...
+func readData(in io.Reader) []inetio.Record {
+	recs, errs := inetio.ReadBlocksCSV(in)
+	for _, err := range errs {
+		log.Printf("skip record: %v", err)
+	}
+	return recs
+}
+
+// just the usage
+func usage() {
+	w := flag.CommandLine.Output()
+	fmt.Fprintf(w, "Usage: %s file.csv [query]...\n\n", os.Args[0])
+	flag.PrintDefaults()
+	fmt.Fprintln(w, description)
+	os.Exit(1)
+}
@@ -0,0 +1,130 @@
+package inet
+
+import "math/big"
+
+// Aggregate is a CIDR of the slack aggregation, Slack counts the addresses not present in the input.
+type Aggregate struct {
+	Block Block
+	Slack *big.Int
+}
+
+// String returns the CIDR and the slack, e.g. "10.0.0.0/23 (slack 256)".
+func (a Aggregate) String() string {
+	return a.Block.String() + " (slack " + a.Slack.String() + ")"
+}
+
+// AggregateWithSlack merges the blocks into fewer CIDRs, trading precision against the number of CIDRs.
+// The aggregates may include up to max addresses not present in the input, per IP version.
+// A nil or zero max results in the exact CIDRs of Merge.
+//
+// The aggregation is greedy, the merge adding the least slack wins,
+// the runtime is quadratic in the number of merged CIDRs.
+// The input slice isn't modified.
+func AggregateWithSlack(bs []Block, max *big.Int) []Aggregate {
+	if max == nil {
+		max = new(big.Int)
+	}
+	used4, used6 := new(big.Int), new(big.Int)
+
+	usedBy := func(c Block) *big.Int {
+		if c.Is4() {
+			return used4
+		}
+		return used6
+	}
+
+	fits := func(c Block, slack, added *big.Int) bool {
+		return new(big.Int).Add(usedBy(c), added).Cmp(max) <= 0
+	}
+	merged := func(c Block, added *big.Int) {
+		used := usedBy(c)
+		used.Add(used, added)
+	}
+	return aggregate(bs, fits, merged)
+}
+
+// AggregateWithSlackPercent merges the blocks into fewer CIDRs, trading precision against the number of CIDRs.
+// Every aggregate may include up to percent addresses of its size not present in the input.
+// A percent <= 0 results in the exact CIDRs of Merge.
+//
+// The aggregation is greedy, the merge adding the least slack wins,
+// the runtime is quadratic in the number of merged CIDRs.
+// The input slice isn't modified.
+func AggregateWithSlackPercent(bs []Block, percent float64) []Aggregate {
+	pct := new(big.Float).SetFloat64(percent / 100)
+
+	fits := func(c Block, slack, added *big.Int) bool {
+		limit := new(big.Float).Mul(pct, new(big.Float).SetInt(c.Size()))
+		return new(big.Float).SetInt(slack).Cmp(limit) <= 0
+	}
+	return aggregate(bs, fits, nil)
+}
+
+// aggregate merges the CIDRs of bs greedily, the fitting merge adding the least slack first.
+// fits is called with the candidate CIDR, its total slack and the slack added by the merge,
+// merged, if not nil, after each merge.
+func aggregate(bs []Block, fits func(c Block, slack, added *big.Int) bool, merged func(c Block, added *big.Int)) []Aggregate {
+	type entry struct {
+		Aggregate
+		covered *big.Int // input addresses
+	}
+
+	// Merge sorts in place, work on a copy
+	bs = append([]Block(nil), bs...)
+
+	var es []entry
+	for _, r := range Merge(bs) {
+		for _, c := range r.CIDRs() {
+			es = append(es, entry{Aggregate{c, new(big.Int)}, c.Size()})
+		}
+	}
+
+	for {
+		var best entry
+		var bestAdded *big.Int
+		bestLo, bestHi := -1, -1
+
+		for i := 0; i+1 < len(es); i++ {
+			if es[i].Block.base.version != es[i+1].Block.base.version {
+				continue
+			}
+			c := Block{es[i].Block.base, es[i+1].Block.last}.CoveringCIDR()
+
+			// entries are disjoint CIDRs, c covers a run of them
+			lo, hi := i, i+1
+			for lo > 0 && c.Covers(es[lo-1].Block) {
+				lo--
+			}
+			for hi+1 < len(es) && c.Covers(es[hi+1].Block) {
+				hi++
+			}
+
+			covered, oldSlack := new(big.Int), new(big.Int)
+			for _, e := range es[lo : hi+1] {
+				covered.Add(covered, e.covered)
+				oldSlack.Add(oldSlack, e.Slack)
+			}
+			slack := new(big.Int).Sub(c.Size(), covered)
+			added := new(big.Int).Sub(slack, oldSlack)
+
+			if bestAdded != nil && added.Cmp(bestAdded) >= 0 || !fits(c, slack, added) {
+				continue
+			}
+			best, bestAdded, bestLo, bestHi = entry{Aggregate{c, slack}, covered}, added, lo, hi
+		}
+
+		if bestLo < 0 {
+			break
+		}
+		if merged != nil {
+			merged(best.Block, bestAdded)
+		}
+		es = append(es[:bestLo], append([]entry{best}, es[bestHi+1:]...)...)
+	}
+
+	out := make([]Aggregate, 0, len(es))
+	for _, e := range es {
+		out = append(out, e.Aggregate)
+	}
+	return out
+}
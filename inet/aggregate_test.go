@@ -0,0 +1,91 @@
+package inet
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestAggregateWithSlack(t *testing.T) {
+	bs := []Block{
+		mustBlock("10.0.0.0/24"),
+		mustBlock("10.0.1.0/24"),
+		mustBlock("10.0.3.0/24"),
+		mustBlock("10.0.8.0/24"),
+		mustBlock("2001:db8::/48"),
+		mustBlock("2001:db8:2::/48"),
+	}
+
+	tests := []struct {
+		max  int64
+		want string
+	}{
+		{0, "[10.0.0.0/23 (slack 0) 10.0.3.0/24 (slack 0) 10.0.8.0/24 (slack 0) 2001:db8::/48 (slack 0) 2001:db8:2::/48 (slack 0)]"},
+		{255, "[10.0.0.0/23 (slack 0) 10.0.3.0/24 (slack 0) 10.0.8.0/24 (slack 0) 2001:db8::/48 (slack 0) 2001:db8:2::/48 (slack 0)]"},
+		{256, "[10.0.0.0/22 (slack 256) 10.0.8.0/24 (slack 0) 2001:db8::/48 (slack 0) 2001:db8:2::/48 (slack 0)]"},
+		{256 + 2816, "[10.0.0.0/20 (slack 3072) 2001:db8::/48 (slack 0) 2001:db8:2::/48 (slack 0)]"},
+		{1 << 40, "[10.0.0.0/20 (slack 3072) 2001:db8::/48 (slack 0) 2001:db8:2::/48 (slack 0)]"},
+	}
+
+	for _, tt := range tests {
+		got := AggregateWithSlack(append([]Block(nil), bs...), big.NewInt(tt.max))
+		if s := sprint(got); s != tt.want {
+			t.Errorf("AggregateWithSlack(%d)\ngot:  %s\nwant: %s", tt.max, s, tt.want)
+		}
+	}
+
+	// the v6 budget is separate, 2001:db8::/46 adds 2*2^80 addresses
+	max := new(big.Int).Lsh(big.NewInt(1), 81)
+	want := "[10.0.0.0/20 (slack 3072) 2001:db8::/46 (slack 2417851639229258349412352)]"
+	if s := sprint(AggregateWithSlack(bs, max)); s != want {
+		t.Errorf("AggregateWithSlack(2^81)\ngot:  %s\nwant: %s", s, want)
+	}
+
+	if got := AggregateWithSlack(nil, nil); len(got) != 0 {
+		t.Errorf("AggregateWithSlack(nil, nil), got %v, want empty", got)
+	}
+
+	// the input isn't sorted in place
+	in := []Block{mustBlock("10.0.8.0/24"), mustBlock("10.0.0.0/24")}
+	AggregateWithSlack(in, nil)
+	AggregateWithSlackPercent(in, 0)
+	if in[0] != mustBlock("10.0.8.0/24") {
+		t.Errorf("AggregateWithSlack, input modified: %v", in)
+	}
+}
+
+func TestAggregateWithSlackPercent(t *testing.T) {
+	bs := []Block{
+		mustBlock("10.0.0.0/24"),
+		mustBlock("10.0.1.0/24"),
+		mustBlock("10.0.3.0/24"),
+		mustBlock("10.0.8.0/24"),
+	}
+
+	tests := []struct {
+		percent float64
+		want    string
+	}{
+		{0, "[10.0.0.0/23 (slack 0) 10.0.3.0/24 (slack 0) 10.0.8.0/24 (slack 0)]"},
+		{24.9, "[10.0.0.0/23 (slack 0) 10.0.3.0/24 (slack 0) 10.0.8.0/24 (slack 0)]"},
+		{25, "[10.0.0.0/22 (slack 256) 10.0.8.0/24 (slack 0)]"},
+		{75, "[10.0.0.0/20 (slack 3072)]"},
+	}
+
+	for _, tt := range tests {
+		got := AggregateWithSlackPercent(append([]Block(nil), bs...), tt.percent)
+		if s := sprint(got); s != tt.want {
+			t.Errorf("AggregateWithSlackPercent(%v)\ngot:  %s\nwant: %s", tt.percent, s, tt.want)
+		}
+	}
+}
+
+func sprint(as []Aggregate) string {
+	s := "["
+	for i, a := range as {
+		if i > 0 {
+			s += " "
+		}
+		s += a.String()
+	}
+	return s + "]"
+}
@@ -3,8 +3,10 @@ package inet
 import (
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
 	"net"
-	"sort"
 	"strings"
 )
 
@@ -48,6 +50,8 @@ func (b Block) Last() IP { return b.last }
 //  ::0
 //
 // IP addresses as input are converted to /32 or /128 blocks.
+// A range of a single address, e.g. 4.4.4.4-4.4.4.4, is accepted
+// like BlockFromRange does, it's the output of StringRange for a /32 or /128.
 // Returns error and Block{} on invalid input.
 //
 // The hard part is done by net.ParseIP() and net.ParseCIDR().
@@ -70,7 +74,7 @@ func ParseBlock(s string) (b Block, err error) {
 	// maybe just an ip
 	ip, err := ParseIP(s)
 	if err == nil {
-		return blockFromIP(ip)
+		return BlockFromIP(ip)
 	}
 
 	return
@@ -98,12 +102,25 @@ func FromStdIPNet(stdNet net.IPNet) (b Block, err error) {
 	return a, nil
 }
 
-// blockFromIP converts inet.IP to inet.Block with ip as base and last.
-func blockFromIP(ip IP) (Block, error) {
+// BlockFromIP converts inet.IP to inet.Block with ip as base and last, a /32 or /128 CIDR.
+// If ip is invalid, returns Block{} and error.
+func BlockFromIP(ip IP) (Block, error) {
+	if !ip.IsValid() {
+		return Block{}, fmt.Errorf("%v: %v", invalidBlock, errInvalidIP)
+	}
 	b := Block{base: ip, last: ip}
 	return b, nil
 }
 
+// BlockFromRange returns the Block from base to last, both included.
+// Returns Block{} and error if the addresses are invalid, of different versions or last is before base.
+func BlockFromRange(base, last IP) (Block, error) {
+	if !base.IsValid() || !last.IsValid() || base.version != last.version || last.Less(base) {
+		return Block{}, fmt.Errorf("%v: %v-%v", invalidBlock, base, last)
+	}
+	return Block{base: base, last: last}, nil
+}
+
 // parse IP CIDR
 // e.g.: 127.0.0.0/8 or 2001:db8::/32
 func blockFromCIDR(s string) (b Block, err error) {
@@ -141,8 +158,8 @@ func blockFromRange(s string, i int) (b Block, err error) {
 		return
 	}
 
-	// begin > end
-	if !baseIP.Less(lastIP) {
+	// begin > end, begin == end is accepted as the range form of a single address
+	if lastIP.Less(baseIP) {
 		err = fmt.Errorf("%v: base > last, %v", invalidBlock, s)
 		return
 	}
@@ -174,6 +191,18 @@ func (b Block) IsCIDR() bool {
 	return b.base.isCIDR(b.last)
 }
 
+// Bits returns the prefix length of the CIDR b, or -1 if b is no CIDR.
+func (b Block) Bits() int {
+	if !b.IsCIDR() {
+		return -1
+	}
+	n := int(b.base.commonPrefixLen(b.last))
+	if b.base.version == v4 {
+		n -= 96
+	}
+	return n
+}
+
 // String returns the string form of the Block.
 // It returns one of 3 forms:
 //
@@ -181,18 +210,77 @@ func (b Block) IsCIDR() bool {
 //   "127.0.0.1-127.0.0.19"  if b.IsCIDR is false
 //   "2001:db8::/32"         if b.IsCIDR is true
 func (b Block) String() string {
+	var buf [93]byte
+	return string(b.AppendTo(buf[:0]))
+}
+
+// StringRange returns the range form base-last of the Block, even for CIDRs,
+// e.g. "10.0.0.0-10.0.0.255", and "invalid Block" if b.IsValid() is false.
+func (b Block) StringRange() string {
 	if !b.IsValid() {
 		return invalidBlock
 	}
-	if !b.IsCIDR() {
-		return fmt.Sprintf("%s-%s", b.base, b.last)
+	return fmt.Sprintf("%s-%s", b.base, b.last)
+}
+
+// StringCIDRs returns the comma separated CIDRs spanning the Block, even for ranges,
+// e.g. "10.0.0.3/32,10.0.0.4/30", and "invalid Block" if b.IsValid() is false.
+// The output is accepted by ParseIPList.
+func (b Block) StringCIDRs() string {
+	if !b.IsValid() {
+		return invalidBlock
+	}
+	cidrs := b.CIDRs()
+	ss := make([]string, 0, len(cidrs))
+	for _, c := range cidrs {
+		ss = append(ss, c.String())
 	}
+	return strings.Join(ss, ",")
+}
 
-	n := b.base.commonPrefixLen(b.last)
-	if b.base.version == v4 {
-		n = n - 96
+// Size returns the number of addresses in b, 0 for the zero value.
+func (b Block) Size() *big.Int {
+	if !b.IsValid() {
+		return new(big.Int)
+	}
+	z := b.last.sub(b.base.uint128).toBig()
+	return z.Add(z, big.NewInt(1))
+}
+
+// UsableHosts returns the number of usable host addresses in b.
+//
+// IPv4 CIDRs lose the network and broadcast address, except for
+// /31 (point-to-point links, RFC 3021) and /32 blocks.
+// IPv4 ranges and all IPv6 blocks return the plain size.
+func (b Block) UsableHosts() *big.Int {
+	z := b.Size()
+	if b.hasNetBcast() {
+		z.Sub(z, big.NewInt(2))
+	}
+	return z
+}
+
+// FirstHost returns the first usable host address in b, see UsableHosts.
+// Returns IP{} if b is invalid.
+func (b Block) FirstHost() IP {
+	if b.hasNetBcast() {
+		return b.base.addOne()
+	}
+	return b.base
+}
+
+// LastHost returns the last usable host address in b, see UsableHosts.
+// Returns IP{} if b is invalid.
+func (b Block) LastHost() IP {
+	if b.hasNetBcast() {
+		return b.last.subOne()
 	}
-	return fmt.Sprintf("%s/%d", b.base, n)
+	return b.last
+}
+
+// hasNetBcast reports whether b is an IPv4 CIDR with network and broadcast address, shorter than /31.
+func (b Block) hasNetBcast() bool {
+	return b.Is4() && b.IsCIDR() && b.base.commonPrefixLen(b.last) < 96+31
 }
 
 // Covers reports whether Block b contains Block c. b and c may NOT coincide.
@@ -207,10 +295,7 @@ func (b Block) Covers(c Block) bool {
 	if b == c {
 		return false
 	}
-	if b.base.uint128.cmp(c.base.uint128) <= 0 && b.last.uint128.cmp(c.last.uint128) >= 0 {
-		return true
-	}
-	return false
+	return !c.base.uint128.less(b.base.uint128) && !b.last.uint128.less(c.last.uint128)
 }
 
 // Less reports whether the block b should be sorted before c.
@@ -252,7 +337,7 @@ func Merge(bs []Block) []Block {
 	}
 
 	// must be sorted for this algo!
-	sort.Slice(bs, func(i, j int) bool { return bs[i].Less(bs[j]) })
+	SortBlocks(bs)
 
 	out := make([]Block, 1, len(bs))
 	out[0] = bs[0]
@@ -261,11 +346,11 @@ func Merge(bs []Block) []Block {
 		switch {
 		case !b.IsValid():
 			// no-op
-		case prev.overlaps(b):
+		case prev.Overlaps(b):
 			prev.last = b.last
 		case prev.last.addOne() == b.base:
 			prev.last = b.last
-		case prev.isDisjunct(b):
+		case prev.IsDisjunct(b):
 			out = append(out, b)
 		default:
 			// no-op: covers or equal
@@ -274,7 +359,292 @@ func Merge(bs []Block) []Block {
 	return out
 }
 
+// MergeWithMinPrefix merges like Merge but returns CIDRs and never aggregates beyond the
+// prefix lengths min4 and min6, e.g. never forms anything shorter than /8 for route and filter generation.
+// Input blocks already shorter than the minimum are kept as CIDRs, a minimum of 0 disables the limit.
+//
+// Returns an error for minimums beyond /32 or /128 and an error wrapping ErrLimit
+// if splitting an aggregate exceeds MaxCIDRSplit. The input slice isn't modified.
+func MergeWithMinPrefix(bs []Block, min4, min6 int) ([]Block, error) {
+	if min4 < 0 || min4 > 32 || min6 < 0 || min6 > 128 {
+		return nil, fmt.Errorf("%v: invalid minimum prefix length /%d or /%d", invalidBlock, min4, min6)
+	}
+
+	// the CIDRs of the input blocks already shorter than the minimum, disjunct and sorted
+	var short []Block
+	for _, b := range bs {
+		if !b.IsValid() {
+			continue
+		}
+		for _, c := range b.CIDRs() {
+			if c.Is4() && c.Bits() < min4 || c.Is6() && c.Bits() < min6 {
+				short = append(short, c)
+			}
+		}
+	}
+	SortBlocks(short)
+	var kept []Block
+	for _, c := range short {
+		// CIDRs are nested or disjunct, sorted the cover is the last one kept
+		if len(kept) == 0 || !c.containedIn(kept[len(kept)-1:]) {
+			kept = append(kept, c)
+		}
+	}
+
+	// Merge sorts in place, work on a copy
+	bs = append([]Block(nil), bs...)
+
+	var out []Block
+	for _, r := range Merge(bs) {
+		min := min6
+		if r.Is4() {
+			min = min4
+		}
+
+		for _, c := range r.CIDRs() {
+			n := c.Bits()
+			if n >= min {
+				out = append(out, c)
+				continue
+			}
+
+			// aggregated beyond the minimum, keep the short input CIDRs within c
+			// and split just the remainder down to the minimum, the remainder
+			// CIDRs are never longer than the minimum, the kept ones are shorter
+			var parts []Block
+			for _, k := range kept {
+				if k == c || c.Covers(k) {
+					parts = append(parts, k)
+				}
+			}
+			for _, d := range c.Diff(parts) {
+				for _, dc := range d.CIDRs() {
+					split, err := dc.SplitCIDR(min - dc.Bits())
+					if err != nil {
+						return nil, err
+					}
+					parts = append(parts, split...)
+				}
+			}
+			SortBlocks(parts)
+			out = append(out, parts...)
+		}
+	}
+	return out, nil
+}
+
+// Subtract returns the allow blocks minus the deny blocks as sorted CIDRs, "permit allow except deny".
+// The input slices aren't modified.
+func Subtract(allow, deny []Block) []Block {
+	deny = normalize(deny)
+
+	var out []Block
+	for _, r := range normalize(allow) {
+		for _, d := range r.Diff(deny) {
+			out = append(out, d.CIDRs()...)
+		}
+	}
+	return out
+}
+
+// SameCoverage reports whether a and b cover exactly the same address space,
+// regardless of the representation as ranges, CIDRs or different aggregation.
+// The input slices aren't modified.
+func SameCoverage(a, b []Block) bool {
+	na, nb := normalize(a), normalize(b)
+	if len(na) != len(nb) {
+		return false
+	}
+	for i := range na {
+		if na[i] != nb[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// CoverageDiff returns the address space covered only by a and only by b, as merged blocks.
+// Both are empty if a and b have the same coverage. The input slices aren't modified.
+func CoverageDiff(a, b []Block) (onlyA, onlyB []Block) {
+	na, nb := normalize(a), normalize(b)
+	for _, r := range na {
+		onlyA = append(onlyA, r.Diff(nb)...)
+	}
+	for _, r := range nb {
+		onlyB = append(onlyB, r.Diff(na)...)
+	}
+	return onlyA, onlyB
+}
+
+// normalize returns the merged valid blocks of a copy of bs.
+func normalize(bs []Block) []Block {
+	buf := make([]Block, 0, len(bs))
+	for _, b := range bs {
+		if b.IsValid() {
+			buf = append(buf, b)
+		}
+	}
+	return Merge(buf)
+}
+
+// CoveringCIDR returns the smallest CIDR containing b.
+// Returns b itself if b is already a CIDR and Block{} if b is invalid.
+func (b Block) CoveringCIDR() Block {
+	if !b.IsValid() {
+		return Block{}
+	}
+	mask := maskUint128[b.base.commonPrefixLen(b.last)]
+	return Block{b.base.mkBaseIP(mask), b.base.mkLastIP(mask)}
+}
+
+// CoveringCIDR returns the smallest CIDR containing all blocks in bs.
+// Returns Block{} and error if bs is empty, contains invalid blocks or blocks of both IP versions.
+func CoveringCIDR(bs []Block) (Block, error) {
+	if len(bs) == 0 {
+		return Block{}, fmt.Errorf("%v: empty list", invalidBlock)
+	}
+
+	span := bs[0]
+	for _, b := range bs {
+		if !b.IsValid() {
+			return Block{}, errInvalidBlock
+		}
+		if b.base.version != span.base.version {
+			return Block{}, fmt.Errorf("%v: version mismatch, %v, %v", invalidBlock, span, b)
+		}
+		if b.base.Less(span.base) {
+			span.base = b.base
+		}
+		if span.last.Less(b.last) {
+			span.last = b.last
+		}
+	}
+
+	return span.CoveringCIDR(), nil
+}
+
+// SplitCIDR splits the CIDR b into 2^bits CIDRs of equal size, in ascending order.
+// Returns error if b is no CIDR or the prefix length would exceed /32 or /128.
+//
+// Beware, 2^bits blocks are allocated, returns an error wrapping ErrLimit beyond MaxCIDRSplit,
+// or beyond 2^30 blocks if MaxCIDRSplit is disabled.
+func (b Block) SplitCIDR(bits int) ([]Block, error) {
+	if !b.IsCIDR() {
+		return nil, fmt.Errorf("%v: not a CIDR: %v", invalidBlock, b)
+	}
+
+	// prefix length in the 128 bit space
+	n := int(b.base.commonPrefixLen(b.last)) + bits
+	if bits < 0 || n > 128 {
+		return nil, fmt.Errorf("%v: can't split %v by %d bits", invalidBlock, b, bits)
+	}
+
+	// -1 signals the overflow, even for 32 bit ints
+	count := -1
+	if bits <= 30 {
+		count = 1 << bits
+	}
+	if err := checkSplit(b, count); err != nil {
+		return nil, err
+	}
+	mask := maskUint128[n]
+
+	out := make([]Block, 0, count)
+	cursor := b.base
+	for i := 0; i < count; i++ {
+		c := Block{cursor, cursor.mkLastIP(mask)}
+		out = append(out, c)
+		cursor = c.last.addOne()
+	}
+	return out, nil
+}
+
+// Offset returns the CIDR n sibling CIDRs of the same size away from b, e.g. "the next /24".
+// n may be negative. ok is false if b is no CIDR or the result overflows the address space.
+func (b Block) Offset(n int64) (c Block, ok bool) {
+	if !b.IsCIDR() {
+		return Block{}, false
+	}
+
+	// the prefix length in the 128 bit space
+	l := int(b.base.commonPrefixLen(b.last))
+	hostBits := 128 - l
+
+	abs := uint64(n)
+	if n < 0 {
+		abs = -abs
+	}
+	if bits.Len64(abs)+hostBits > 128 {
+		return Block{}, false
+	}
+	step := uint128{0, abs}.lsh(hostBits)
+
+	var overflow uint64
+	base := b.base
+	if n < 0 {
+		base.uint128, overflow = base.subBorrow(step)
+	} else {
+		base.uint128, overflow = base.add(step)
+	}
+	if overflow != 0 || base.version == v4 && (base.hi != 0 || base.lo > math.MaxUint32) {
+		return Block{}, false
+	}
+
+	// base is aligned, last can't overflow
+	return Block{base, base.mkLastIP(maskUint128[l])}, true
+}
+
+// Next returns the adjacent CIDR of the same size after b, see Offset.
+func (b Block) Next() (Block, bool) {
+	return b.Offset(1)
+}
+
+// Prev returns the adjacent CIDR of the same size before b, see Offset.
+func (b Block) Prev() (Block, bool) {
+	return b.Offset(-1)
+}
+
+// SplitRecursive splits the CIDR b level by level, the subnets of each level are split by the next bits.
+// The subnets of all levels are returned flat in pre-order, every subnet followed by its own subnets,
+// e.g. ready to build a tree. The CIDR b itself isn't returned.
+//
+// Returns an error if b is no CIDR or the bits overflow the address length,
+// and an error wrapping ErrLimit if the subnets of all levels exceed MaxCIDRSplit.
+func (b Block) SplitRecursive(bits ...int) ([]Block, error) {
+	if len(bits) == 0 {
+		if !b.IsCIDR() {
+			return nil, fmt.Errorf("%v: not a CIDR: %v", invalidBlock, b)
+		}
+		return nil, nil
+	}
+
+	// check the total before allocating anything
+	if err := checkSplit(b, splitCount(bits)); err != nil {
+		return nil, err
+	}
+
+	subnets, err := b.SplitCIDR(bits[0])
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Block, 0, len(subnets))
+	for _, sub := range subnets {
+		out = append(out, sub)
+
+		deeper, err := sub.SplitRecursive(bits[1:]...)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, deeper...)
+	}
+	return out, nil
+}
+
 // CIDRs returns a list of CIDRs that span b.
+//
+// The worst case is 2*(bits-1) CIDRs, 62 for IPv4 and 254 for IPv6, e.g. for ::1-ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe.
+// Use CIDRsN to limit the output for untrusted input.
 func (b Block) CIDRs() []Block {
 	if !b.IsValid() {
 		return nil
@@ -282,6 +652,49 @@ func (b Block) CIDRs() []Block {
 	return b.base.toCIDRsRec(b.last, nil)
 }
 
+// CIDRsN returns at most max CIDRs that span b, in ascending order, same as CIDRs but stops early.
+// Reports true if the list is truncated, the first missing CIDR starts at the last returned plus one.
+func (b Block) CIDRsN(max int) ([]Block, bool) {
+	if !b.IsValid() {
+		return nil, false
+	}
+	if max < 0 {
+		max = 0
+	}
+
+	var out []Block
+	for start := b.base; ; {
+		if len(out) == max {
+			return out, true
+		}
+		cidr := MaxCIDRAt(start, b.last)
+		out = append(out, cidr)
+
+		if cidr.last == b.last {
+			return out, false
+		}
+		start = cidr.last.addOne()
+	}
+}
+
+// MaxCIDRAt returns the largest CIDR beginning exactly at start and not passing notBeyond,
+// the core step of range to CIDR conversion and of first-fit allocation.
+// Returns Block{} if start or notBeyond is invalid, the IP versions differ or notBeyond is before start.
+func MaxCIDRAt(start, notBeyond IP) Block {
+	if !start.IsValid() || start.version != notBeyond.version || notBeyond.Less(start) {
+		return Block{}
+	}
+
+	// the largest CIDR aligned at start, internal prefix lengths are 128 bit based,
+	// shrink until notBeyond isn't passed, ends at the latest with start itself
+	for n := 128 - start.TrailingZeros(); ; n++ {
+		last := start.mkLastIP(maskUint128[n])
+		if !notBeyond.Less(last) {
+			return Block{start, last}
+		}
+	}
+}
+
 // recursion ahead
 // end condition: isCIDR
 // split the range in the middle
@@ -315,14 +728,14 @@ func (b Block) Diff(bs []Block) []Block {
 	}
 
 	// to remove blocks must be sorted for this algo!
-	sort.Slice(bs, func(i, j int) bool { return bs[i].Less(bs[j]) })
+	SortBlocks(bs)
 
 	var out []Block
 	for _, d := range bs {
 		switch {
 		case !d.IsValid():
 			// no-op
-		case d.isDisjunct(b):
+		case d.IsDisjunct(b):
 			// no-op
 		case d == b:
 			// masks rest
@@ -356,13 +769,118 @@ func (b Block) Diff(bs []Block) []Block {
 	return out
 }
 
-// isDisjunct reports whether the Blocks b and c are disjunct
+// Gaps returns the ranges in outer not covered by any of the blocks in bs, in ascending order.
+//
+// In contrast to Diff, bs must already be sorted (see Block.Less) and is not modified,
+// blocks outside of outer or of another IP version are ignored.
+// Use Merge or SortBlocks to get the bs sorted.
+//
+//  outer |-------------------------------|
+//  bs       |---|   |-----|    |--|
+//  gaps  |--|   |---|     |----|  |------|
+func Gaps(outer Block, bs []Block) []Block {
+	if !outer.IsValid() {
+		return nil
+	}
+
+	var out []Block
+	cursor := outer.base
+	for _, b := range bs {
+		if !b.IsValid() || b.IsDisjunct(outer) {
+			continue
+		}
+
+		// gap before b
+		if cursor.Less(b.base) {
+			out = append(out, Block{cursor, b.base.subOne()})
+		}
+
+		// b ends before cursor, e.g. subset of a prior block
+		if b.last.Less(cursor) {
+			continue
+		}
+
+		// overflow or cursor moved behind outer.last
+		cursor = b.last.addOne()
+		if !cursor.IsValid() || outer.last.Less(cursor) {
+			return out
+		}
+	}
+
+	// save the rest
+	return append(out, Block{cursor, outer.last})
+}
+
+// Utilization returns the fraction of outer covered by the blocks in bs, between 0 and 1.
+// Overlapping blocks are counted once, parts of bs outside of outer are ignored.
+// bs is not modified.
+func Utilization(outer Block, bs []Block) float64 {
+	if !outer.IsValid() {
+		return 0
+	}
+
+	// Gaps needs sorted input, decouple from caller
+	sorted := make([]Block, len(bs))
+	copy(sorted, bs)
+	SortBlocks(sorted)
+
+	free := new(big.Int)
+	for _, g := range Gaps(outer, sorted) {
+		free.Add(free, g.Size())
+	}
+
+	size := outer.Size()
+	used := new(big.Int).Sub(size, free)
+
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(used), new(big.Float).SetInt(size)).Float64()
+	return f
+}
+
+// TotalSize returns the number of unique addresses covered by the blocks, per IP version.
+// Overlaps and duplicates are merged before counting, invalid blocks are ignored, bs is not modified.
+func TotalSize(bs []Block) (v4, v6 *big.Int) {
+	v4, v6 = new(big.Int), new(big.Int)
+
+	// Merge sorts in place, decouple from caller
+	merged := make([]Block, len(bs))
+	copy(merged, bs)
+
+	for _, b := range Merge(merged) {
+		switch {
+		case !b.IsValid():
+			// no-op
+		case b.Is4():
+			v4.Add(v4, b.Size())
+		default:
+			v6.Add(v6, b.Size())
+		}
+	}
+	return
+}
+
+// SplitByVersion returns the IPv4 and IPv6 blocks of bs, the order is retained.
+// Invalid blocks are skipped, bs is not modified.
+func SplitByVersion(bs []Block) (v4, v6 []Block) {
+	for _, b := range bs {
+		switch {
+		case b.Is4():
+			v4 = append(v4, b)
+		case b.Is6():
+			v6 = append(v6, b)
+		}
+	}
+	return
+}
+
+// IsDisjunct reports whether the Blocks b and c are disjunct.
+// Blocks of different IP versions are always disjunct.
+//
 //  b       |----------|
 //  c |---|
 //
 //  b |------|
 //  c          |---|
-func (b Block) isDisjunct(c Block) bool {
+func (b Block) IsDisjunct(c Block) bool {
 	//  a       |----------|
 	//  b |---|
 	if c.last.Less(b.base) {
@@ -378,7 +896,7 @@ func (b Block) isDisjunct(c Block) bool {
 	return false
 }
 
-// overlaps reports whether the Blocks overlaps.
+// Overlaps reports whether the Blocks overlap partially, neither covers the other nor are they equal.
 //
 //  b    |-------|
 //  c |------|
@@ -391,14 +909,14 @@ func (b Block) isDisjunct(c Block) bool {
 //
 //  b      |---------|
 //  c |----|
-func (b Block) overlaps(c Block) bool {
+func (b Block) Overlaps(c Block) bool {
 	if b == c {
 		return false
 	}
 	if b.Covers(c) || c.Covers(b) {
 		return false
 	}
-	if b.isDisjunct(c) {
+	if b.IsDisjunct(c) {
 		return false
 	}
 	return true
@@ -1,6 +1,9 @@
 package inet
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"math/rand"
 	"net"
 	"reflect"
@@ -110,6 +113,18 @@ func TestParseBlockFail(t *testing.T) {
 	}
 }
 
+func TestParseBlockSingleRange(t *testing.T) {
+	for _, in := range []string{"4.4.4.4-4.4.4.4", "2001:db8::1-2001:db8::1"} {
+		b, err := ParseBlock(in)
+		if err != nil {
+			t.Fatalf("ParseBlock(%s), unexpected error: %v", in, err)
+		}
+		if b.Base() != b.Last() || !b.IsCIDR() || b.StringRange() != in {
+			t.Errorf("ParseBlock(%s), got %v, want single address", in, b)
+		}
+	}
+}
+
 func TestSortBlock(t *testing.T) {
 
 	sorted := []string{
@@ -378,7 +393,7 @@ func TestBlockToCIDRsV6cornerCase(t *testing.T) {
 	}
 }
 
-func TestBlockIsDisjunctWith(t *testing.T) {
+func TestBlockIsDisjunct(t *testing.T) {
 	tests := []struct {
 		a, b string
 		want bool
@@ -441,14 +456,14 @@ func TestBlockIsDisjunctWith(t *testing.T) {
 		ra := mustBlock(a)
 		rb := mustBlock(b)
 
-		got := ra.isDisjunct(rb)
+		got := ra.IsDisjunct(rb)
 		if got != want {
-			t.Errorf("(%v).IsDisjunctWith(%v) = %v; want %v", ra, rb, got, want)
+			t.Errorf("(%v).IsDisjunct(%v) = %v; want %v", ra, rb, got, want)
 		}
 	}
 }
 
-func TestBlockHasOverlapWith(t *testing.T) {
+func TestBlockOverlaps(t *testing.T) {
 
 	tests := []struct {
 		a, b string
@@ -512,9 +527,9 @@ func TestBlockHasOverlapWith(t *testing.T) {
 		ra := mustBlock(a)
 		rb := mustBlock(b)
 
-		got := ra.overlaps(rb)
+		got := ra.Overlaps(rb)
 		if got != want {
-			t.Errorf("(%v).HasOverlapWith(%v) = %v; want %v", ra, rb, got, want)
+			t.Errorf("(%v).Overlaps(%v) = %v; want %v", ra, rb, got, want)
 		}
 	}
 }
@@ -524,11 +539,11 @@ func TestBlockV4V6(t *testing.T) {
 	r1 := mustBlock("0.0.0.0/0")
 	r2 := mustBlock("::/0")
 
-	if r1.overlaps(r2) != false {
-		t.Errorf("%q.OverlapsWith(%q) == %t, want %t", r1, r2, r1.overlaps(r2), false)
+	if r1.Overlaps(r2) != false {
+		t.Errorf("%q.Overlaps(%q) == %t, want %t", r1, r2, r1.Overlaps(r2), false)
 	}
-	if r2.overlaps(r1) != false {
-		t.Errorf("%q.OverlapsWith(%q) == %t, want %t", r2, r1, r2.overlaps(r1), false)
+	if r2.Overlaps(r1) != false {
+		t.Errorf("%q.Overlaps(%q) == %t, want %t", r2, r1, r2.Overlaps(r1), false)
 	}
 	if r2.Covers(r1) != false {
 		t.Errorf("%q.Covers(%q) == %t, want %t", r2, r1, r2.Covers(r1), false)
@@ -536,11 +551,11 @@ func TestBlockV4V6(t *testing.T) {
 	if r1.Covers(r2) != false {
 		t.Errorf("%q.Covers(%q) == %t, want %t", r1, r2, r1.Covers(r2), false)
 	}
-	if r1.isDisjunct(r2) != true {
-		t.Errorf("%q.IsDisjunctWith(%q) == %t, want %t", r1, r2, r1.isDisjunct(r2), true)
+	if r1.IsDisjunct(r2) != true {
+		t.Errorf("%q.IsDisjunct(%q) == %t, want %t", r1, r2, r1.IsDisjunct(r2), true)
 	}
-	if r2.isDisjunct(r1) != true {
-		t.Errorf("%q.IsDisjunctWith(%q) == %t, want %t", r2, r1, r2.isDisjunct(r1), true)
+	if r2.IsDisjunct(r1) != true {
+		t.Errorf("%q.IsDisjunct(%q) == %t, want %t", r2, r1, r2.IsDisjunct(r1), true)
 	}
 }
 
@@ -660,3 +675,900 @@ func TestFindDiffIANAv6(t *testing.T) {
 		t.Errorf("Diff for IANAv6 blocks, got %v, want %v", rs, want)
 	}
 }
+
+func TestGaps(t *testing.T) {
+	tests := []struct {
+		outer string
+		bs    []string
+		want  []string
+	}{
+		{
+			outer: "10.0.0.0/24",
+			bs:    nil,
+			want:  []string{"10.0.0.0/24"},
+		},
+		{
+			outer: "10.0.0.0/24",
+			bs:    []string{"10.0.0.0/24"},
+			want:  nil,
+		},
+		{
+			outer: "10.0.0.0/24",
+			bs:    []string{"10.0.0.0/8", "::/0"},
+			want:  nil,
+		},
+		{
+			outer: "10.0.0.0/24",
+			bs:    []string{"9.255.255.250-10.0.0.9", "10.0.0.16/28", "10.0.0.20", "10.0.0.200-10.0.1.7"},
+			want:  []string{"10.0.0.10-10.0.0.15", "10.0.0.32-10.0.0.199"},
+		},
+		{
+			outer: "10.0.0.0/24",
+			bs:    []string{"10.0.0.10/31", "10.0.0.12/30", "172.16.0.0/12"},
+			want:  []string{"10.0.0.0-10.0.0.9", "10.0.0.16-10.0.0.255"},
+		},
+		{
+			outer: "::/0",
+			bs:    []string{"::/1", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+			want:  []string{"8000::-ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe"},
+		},
+	}
+
+	for _, tt := range tests {
+		var bs []Block
+		for _, s := range tt.bs {
+			bs = append(bs, mustBlock(s))
+		}
+		var want []Block
+		for _, s := range tt.want {
+			want = append(want, mustBlock(s))
+		}
+
+		got := Gaps(mustBlock(tt.outer), bs)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Gaps(%v, %v), got %v, want %v", tt.outer, tt.bs, got, want)
+		}
+	}
+
+	if got := Gaps(Block{}, nil); got != nil {
+		t.Errorf("Gaps(invalid, nil), got %v, want nil", got)
+	}
+}
+
+func TestCoveringCIDR(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"10.0.0.0/8", "10.0.0.0/8"},
+		{"10.0.0.1", "10.0.0.1/32"},
+		{"10.0.0.15-10.0.0.236", "10.0.0.0/24"},
+		{"10.0.0.255-10.0.1.0", "10.0.0.0/23"},
+		{"0.0.0.1-255.255.255.254", "0.0.0.0/0"},
+		{"2001:db8::1-2001:db8::1234", "2001:db8::/115"},
+		{"::1-ffff::", "::/0"},
+	}
+
+	for _, tt := range tests {
+		if got := mustBlock(tt.in).CoveringCIDR(); got != mustBlock(tt.want) {
+			t.Errorf("(%v).CoveringCIDR(), got %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	if got := (Block{}).CoveringCIDR(); got != (Block{}) {
+		t.Errorf("CoveringCIDR() on invalid block, got %v, want %v", got, Block{})
+	}
+}
+
+func TestCoveringCIDRList(t *testing.T) {
+	bs := []Block{
+		mustBlock("10.0.1.0/24"),
+		mustBlock("10.0.0.7"),
+		mustBlock("10.0.3.0-10.0.3.17"),
+	}
+	got, err := CoveringCIDR(bs)
+	if err != nil {
+		t.Fatalf("CoveringCIDR(%v), unexpected error: %v", bs, err)
+	}
+	if want := mustBlock("10.0.0.0/22"); got != want {
+		t.Errorf("CoveringCIDR(%v), got %v, want %v", bs, got, want)
+	}
+
+	for _, bs := range [][]Block{
+		nil,
+		{mustBlock("10.0.0.0/8"), {}},
+		{mustBlock("10.0.0.0/8"), mustBlock("::/0")},
+	} {
+		if _, err := CoveringCIDR(bs); err == nil {
+			t.Errorf("CoveringCIDR(%v), expected error", bs)
+		}
+	}
+}
+
+func TestUsableHosts(t *testing.T) {
+	tests := []struct {
+		in          string
+		hosts       string
+		first, last string
+	}{
+		{"10.0.0.0/24", "254", "10.0.0.1", "10.0.0.254"},
+		{"10.0.0.0/30", "2", "10.0.0.1", "10.0.0.2"},
+		{"10.0.0.0/31", "2", "10.0.0.0", "10.0.0.1"},
+		{"10.0.0.7/32", "1", "10.0.0.7", "10.0.0.7"},
+		{"10.0.0.3-10.0.0.17", "15", "10.0.0.3", "10.0.0.17"},
+		{"0.0.0.0/0", "4294967294", "0.0.0.1", "255.255.255.254"},
+		{"2001:db8::/64", "18446744073709551616", "2001:db8::", "2001:db8::ffff:ffff:ffff:ffff"},
+		{"::/0", "340282366920938463463374607431768211456", "::", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+	}
+
+	for _, tt := range tests {
+		b := mustBlock(tt.in)
+		if got := b.UsableHosts().String(); got != tt.hosts {
+			t.Errorf("(%v).UsableHosts(), got %v, want %v", b, got, tt.hosts)
+		}
+		if got := b.FirstHost(); got != mustIP(tt.first) {
+			t.Errorf("(%v).FirstHost(), got %v, want %v", b, got, tt.first)
+		}
+		if got := b.LastHost(); got != mustIP(tt.last) {
+			t.Errorf("(%v).LastHost(), got %v, want %v", b, got, tt.last)
+		}
+	}
+
+	var b Block
+	if got := b.UsableHosts().Sign(); got != 0 {
+		t.Errorf("UsableHosts() on invalid block, got %v, want 0", got)
+	}
+	if got := b.FirstHost(); got.IsValid() {
+		t.Errorf("FirstHost() on invalid block, got %v, want %v", got, IP{})
+	}
+}
+
+func TestSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"10.0.0.0/8", "16777216"},
+		{"10.0.0.3-10.0.0.17", "15"},
+		{"::1", "1"},
+		{"::/0", "340282366920938463463374607431768211456"},
+	}
+	for _, tt := range tests {
+		if got := mustBlock(tt.in).Size().String(); got != tt.want {
+			t.Errorf("(%v).Size(), got %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	if got := (Block{}).Size().Sign(); got != 0 {
+		t.Errorf("Size() on invalid block, got %v, want 0", got)
+	}
+}
+
+func TestUtilization(t *testing.T) {
+	tests := []struct {
+		outer string
+		bs    []string
+		want  float64
+	}{
+		{"10.0.0.0/24", nil, 0},
+		{"10.0.0.0/24", []string{"10.0.0.0/8"}, 1},
+		{"10.0.0.0/24", []string{"10.0.0.128/25", "10.0.0.0/26", "10.0.0.0/27"}, 0.75},
+		{"10.0.0.0/24", []string{"10.0.0.192-10.0.1.7", "::/0"}, 0.25},
+		{"::/0", []string{"::/1"}, 0.5},
+	}
+
+	for _, tt := range tests {
+		var bs []Block
+		for _, s := range tt.bs {
+			bs = append(bs, mustBlock(s))
+		}
+		if got := Utilization(mustBlock(tt.outer), bs); got != tt.want {
+			t.Errorf("Utilization(%v, %v), got %v, want %v", tt.outer, tt.bs, got, tt.want)
+		}
+	}
+
+	if got := Utilization(Block{}, nil); got != 0 {
+		t.Errorf("Utilization(invalid, nil), got %v, want 0", got)
+	}
+}
+
+func TestTotalSize(t *testing.T) {
+	tests := []struct {
+		bs     []string
+		v4, v6 string
+	}{
+		{nil, "0", "0"},
+		{[]string{"10.0.0.0/24", "10.0.0.0/24", "10.0.0.128/25"}, "256", "0"},
+		{[]string{"10.0.0.0/24", "10.0.0.192-10.0.1.7", "10.0.2.0/31"}, "266", "0"},
+		{[]string{"0.0.0.0/0", "::/0", "2001:db8::/32"}, "4294967296", "340282366920938463463374607431768211456"},
+	}
+
+	for _, tt := range tests {
+		var bs []Block
+		for _, s := range tt.bs {
+			bs = append(bs, mustBlock(s))
+		}
+		bs = append(bs, Block{})
+		before := append([]Block(nil), bs...)
+
+		v4, v6 := TotalSize(bs)
+		if v4.String() != tt.v4 || v6.String() != tt.v6 {
+			t.Errorf("TotalSize(%v), got %v, %v, want %v, %v", tt.bs, v4, v6, tt.v4, tt.v6)
+		}
+		if !reflect.DeepEqual(bs, before) {
+			t.Errorf("TotalSize(%v), input modified", tt.bs)
+		}
+	}
+}
+
+func TestParseIPList(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		errs int
+	}{
+		{"", "[]", 0},
+		{"10.0.0.1, 10.0.0.5-10.0.0.9, 192.168.1.0/28", "[10.0.0.1/32 10.0.0.5-10.0.0.9 192.168.1.0/28]", 0},
+		{"10.0.0.5 - 10.0.0.9;10.0.0.1\n10.0.0.2 ,,10.0.0.3 -10.0.0.4", "[10.0.0.1-10.0.0.9]", 0},
+		{"2001:db8::1 2001:db8::/32\t::1", "[::1/128 2001:db8::/32]", 0},
+		{"10.0.0.1, foo, 10.0.0.9-10.0.0.5, 10.0.0.2", "[10.0.0.1-10.0.0.2]", 2},
+		{"10.0.0.1 -", "[]", 1},
+	}
+
+	for _, tt := range tests {
+		bs, err := ParseIPList(tt.in)
+		if got := fmt.Sprint(bs); got != tt.want {
+			t.Errorf("ParseIPList(%q), got %s, want %s", tt.in, got, tt.want)
+		}
+
+		var le *ListError
+		switch {
+		case tt.errs == 0 && err != nil:
+			t.Errorf("ParseIPList(%q), unexpected error: %v", tt.in, err)
+		case tt.errs > 0 && (!errors.As(err, &le) || len(le.Errs) != tt.errs):
+			t.Errorf("ParseIPList(%q), got error %v, want %d errors", tt.in, err, tt.errs)
+		}
+	}
+}
+
+func TestParseBlockLoose(t *testing.T) {
+	tests := []struct {
+		in    string
+		want  string
+		fixes int
+	}{
+		{"10.0.0.0/8", "10.0.0.0/8", 0},
+		{" 10.0.0.0 / 8 ", "10.0.0.0/8", 2},
+		{"10.0.0.1–10.0.0.9", "10.0.0.1-10.0.0.9", 1},
+		{"10.0.0.1 — 10.0.0.9", "10.0.0.1-10.0.0.9", 2},
+		{"10.0.0.1..10.0.0.9", "10.0.0.1-10.0.0.9", 1},
+		{"10.0.0.1.", "10.0.0.1/32", 1},
+		{"10.0.0.0./24", "10.0.0.0/24", 1},
+		{"2001:db8:: - 2001:db8::ff", "2001:db8::/120", 1},
+	}
+
+	for _, tt := range tests {
+		b, fixes, err := ParseBlockLoose(tt.in)
+		if err != nil {
+			t.Errorf("ParseBlockLoose(%q), unexpected error: %v", tt.in, err)
+			continue
+		}
+		if b.String() != tt.want || len(fixes) != tt.fixes {
+			t.Errorf("ParseBlockLoose(%q), got %v, %q, want %v with %d fixes", tt.in, b, fixes, tt.want, tt.fixes)
+		}
+	}
+
+	for _, s := range []string{"", "foo", "10.0.0.9-10.0.0.1", "10.0.0.0 / 33"} {
+		if _, _, err := ParseBlockLoose(s); err == nil {
+			t.Errorf("ParseBlockLoose(%q), want error", s)
+		}
+	}
+}
+
+func TestSplitRecursive(t *testing.T) {
+	got, err := mustBlock("10.0.0.0/24").SplitRecursive(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[10.0.0.0/25 10.0.0.0/27 10.0.0.32/27 10.0.0.64/27 10.0.0.96/27 10.0.0.128/25 10.0.0.128/27 10.0.0.160/27 10.0.0.192/27 10.0.0.224/27]"
+	if fmt.Sprint(got) != want {
+		t.Errorf("SplitRecursive(1, 2), got %v, want %v", got, want)
+	}
+
+	if got, err := mustBlock("10.0.0.0/24").SplitRecursive(); got != nil || err != nil {
+		t.Errorf("SplitRecursive(), got %v, %v, want nil, nil", got, err)
+	}
+
+	if _, err := mustBlock("10.0.0.0/24").SplitRecursive(4, 4, 1); err == nil {
+		t.Errorf("SplitRecursive(4, 4, 1), want error")
+	}
+	if _, err := mustBlock("10.0.0.1-10.0.0.5").SplitRecursive(); err == nil {
+		t.Errorf("SplitRecursive on range, want error")
+	}
+}
+
+func TestOffset(t *testing.T) {
+	tests := []struct {
+		b    string
+		n    int64
+		want string
+		ok   bool
+	}{
+		{"10.0.0.0/24", 1, "10.0.1.0/24", true},
+		{"10.0.0.0/24", -1, "9.255.255.0/24", true},
+		{"10.0.0.0/24", 256, "10.1.0.0/24", true},
+		{"10.0.0.0/24", 0, "10.0.0.0/24", true},
+		{"255.255.255.0/24", 1, "", false},
+		{"0.0.0.0/24", -1, "", false},
+		{"0.0.0.0/1", 1, "128.0.0.0/1", true},
+		{"0.0.0.0/0", 1, "", false},
+		{"10.0.0.1", math.MaxInt64, "", false},
+		{"2001:db8::/32", 1, "2001:db9::/32", true},
+		{"2001:db8::/32", -0x2001 << 16, "0:db8::/32", true},
+		{"2001:db8::/32", -0x2002 << 16, "", false},
+		{"::/64", math.MaxInt64, "7fff:ffff:ffff:ffff::/64", true},
+		{"::/64", math.MinInt64, "", false},
+		{"ffff:ffff:ffff:ffff::/64", 1, "", false},
+		{"::1", -1, "::/128", true},
+		{"10.0.0.1-10.0.0.5", 1, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := mustBlock(tt.b).Offset(tt.n)
+		if ok != tt.ok || ok && got.String() != tt.want {
+			t.Errorf("%s.Offset(%d), got %v, %v, want %s, %v", tt.b, tt.n, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	b := mustBlock("10.0.0.0/24")
+	if next, _ := b.Next(); next != mustBlock("10.0.1.0/24") {
+		t.Errorf("Next(), got %v", next)
+	}
+	if prev, _ := b.Prev(); prev != mustBlock("9.255.255.0/24") {
+		t.Errorf("Prev(), got %v", prev)
+	}
+}
+
+func TestSplitByVersion(t *testing.T) {
+	bs := []Block{mustBlock("::1"), mustBlock("10.0.0.0/8"), {}, mustBlock("2001:db8::/32"), mustBlock("1.2.3.4-1.2.3.5")}
+
+	v4, v6 := SplitByVersion(bs)
+	if want := []Block{mustBlock("10.0.0.0/8"), mustBlock("1.2.3.4-1.2.3.5")}; !reflect.DeepEqual(v4, want) {
+		t.Errorf("SplitByVersion, v4 got %v, want %v", v4, want)
+	}
+	if want := []Block{mustBlock("::1"), mustBlock("2001:db8::/32")}; !reflect.DeepEqual(v6, want) {
+		t.Errorf("SplitByVersion, v6 got %v, want %v", v6, want)
+	}
+}
+
+func TestBits(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"0.0.0.0/0", 0},
+		{"10.0.0.0/8", 8},
+		{"10.0.0.1", 32},
+		{"10.0.0.1-10.0.0.2", -1},
+		{"::/0", 0},
+		{"2001:db8::/32", 32},
+		{"::1", 128},
+	}
+	for _, tt := range tests {
+		if got := mustBlock(tt.in).Bits(); got != tt.want {
+			t.Errorf("(%v).Bits(), got %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	if got := (Block{}).Bits(); got != -1 {
+		t.Errorf("Bits() on invalid block, got %v, want -1", got)
+	}
+}
+
+func TestSplitCIDR(t *testing.T) {
+	tests := []struct {
+		in   string
+		bits int
+		want []string
+	}{
+		{"10.0.0.0/8", 0, []string{"10.0.0.0/8"}},
+		{"10.0.0.0/8", 2, []string{"10.0.0.0/10", "10.64.0.0/10", "10.128.0.0/10", "10.192.0.0/10"}},
+		{"255.255.255.252/30", 2, []string{"255.255.255.252", "255.255.255.253", "255.255.255.254", "255.255.255.255"}},
+		{"::/0", 1, []string{"::/1", "8000::/1"}},
+		{"2001:db8::/63", 1, []string{"2001:db8::/64", "2001:db8:0:1::/64"}},
+	}
+
+	for _, tt := range tests {
+		got, err := mustBlock(tt.in).SplitCIDR(tt.bits)
+		if err != nil {
+			t.Errorf("(%v).SplitCIDR(%d), unexpected error: %v", tt.in, tt.bits, err)
+			continue
+		}
+		var want []Block
+		for _, s := range tt.want {
+			want = append(want, mustBlock(s))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("(%v).SplitCIDR(%d), got %v, want %v", tt.in, tt.bits, got, want)
+		}
+	}
+
+	for _, tt := range []struct {
+		in   string
+		bits int
+	}{
+		{"10.0.0.0/30", 3},
+		{"10.0.0.0/8", -1},
+		{"10.0.0.1-10.0.0.2", 1},
+	} {
+		if _, err := mustBlock(tt.in).SplitCIDR(tt.bits); err == nil {
+			t.Errorf("(%v).SplitCIDR(%d), expected error", tt.in, tt.bits)
+		}
+	}
+}
+
+func TestBlockFromIP(t *testing.T) {
+	ip := mustIP("2001:db8::1")
+	b, err := BlockFromIP(ip)
+	if err != nil {
+		t.Errorf("BlockFromIP(%v), unexpected error: %v", ip, err)
+	}
+	if b != mustBlock("2001:db8::1/128") {
+		t.Errorf("BlockFromIP(%v), got %v, want %v", ip, b, "2001:db8::1/128")
+	}
+
+	if _, err := BlockFromIP(IP{}); err == nil {
+		t.Errorf("BlockFromIP(invalid), expected error")
+	}
+}
+
+func TestBlockFromRange(t *testing.T) {
+	b, err := BlockFromRange(mustIP("10.0.0.3"), mustIP("10.0.17.134"))
+	if err != nil || b != mustBlock("10.0.0.3-10.0.17.134") {
+		t.Errorf("BlockFromRange, got %v, %v", b, err)
+	}
+
+	for _, tt := range [][2]IP{
+		{IP{}, mustIP("10.0.0.1")},
+		{mustIP("10.0.0.1"), mustIP("::1")},
+		{mustIP("10.0.0.2"), mustIP("10.0.0.1")},
+	} {
+		if _, err := BlockFromRange(tt[0], tt[1]); err == nil {
+			t.Errorf("BlockFromRange(%v, %v), expected error", tt[0], tt[1])
+		}
+	}
+}
+
+func TestHintString(t *testing.T) {
+	tests := []struct {
+		in             string
+		nibble, hextet bool
+		want           string
+	}{
+		{"2001:db8:a00::/40", true, false, "2001:0db8:0a<</40"},
+		{"2001:db8::/32", true, true, "2001:0db8<</32"},
+		{"2001:db8::/36", true, false, "2001:0db8:0<</36"},
+		{"2001:db8::/33", false, false, "2001:0db8:0000:0000:0000:0000:0000:0000~~/33"},
+		{"2001:db8::1/128", true, true, "2001:0db8:0000:0000:0000:0000:0000:0001<</128"},
+		{"::/0", true, true, "<</0"},
+		{"10.0.0.0/8", true, false, "010<</8"},
+		{"10.0.0.0/16", true, true, "010.000<</16"},
+		{"10.0.0.1/32", true, true, "010.000.000.001<</32"},
+		{"10.0.0.0/12", true, false, "010.000.000.000~~/12"},
+		{"10.0.0.0/25", false, false, "010.000.000.000~~/25"},
+		{"10.0.0.3-10.0.0.17", false, false, "10.0.0.3-10.0.0.17"},
+	}
+
+	for _, tt := range tests {
+		b := mustBlock(tt.in)
+		if got := b.NibbleAligned(); got != tt.nibble {
+			t.Errorf("%s.NibbleAligned(), got %v, want %v", tt.in, got, tt.nibble)
+		}
+		if got := b.HextetAligned(); got != tt.hextet {
+			t.Errorf("%s.HextetAligned(), got %v, want %v", tt.in, got, tt.hextet)
+		}
+		if got := b.HintString(); got != tt.want {
+			t.Errorf("%s.HintString(), got %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if (Block{}).NibbleAligned() || (Block{}).HextetAligned() {
+		t.Errorf("zero value, want not aligned")
+	}
+}
+
+func TestSplitToAligned(t *testing.T) {
+	tests := []struct {
+		in   string
+		bits int
+		want string
+	}{
+		{"2001:db8::/33", 4, "[2001:db8::/36 2001:db8:1000::/36 2001:db8:2000::/36 2001:db8:3000::/36 2001:db8:4000::/36 2001:db8:5000::/36 2001:db8:6000::/36 2001:db8:7000::/36]"},
+		{"2001:db8::/32", 4, "[2001:db8::/32]"},
+		{"10.0.0.0/23", 8, "[10.0.0.0/24 10.0.1.0/24]"},
+		{"10.0.0.0-10.0.2.255", 8, "[10.0.0.0/24 10.0.1.0/24 10.0.2.0/24]"},
+		{"10.0.0.0/31", 5, "[10.0.0.0/32 10.0.0.1/32]"},
+		{"10.0.0.0/16", 1, "[10.0.0.0/16]"},
+	}
+
+	for _, tt := range tests {
+		bs, err := mustBlock(tt.in).SplitToAligned(tt.bits)
+		if err != nil {
+			t.Fatalf("%s.SplitToAligned(%d), unexpected error: %v", tt.in, tt.bits, err)
+		}
+		if got := fmt.Sprint(bs); got != tt.want {
+			t.Errorf("%s.SplitToAligned(%d), got %s, want %s", tt.in, tt.bits, got, tt.want)
+		}
+		for _, b := range bs {
+			if !b.AlignedTo(tt.bits) && b.Bits() != 32 && b.Bits() != 128 {
+				t.Errorf("%s.SplitToAligned(%d), %v not aligned", tt.in, tt.bits, b)
+			}
+		}
+	}
+
+	if _, err := mustBlock("10.0.0.0/8").SplitToAligned(0); err == nil {
+		t.Errorf("SplitToAligned(0), want error")
+	}
+	if _, err := (Block{}).SplitToAligned(4); err == nil {
+		t.Errorf("Block{}.SplitToAligned(4), want error")
+	}
+
+	// 2^63 parts, must not be dropped silently
+	if _, err := mustBlock("::/1").SplitToAligned(64); !errors.Is(err, ErrLimit) {
+		t.Errorf("SplitToAligned(64), got %v, want ErrLimit", err)
+	}
+
+	if !mustBlock("10.0.0.0/24").AlignedTo(8) || mustBlock("10.0.0.0/23").AlignedTo(8) || mustBlock("10.0.0.0/24").AlignedTo(0) {
+		t.Errorf("AlignedTo, unexpected result")
+	}
+}
+
+func TestMaxCIDRAt(t *testing.T) {
+	tests := []struct {
+		start, notBeyond string
+		want             string
+	}{
+		{"10.0.0.0", "10.0.0.255", "10.0.0.0/24"},
+		{"10.0.0.0", "10.0.1.254", "10.0.0.0/24"},
+		{"10.0.0.0", "10.255.255.255", "10.0.0.0/8"},
+		{"10.0.0.0", "255.255.255.255", "10.0.0.0/7"},
+		{"10.0.0.3", "10.0.0.17", "10.0.0.3/32"},
+		{"10.0.0.4", "10.0.0.17", "10.0.0.4/30"},
+		{"10.0.0.8", "10.0.0.17", "10.0.0.8/29"},
+		{"0.0.0.0", "255.255.255.255", "0.0.0.0/0"},
+		{"255.255.255.255", "255.255.255.255", "255.255.255.255/32"},
+		{"::", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", "::/0"},
+		{"2001:db8::", "2001:db8:ffff:ffff:ffff:ffff:ffff:ffff", "2001:db8::/32"},
+		{"2001:db8::", "2001:db9::", "2001:db8::/32"},
+	}
+
+	for _, tt := range tests {
+		got := MaxCIDRAt(mustIP(tt.start), mustIP(tt.notBeyond))
+		if got != mustBlock(tt.want) {
+			t.Errorf("MaxCIDRAt(%s, %s), got %v, want %s", tt.start, tt.notBeyond, got, tt.want)
+		}
+	}
+
+	// agrees with CIDRs
+	for _, s := range []string{"10.0.0.3-10.0.17.134", "2001:db8::1-2001:db8::ff:ffff"} {
+		b := mustBlock(s)
+		if got, want := MaxCIDRAt(b.Base(), b.Last()), b.CIDRs()[0]; got != want {
+			t.Errorf("MaxCIDRAt(%v, %v), got %v, want %v", b.Base(), b.Last(), got, want)
+		}
+	}
+
+	for _, tt := range [][2]string{{"10.0.0.2", "10.0.0.1"}, {"10.0.0.1", "::1"}} {
+		if got := MaxCIDRAt(mustIP(tt[0]), mustIP(tt[1])); got.IsValid() {
+			t.Errorf("MaxCIDRAt(%s, %s), got %v, want Block{}", tt[0], tt[1], got)
+		}
+	}
+	if got := MaxCIDRAt(IP{}, IP{}); got.IsValid() {
+		t.Errorf("MaxCIDRAt(IP{}, IP{}), got %v, want Block{}", got)
+	}
+}
+
+func TestBlockCIDRsN(t *testing.T) {
+	for _, s := range []string{
+		"10.0.0.0/8",
+		"10.0.0.3-10.0.17.134",
+		"0.0.0.1-255.255.255.254",
+		"::1-ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe",
+		"2001:db8::1-2001:db8::ff:ffff",
+	} {
+		b := mustBlock(s)
+		all := b.CIDRs()
+
+		got, truncated := b.CIDRsN(len(all))
+		if truncated || !reflect.DeepEqual(got, all) {
+			t.Errorf("%v.CIDRsN(%d), got %v, %v, want %v, false", b, len(all), got, truncated, all)
+		}
+
+		got, truncated = b.CIDRsN(len(all) + 1)
+		if truncated || !reflect.DeepEqual(got, all) {
+			t.Errorf("%v.CIDRsN(%d), got %v, %v, want %v, false", b, len(all)+1, got, truncated, all)
+		}
+
+		for max := 0; max < len(all); max++ {
+			got, truncated = b.CIDRsN(max)
+			if !truncated || len(got) != max || max > 0 && !reflect.DeepEqual(got, all[:max]) {
+				t.Errorf("%v.CIDRsN(%d), got %v, %v, want %v, true", b, max, got, truncated, all[:max])
+			}
+		}
+	}
+
+	// documented worst cases
+	if n := len(mustBlock("0.0.0.1-255.255.255.254").CIDRs()); n != 62 {
+		t.Errorf("IPv4 worst case, got %d CIDRs, want 62", n)
+	}
+	if n := len(mustBlock("::1-ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe").CIDRs()); n != 254 {
+		t.Errorf("IPv6 worst case, got %d CIDRs, want 254", n)
+	}
+
+	if got, truncated := (Block{}).CIDRsN(10); got != nil || truncated {
+		t.Errorf("Block{}.CIDRsN(10), got %v, %v, want nil, false", got, truncated)
+	}
+}
+
+func TestMergeWithMinPrefix(t *testing.T) {
+	tests := []struct {
+		in         []string
+		min4, min6 int
+		want       []string
+	}{
+		{
+			in:   []string{"10.0.0.0/9", "10.128.0.0/9", "11.0.0.0/8"},
+			min4: 8,
+			want: []string{"10.0.0.0/8", "11.0.0.0/8"},
+		},
+		{
+			in:   []string{"10.0.0.0/9", "10.128.0.0/9", "11.0.0.0/8"},
+			min4: 0,
+			want: []string{"10.0.0.0/7"},
+		},
+		{
+			// input blocks shorter than the minimum are kept
+			in:   []string{"8.0.0.0/6", "12.0.0.0/8", "13.0.0.0/8"},
+			min4: 8,
+			want: []string{"8.0.0.0/6", "12.0.0.0/8", "13.0.0.0/8"},
+		},
+		{
+			in:   []string{"10.0.0.0/25", "10.0.0.128-10.0.1.255", "2001:db8::/33", "2001:db8:8000::/33"},
+			min4: 24,
+			min6: 32,
+			want: []string{"10.0.0.0/24", "10.0.1.0/24", "2001:db8::/32"},
+		},
+		{
+			in:   []string{"2001:db8::/33", "2001:db8:8000::/33"},
+			min6: 33,
+			want: []string{"2001:db8::/33", "2001:db8:8000::/33"},
+		},
+		{
+			// adjacent input /7s merge to a /6, the /7s are kept
+			in:   []string{"8.0.0.0/7", "10.0.0.0/7"},
+			min4: 8,
+			want: []string{"8.0.0.0/7", "10.0.0.0/7"},
+		},
+		{
+			// the /7 is kept, the /9 and the gap to the /6 aggregate are not there
+			in:   []string{"8.0.0.0/7", "10.0.0.0/9"},
+			min4: 8,
+			want: []string{"8.0.0.0/7", "10.0.0.0/9"},
+		},
+		{
+			// the /7 is kept, the /8s beside it merge with it, split back to /8
+			in:   []string{"8.0.0.0/7", "10.0.0.0/8", "11.0.0.0/8"},
+			min4: 8,
+			want: []string{"8.0.0.0/7", "10.0.0.0/8", "11.0.0.0/8"},
+		},
+		{
+			// nested short inputs, just the outer one
+			in:   []string{"8.0.0.0/6", "8.0.0.0/7", "12.0.0.0/7"},
+			min4: 8,
+			want: []string{"8.0.0.0/6", "12.0.0.0/7"},
+		},
+	}
+
+	for _, tt := range tests {
+		var bs []Block
+		for _, s := range tt.in {
+			bs = append(bs, mustBlock(s))
+		}
+		got, err := MergeWithMinPrefix(bs, tt.min4, tt.min6)
+		if err != nil {
+			t.Errorf("MergeWithMinPrefix(%v, %d, %d), unexpected error: %v", tt.in, tt.min4, tt.min6, err)
+			continue
+		}
+
+		var want []Block
+		for _, s := range tt.want {
+			want = append(want, mustBlock(s))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("MergeWithMinPrefix(%v, %d, %d), got %v, want %v", tt.in, tt.min4, tt.min6, got, want)
+		}
+	}
+
+	// the input isn't sorted in place
+	in := []Block{mustBlock("11.0.0.0/8"), mustBlock("10.0.0.0/8")}
+	if _, err := MergeWithMinPrefix(in, 8, 32); err != nil || in[0] != mustBlock("11.0.0.0/8") {
+		t.Errorf("MergeWithMinPrefix, input modified: %v, %v", in, err)
+	}
+
+	if _, err := MergeWithMinPrefix(nil, 33, 0); err == nil {
+		t.Errorf("MergeWithMinPrefix(nil, 33, 0), want error")
+	}
+	if _, err := MergeWithMinPrefix(nil, 0, -1); err == nil {
+		t.Errorf("MergeWithMinPrefix(nil, 0, -1), want error")
+	}
+	if got, err := MergeWithMinPrefix(nil, 8, 32); got != nil || err != nil {
+		t.Errorf("MergeWithMinPrefix(nil, 8, 32), got %v, %v, want nil, nil", got, err)
+	}
+
+	// the input /1s are shorter than the minimum, kept, not split into 2^40 /41
+	bs := []Block{mustBlock("::/1"), mustBlock("8000::/1")}
+	if got, err := MergeWithMinPrefix(bs, 0, 41); err != nil || !reflect.DeepEqual(got, bs) {
+		t.Errorf("MergeWithMinPrefix(::/1, 8000::/1), got %v, %v, want %v", got, err, bs)
+	}
+
+	// aggregate of 16 /12 split again beyond MaxCIDRSplit
+	bs, _ = mustBlock("10.0.0.0/8").SplitCIDR(4)
+	defer func(old int) { MaxCIDRSplit = old }(MaxCIDRSplit)
+	MaxCIDRSplit = 8
+	if _, err := MergeWithMinPrefix(bs, 12, 0); !errors.Is(err, ErrLimit) {
+		t.Errorf("MergeWithMinPrefix(16 /12, 12, 0), got %v, want ErrLimit", err)
+	}
+}
+
+func TestSubtract(t *testing.T) {
+	tests := []struct {
+		allow, deny []string
+		want        []string
+	}{
+		{
+			allow: []string{"10.0.0.0/8"},
+			deny:  []string{"10.0.0.0/9"},
+			want:  []string{"10.128.0.0/9"},
+		},
+		{
+			allow: []string{"10.0.0.0/24", "10.0.1.0/24", "2001:db8::/32"},
+			deny:  []string{"10.0.0.128/25", "10.0.1.0/26", "2001:db8:8000::/33", "192.168.0.0/16"},
+			want:  []string{"10.0.0.0/25", "10.0.1.64/26", "10.0.1.128/25", "2001:db8::/33"},
+		},
+		{
+			allow: []string{"10.0.0.0/8"},
+			deny:  []string{"0.0.0.0/0"},
+			want:  nil,
+		},
+		{
+			allow: []string{"10.0.0.0/8", "::/0"},
+			deny:  nil,
+			want:  []string{"10.0.0.0/8", "::/0"},
+		},
+		{
+			allow: []string{"10.0.0.1-10.0.0.6"},
+			deny:  []string{"10.0.0.3"},
+			want:  []string{"10.0.0.1/32", "10.0.0.2/32", "10.0.0.4/31", "10.0.0.6/32"},
+		},
+	}
+
+	for _, tt := range tests {
+		var allow, deny, want []Block
+		for _, s := range tt.allow {
+			allow = append(allow, mustBlock(s))
+		}
+		for _, s := range tt.deny {
+			deny = append(deny, mustBlock(s))
+		}
+		for _, s := range tt.want {
+			want = append(want, mustBlock(s))
+		}
+
+		origAllow := append([]Block(nil), allow...)
+		origDeny := append([]Block(nil), deny...)
+
+		got := Subtract(allow, deny)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Subtract(%v, %v)\ngot:  %v\nwant: %v", tt.allow, tt.deny, got, want)
+		}
+		if !reflect.DeepEqual(allow, origAllow) || !reflect.DeepEqual(deny, origDeny) {
+			t.Errorf("Subtract(%v, %v), input modified", tt.allow, tt.deny)
+		}
+	}
+}
+
+func TestSameCoverage(t *testing.T) {
+	tests := []struct {
+		a, b         []string
+		onlyA, onlyB []string
+	}{
+		{
+			a: []string{"10.0.0.0/8"},
+			b: []string{"10.0.0.0/9", "10.128.0.0-10.255.255.255"},
+		},
+		{
+			a: []string{"10.0.0.0/24", "10.0.0.0/25", "2001:db8::/32"},
+			b: []string{"2001:db8::/33", "2001:db8:8000::/33", "10.0.0.0-10.0.0.255"},
+		},
+		{
+			a:     []string{"10.0.0.0/24", "2001:db8::/32"},
+			b:     []string{"10.0.0.0/25", "10.0.1.0/24", "2001:db8::/32"},
+			onlyA: []string{"10.0.0.128/25"},
+			onlyB: []string{"10.0.1.0/24"},
+		},
+		{
+			a:     []string{"10.0.0.0/24"},
+			b:     nil,
+			onlyA: []string{"10.0.0.0/24"},
+		},
+		{
+			a: nil,
+			b: nil,
+		},
+	}
+
+	toBlocks := func(ss []string) []Block {
+		var bs []Block
+		for _, s := range ss {
+			bs = append(bs, mustBlock(s))
+		}
+		return bs
+	}
+
+	for _, tt := range tests {
+		a, b := toBlocks(tt.a), toBlocks(tt.b)
+
+		same := len(tt.onlyA) == 0 && len(tt.onlyB) == 0
+		if got := SameCoverage(a, b); got != same {
+			t.Errorf("SameCoverage(%v, %v), got %v, want %v", tt.a, tt.b, got, same)
+		}
+
+		onlyA, onlyB := CoverageDiff(a, b)
+		if want := toBlocks(tt.onlyA); !reflect.DeepEqual(onlyA, want) {
+			t.Errorf("CoverageDiff(%v, %v), onlyA got %v, want %v", tt.a, tt.b, onlyA, want)
+		}
+		if want := toBlocks(tt.onlyB); !reflect.DeepEqual(onlyB, want) {
+			t.Errorf("CoverageDiff(%v, %v), onlyB got %v, want %v", tt.a, tt.b, onlyB, want)
+		}
+	}
+
+	// invalid blocks cover nothing
+	if !SameCoverage([]Block{{}}, nil) {
+		t.Errorf("SameCoverage([Block{}], nil), want true")
+	}
+}
+
+func TestBlockStringRangeCIDRs(t *testing.T) {
+	tests := []struct {
+		in         string
+		rng, cidrs string
+	}{
+		{"10.0.0.0/24", "10.0.0.0-10.0.0.255", "10.0.0.0/24"},
+		{"10.0.0.3-10.0.0.8", "10.0.0.3-10.0.0.8", "10.0.0.3/32,10.0.0.4/30,10.0.0.8/32"},
+		{"1.2.3.4", "1.2.3.4-1.2.3.4", "1.2.3.4/32"},
+		{"2001:db8::/32", "2001:db8::-2001:db8:ffff:ffff:ffff:ffff:ffff:ffff", "2001:db8::/32"},
+		{"2001:db8::1-2001:db8::2", "2001:db8::1-2001:db8::2", "2001:db8::1/128,2001:db8::2/128"},
+	}
+
+	for _, tt := range tests {
+		b := mustBlock(tt.in)
+		if got := b.StringRange(); got != tt.rng {
+			t.Errorf("%v.StringRange(), got %s, want %s", b, got, tt.rng)
+		}
+		if got := b.StringCIDRs(); got != tt.cidrs {
+			t.Errorf("%v.StringCIDRs(), got %s, want %s", b, got, tt.cidrs)
+		}
+
+		// round trips
+		if got := mustBlock(b.StringRange()); got != b {
+			t.Errorf("ParseBlock(%s), got %v, want %v", b.StringRange(), got, b)
+		}
+		if got, err := ParseIPList(b.StringCIDRs()); err != nil || len(got) != 1 || got[0] != b {
+			t.Errorf("ParseIPList(%s), got %v, %v, want %v", b.StringCIDRs(), got, err, b)
+		}
+	}
+
+	var b Block
+	if b.StringRange() != invalidBlock || b.StringCIDRs() != invalidBlock {
+		t.Errorf("Block{}, want %q", invalidBlock)
+	}
+}
@@ -0,0 +1,104 @@
+package inet
+
+import "sync"
+
+// BlockSet is an immutable, sorted set of blocks, safe for concurrent use.
+// The zero value is an empty set.
+type BlockSet struct {
+	blocks []Block
+}
+
+// NewBlockSet returns a frozen set of the valid blocks in bs, duplicates removed.
+// The input slice isn't modified nor retained.
+func NewBlockSet(bs []Block) BlockSet {
+	buf := make([]Block, 0, len(bs))
+	for _, b := range bs {
+		if b.IsValid() {
+			buf = append(buf, b)
+		}
+	}
+	SortBlocks(buf)
+
+	// remove dups
+	out := buf[:0]
+	for i, b := range buf {
+		if i > 0 && b == buf[i-1] {
+			continue
+		}
+		out = append(out, b)
+	}
+	return BlockSet{out[:len(out):len(out)]}
+}
+
+// Len returns the number of blocks in the set.
+func (s BlockSet) Len() int { return len(s.blocks) }
+
+// Blocks returns a sorted copy of the blocks, ready for Diff or tree building.
+func (s BlockSet) Blocks() []Block {
+	return append([]Block(nil), s.blocks...)
+}
+
+// Contains reports whether ip is within any block of the set.
+func (s BlockSet) Contains(ip IP) bool {
+	return ip.IsValid() && Block{ip, ip}.containedIn(s.blocks)
+}
+
+// Covers reports whether b is equal to or covered by any block of the set.
+func (s BlockSet) Covers(b Block) bool {
+	return b.IsValid() && b.containedIn(s.blocks)
+}
+
+// lazySet builds the frozen set on first use.
+type lazySet struct {
+	once  sync.Once
+	build func() []Block
+	set   BlockSet
+}
+
+func (l *lazySet) get() BlockSet {
+	l.once.Do(func() { l.set = NewBlockSet(l.build()) })
+	return l.set
+}
+
+// staticBlocks returns the builder for a package table
+func staticBlocks(bs []Block) func() []Block {
+	return func() []Block { return bs }
+}
+
+var (
+	loopbackSet      = &lazySet{build: staticBlocks(loopbackBlocks)}
+	privateSet       = &lazySet{build: staticBlocks(privateBlocks)}
+	cgnatSet         = &lazySet{build: staticBlocks(cgnatBlocks)}
+	linkLocalSet     = &lazySet{build: staticBlocks(linkLocalBlocks)}
+	multicastSet     = &lazySet{build: staticBlocks(multicastBlocks)}
+	documentationSet = &lazySet{build: staticBlocks(documentationBlocks)}
+	specialSet       = &lazySet{build: func() []Block {
+		bs := make([]Block, 0, len(specials))
+		for _, s := range specials {
+			bs = append(bs, s.Block)
+		}
+		return bs
+	}}
+)
+
+// LoopbackBlocks returns the loopback blocks, 127.0.0.0/8 and ::1/128.
+func LoopbackBlocks() BlockSet { return loopbackSet.get() }
+
+// PrivateBlocks returns the private blocks, RFC 1918 and RFC 4193 (ULA), without the CGNAT space.
+func PrivateBlocks() BlockSet { return privateSet.get() }
+
+// CGNATBlocks returns the shared address space for carrier-grade NAT, 100.64.0.0/10 (RFC 6598).
+func CGNATBlocks() BlockSet { return cgnatSet.get() }
+
+// LinkLocalBlocks returns the link-local unicast blocks, 169.254.0.0/16 and fe80::/10.
+func LinkLocalBlocks() BlockSet { return linkLocalSet.get() }
+
+// MulticastBlocks returns the multicast blocks, 224.0.0.0/4 and ff00::/8.
+func MulticastBlocks() BlockSet { return multicastSet.get() }
+
+// DocumentationBlocks returns the blocks reserved for documentation, RFC 5737, RFC 3849 and RFC 9637.
+func DocumentationBlocks() BlockSet { return documentationSet.get() }
+
+// SpecialBlocks returns all blocks of the IANA special-purpose address registries, see SpecialPurpose.
+// Nested entries are kept, e.g. 192.0.0.0/24 and 192.0.0.9/32.
+func SpecialBlocks() BlockSet { return specialSet.get() }
@@ -0,0 +1,88 @@
+package inet
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestNewBlockSet(t *testing.T) {
+	in := []Block{mustBlock("10.0.0.0/8"), {}, mustBlock("::1"), mustBlock("10.0.0.0/8"), mustBlock("1.2.3.4")}
+	orig := append([]Block(nil), in...)
+
+	s := NewBlockSet(in)
+	want := []Block{mustBlock("1.2.3.4"), mustBlock("10.0.0.0/8"), mustBlock("::1")}
+	if got := s.Blocks(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Blocks(), got %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(in, orig) {
+		t.Errorf("NewBlockSet modified the input")
+	}
+
+	// Blocks returns a copy
+	s.Blocks()[0] = Block{}
+	if s.Blocks()[0] != want[0] {
+		t.Errorf("Blocks() isn't a copy")
+	}
+
+	if !s.Contains(mustIP("10.1.2.3")) || s.Contains(mustIP("11.0.0.0")) || s.Contains(IP{}) {
+		t.Errorf("Contains, unexpected result")
+	}
+	if !s.Covers(mustBlock("10.1.0.0/16")) || s.Covers(mustBlock("10.0.0.0/7")) {
+		t.Errorf("Covers, unexpected result")
+	}
+
+	var zero BlockSet
+	if zero.Len() != 0 || zero.Contains(mustIP("10.0.0.1")) || zero.Blocks() != nil {
+		t.Errorf("zero BlockSet isn't empty")
+	}
+}
+
+func TestWellKnownBlocks(t *testing.T) {
+	tests := []struct {
+		set  func() BlockSet
+		in   string
+		want bool
+	}{
+		{PrivateBlocks, "172.16.1.1", true},
+		{PrivateBlocks, "fd00::1", true},
+		{PrivateBlocks, "100.64.0.1", false},
+		{CGNATBlocks, "100.127.255.255", true},
+		{CGNATBlocks, "100.128.0.0", false},
+		{LoopbackBlocks, "127.0.0.1", true},
+		{LinkLocalBlocks, "fe80::1", true},
+		{MulticastBlocks, "ff02::1", true},
+		{DocumentationBlocks, "3fff::1", true},
+		{SpecialBlocks, "100.64.0.1", true},
+		{SpecialBlocks, "8.8.8.8", false},
+	}
+	for _, tt := range tests {
+		if got := tt.set().Contains(mustIP(tt.in)); got != tt.want {
+			t.Errorf("Contains(%s), got %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	if n := SpecialBlocks().Len(); n != len(specials) {
+		t.Errorf("SpecialBlocks().Len(), got %d, want %d", n, len(specials))
+	}
+
+	// customer space minus special space
+	rest := mustBlock("10.0.0.0/7").Diff(PrivateBlocks().Blocks())
+	if want := []Block{mustBlock("11.0.0.0/8")}; !reflect.DeepEqual(rest, want) {
+		t.Errorf("Diff(PrivateBlocks), got %v, want %v", rest, want)
+	}
+}
+
+func TestWellKnownBlocksConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !PrivateBlocks().Contains(mustIP("10.0.0.1")) {
+				t.Errorf("PrivateBlocks() misses 10.0.0.1")
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,147 @@
+package inet
+
+import (
+	"errors"
+	"math"
+)
+
+// Bloom is a probabilistic pre-filter for large sets of blocks.
+//
+// MayContain returns false if the IP is definitely not covered by any block of the set,
+// true if it's probably covered. The false positive rate is configured with NewBloom.
+//
+// The blocks are decomposed into CIDRs, the filter holds one key per CIDR.
+// A query probes every prefix length present in the set, at most 33 for IPv4 and 129 for IPv6.
+type Bloom struct {
+	// the bit array, m bits
+	bits []uint64
+	m    uint64
+
+	// number of hash functions
+	k uint64
+
+	// the distinct internal prefix lengths of the CIDRs, per IP version
+	lens4 []uint8
+	lens6 []uint8
+}
+
+// NewBloom returns the filter for bs with the false positive rate fpRate.
+// Returns an error if fpRate isn't in the open interval (0, 1).
+func NewBloom(bs []Block, fpRate float64) (*Bloom, error) {
+	if !(fpRate > 0 && fpRate < 1) {
+		return nil, errors.New("bloom: false positive rate must be in (0, 1)")
+	}
+
+	var cidrs []Block
+	for _, b := range bs {
+		if !b.IsValid() {
+			continue
+		}
+		cidrs = append(cidrs, b.CIDRs()...)
+	}
+
+	// collect the distinct prefix lengths first, a query probes all of them
+	var seen4, seen6 [129]bool
+	for _, c := range cidrs {
+		if c.base.version == v4 {
+			seen4[c.base.commonPrefixLen(c.last)] = true
+		} else {
+			seen6[c.base.commonPrefixLen(c.last)] = true
+		}
+	}
+
+	bf := &Bloom{}
+	for l := range seen4 {
+		if seen4[l] {
+			bf.lens4 = append(bf.lens4, uint8(l))
+		}
+		if seen6[l] {
+			bf.lens6 = append(bf.lens6, uint8(l))
+		}
+	}
+
+	// split the false positive rate between the probes
+	probes := len(bf.lens4)
+	if len(bf.lens6) > probes {
+		probes = len(bf.lens6)
+	}
+	if probes > 1 {
+		fpRate /= float64(probes)
+	}
+
+	// optimal size and number of hash functions
+	n := float64(len(cidrs))
+	if n < 1 {
+		n = 1
+	}
+	m := uint64(math.Ceil(-n * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := uint64(math.Round(float64(m) / n * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+
+	bf.bits = make([]uint64, (m+63)/64)
+	bf.m = m
+	bf.k = k
+
+	for _, c := range cidrs {
+		bf.add(c.base.version, c.base.uint128, c.base.commonPrefixLen(c.last))
+	}
+
+	return bf, nil
+}
+
+// MayContain reports whether ip is probably covered by the blocks of the filter.
+// A false result is always correct.
+func (bf *Bloom) MayContain(ip IP) bool {
+	if bf == nil {
+		return false
+	}
+
+	lens := bf.lens6
+	switch ip.version {
+	case v4:
+		lens = bf.lens4
+	case v6:
+	default:
+		return false
+	}
+
+	for _, l := range lens {
+		if bf.test(ip.version, ip.and(maskUint128[l]), l) {
+			return true
+		}
+	}
+	return false
+}
+
+// add the prefix key to the filter
+func (bf *Bloom) add(version uint8, u uint128, l uint8) {
+	h1, h2 := bloomHash(version, u, l)
+	for i := uint64(0); i < bf.k; i++ {
+		pos := (h1 + i*h2) % bf.m
+		bf.bits[pos>>6] |= 1 << (pos & 63)
+	}
+}
+
+// test the prefix key
+func (bf *Bloom) test(version uint8, u uint128, l uint8) bool {
+	h1, h2 := bloomHash(version, u, l)
+	for i := uint64(0); i < bf.k; i++ {
+		pos := (h1 + i*h2) % bf.m
+		if bf.bits[pos>>6]&(1<<(pos&63)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomHash, two independent hashes for double hashing, splitmix64 finalizer
+func bloomHash(version uint8, u uint128, l uint8) (h1, h2 uint64) {
+	h1 = mix64(mix64(u.hi^uint64(l)<<8^uint64(version)) ^ u.lo)
+	h2 = mix64(h1^golden) | 1
+	return
+}
@@ -0,0 +1,71 @@
+package inet
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBloom(t *testing.T) {
+	prng := rand.New(rand.NewSource(1))
+
+	// random IPv4 /24 networks
+	var bs []Block
+	for i := 0; i < 10_000; i++ {
+		base := IP{v4, uint128{0, uint64(prng.Uint32() &^ 0xff)}}
+		bs = append(bs, Block{base, base.mkLastIP(maskUint128[96+24])})
+	}
+	// and a range, decomposed into CIDRs of different lengths
+	bs = append(bs, mustBlock("10.0.0.3-10.0.17.134"))
+
+	fpRate := 0.01
+	bf, err := NewBloom(bs, fpRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no false negatives
+	for _, b := range bs {
+		for _, ip := range []IP{b.base, b.last} {
+			if !bf.MayContain(ip) {
+				t.Fatalf("MayContain(%v) = false, want true", ip)
+			}
+		}
+	}
+
+	// false positives, random IPs mostly miss
+	fps, n := 0, 100_000
+	for i := 0; i < n; i++ {
+		ip := IP{v4, uint128{0, uint64(prng.Uint32())}}
+		if bf.MayContain(ip) && !covered(bs, ip) {
+			fps++
+		}
+	}
+	if rate := float64(fps) / float64(n); rate > 2*fpRate {
+		t.Errorf("false positive rate %.4f, want about %.4f", rate, fpRate)
+	}
+
+	// other version
+	if ip := mustIP("2001:db8::1"); bf.MayContain(ip) {
+		t.Errorf("MayContain(%v) = true, want false", ip)
+	}
+	if bf.MayContain(IP{}) {
+		t.Errorf("MayContain(invalid IP) = true, want false")
+	}
+}
+
+func TestBloomFault(t *testing.T) {
+	for _, fpRate := range []float64{0, 1, -0.5, 2} {
+		if _, err := NewBloom(nil, fpRate); err == nil {
+			t.Errorf("NewBloom(nil, %v), want error", fpRate)
+		}
+	}
+}
+
+func covered(bs []Block, ip IP) bool {
+	for _, b := range bs {
+		if !ip.Less(b.base) && !b.last.Less(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package inet
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// DelegationFor returns the delegated CIDR number index with prefix length length out of the CIDR parent,
+// e.g. the 5th customer /48 out of the ISP /32, counting from 0.
+// Same as the index-th CIDR of parent.SplitCIDR(length - parent.Bits()), without allocating them all.
+//
+// Returns error if parent is no CIDR, the length is out of range or index is beyond the number of delegations.
+func DelegationFor(parent Block, index int, length int) (Block, error) {
+	pl := parent.Bits()
+	if pl < 0 {
+		return Block{}, fmt.Errorf("%v: not a CIDR: %v", invalidBlock, parent)
+	}
+
+	max := 128
+	if parent.base.version == v4 {
+		max = 32
+	}
+	if length < pl || length > max {
+		return Block{}, fmt.Errorf("%v: delegation length /%d out of range for %v", invalidBlock, length, parent)
+	}
+	if index < 0 || bits.Len64(uint64(index)) > length-pl {
+		return Block{}, fmt.Errorf("%v: delegation index %d out of range for /%d in %v", invalidBlock, index, length, parent)
+	}
+
+	// prefix length in the 128 bit space
+	n := length + 128 - max
+
+	base := parent.base
+	if index != 0 {
+		base.uint128 = base.or(uint128{0, uint64(index)}.lsh(128 - n))
+	}
+	return Block{base, base.mkLastIP(maskUint128[n])}, nil
+}
+
+// SixRD returns the 6rd delegated prefix for the IPv4 address ip, RFC 5969.
+// The 6rd prefix is the IPv6 CIDR of the ISP, v4MaskLen the number of high-order
+// bits common to all IPv4 addresses of the 6rd domain, they are not embedded.
+//
+// The delegated prefix is the 6rd prefix followed by the remaining 32-v4MaskLen bits of ip,
+// the prefix length is prefix.Bits() + 32 - v4MaskLen.
+func SixRD(prefix Block, v4MaskLen int, ip IP) (Block, error) {
+	if !prefix.Is6() || !prefix.IsCIDR() {
+		return Block{}, fmt.Errorf("%v: 6rd prefix is no IPv6 CIDR: %v", invalidBlock, prefix)
+	}
+	if !ip.Is4() {
+		return Block{}, fmt.Errorf("%v: no IPv4 address: %v", invalidIP, ip)
+	}
+	if v4MaskLen < 0 || v4MaskLen > 32 {
+		return Block{}, fmt.Errorf("%v: IPv4 mask length %d out of range", invalidBlock, v4MaskLen)
+	}
+
+	pl := prefix.Bits()
+	k := 32 - v4MaskLen
+	if pl+k > 128 {
+		return Block{}, fmt.Errorf("%v: 6rd prefix %v with %d IPv4 bits exceeds /128", invalidBlock, prefix, k)
+	}
+
+	base := prefix.base
+	if k > 0 {
+		embed := uint128{0, ip.lo & (1<<uint(k) - 1)}
+		base.uint128 = base.or(embed.lsh(128 - pl - k))
+	}
+	return Block{base, base.mkLastIP(maskUint128[pl+k])}, nil
+}
@@ -0,0 +1,97 @@
+package inet
+
+import "testing"
+
+func TestDelegationFor(t *testing.T) {
+	tests := []struct {
+		parent string
+		index  int
+		length int
+		want   string
+	}{
+		{"2001:db8::/32", 0, 48, "2001:db8::/48"},
+		{"2001:db8::/32", 5, 48, "2001:db8:5::/48"},
+		{"2001:db8::/32", 65535, 48, "2001:db8:ffff::/48"},
+		{"2001:db8::/32", 0, 32, "2001:db8::/32"},
+		{"2001:db8::/32", 257, 56, "2001:db8:1:100::/56"},
+		{"::/0", 1, 1, "8000::/1"},
+		{"10.0.0.0/8", 3, 24, "10.0.3.0/24"},
+		{"10.0.0.0/8", 1, 32, "10.0.0.1/32"},
+	}
+
+	for _, tt := range tests {
+		got, err := DelegationFor(mustBlock(tt.parent), tt.index, tt.length)
+		if err != nil || got != mustBlock(tt.want) {
+			t.Errorf("DelegationFor(%s, %d, %d), got %v, %v, want %s", tt.parent, tt.index, tt.length, got, err, tt.want)
+		}
+
+		// same as SplitCIDR
+		if tt.length-mustBlock(tt.parent).Bits() > 16 {
+			continue
+		}
+		split, _ := mustBlock(tt.parent).SplitCIDR(tt.length - mustBlock(tt.parent).Bits())
+		if split[tt.index] != got {
+			t.Errorf("DelegationFor(%s, %d, %d), got %v, SplitCIDR %v", tt.parent, tt.index, tt.length, got, split[tt.index])
+		}
+	}
+
+	errs := []struct {
+		parent Block
+		index  int
+		length int
+	}{
+		{Block{}, 0, 48},
+		{mustBlock("10.0.0.1-10.0.0.2"), 0, 32},
+		{mustBlock("2001:db8::/32"), 0, 31},
+		{mustBlock("2001:db8::/32"), 0, 129},
+		{mustBlock("2001:db8::/32"), -1, 48},
+		{mustBlock("2001:db8::/32"), 65536, 48},
+		{mustBlock("2001:db8::/32"), 1, 32},
+		{mustBlock("10.0.0.0/8"), 0, 33},
+	}
+	for _, tt := range errs {
+		if _, err := DelegationFor(tt.parent, tt.index, tt.length); err == nil {
+			t.Errorf("DelegationFor(%v, %d, %d), want error", tt.parent, tt.index, tt.length)
+		}
+	}
+}
+
+func TestSixRD(t *testing.T) {
+	tests := []struct {
+		prefix    string
+		v4MaskLen int
+		ip        string
+		want      string
+	}{
+		{"2001:db8::/32", 0, "192.0.2.1", "2001:db8:c000:201::/64"},
+		{"2001:db8::/32", 8, "192.0.2.1", "2001:db8:2:100::/56"},
+		{"2001:db8::/32", 32, "192.0.2.1", "2001:db8::/32"},
+		{"2001:db8:ff00::/40", 16, "198.51.100.7", "2001:db8:ff64:700::/56"},
+		{"2001:db8::/96", 0, "255.255.255.255", "2001:db8::ffff:ffff/128"},
+	}
+
+	for _, tt := range tests {
+		got, err := SixRD(mustBlock(tt.prefix), tt.v4MaskLen, mustIP(tt.ip))
+		if err != nil || got != mustBlock(tt.want) {
+			t.Errorf("SixRD(%s, %d, %s), got %v, %v, want %s", tt.prefix, tt.v4MaskLen, tt.ip, got, err, tt.want)
+		}
+	}
+
+	errs := []struct {
+		prefix    Block
+		v4MaskLen int
+		ip        IP
+	}{
+		{mustBlock("10.0.0.0/8"), 0, mustIP("192.0.2.1")},
+		{mustBlock("2001:db8::1-2001:db8::2"), 0, mustIP("192.0.2.1")},
+		{mustBlock("2001:db8::/32"), 0, mustIP("::1")},
+		{mustBlock("2001:db8::/32"), 33, mustIP("192.0.2.1")},
+		{mustBlock("2001:db8::/32"), -1, mustIP("192.0.2.1")},
+		{mustBlock("2001:db8::/97"), 0, mustIP("192.0.2.1")},
+	}
+	for _, tt := range errs {
+		if _, err := SixRD(tt.prefix, tt.v4MaskLen, tt.ip); err == nil {
+			t.Errorf("SixRD(%v, %d, %v), want error", tt.prefix, tt.v4MaskLen, tt.ip)
+		}
+	}
+}
@@ -19,6 +19,18 @@ Blocks are IP-networks or arbitrary IP-ranges, e.g.
  10.0.0.3-10.0.17.134        // range
  2001:db8::1-2001:db8::f6    // range
 
+Migrating from v1
+
+All commands and examples in this module use the v2 API, the v1 names map to:
+
+ NewBlock         ParseBlock
+ NewIP            ParseIP
+ SortBlock        SortBlocks
+ BlockToCIDRList  Block.CIDRs, Block.CIDRsN
+ SplitCIDR        Block.SplitCIDR
+ MaxCIDRSplit     MaxCIDRSplit
+ tree.Item        inettree.Item
+ BlockTree        tree.Tree with inettree.Item, see package inettree
 
 */
 package inet
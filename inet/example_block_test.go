@@ -115,3 +115,44 @@ func ExampleBlock_Diff_v6() {
 	// 2001:db8:de00::/40 - 2001:db8:dea0::/44
 	// diff: [2001:db8:de00::-2001:db8:de9f:ffff:ffff:ffff:ffff:ffff 2001:db8:deb0::-2001:db8:deff:ffff:ffff:ffff:ffff:ffff]
 }
+
+func ExampleGaps() {
+	outer := mustParseBlock("192.168.2.0/24")
+	inner := []inet.Block{
+		mustParseBlock("192.168.2.0/26"),
+		mustParseBlock("192.168.2.128/27"),
+		mustParseBlock("192.168.2.240-192.168.2.249"),
+	}
+
+	fmt.Printf("gaps: %v\n", inet.Gaps(outer, inner))
+
+	// Output:
+	// gaps: [192.168.2.64/26 192.168.2.160-192.168.2.239 192.168.2.250-192.168.2.255]
+}
+
+func ExampleCoveringCIDR() {
+	b := mustParseBlock("10.0.0.6-10.0.0.99")
+	fmt.Println(b.CoveringCIDR())
+
+	c, _ := inet.CoveringCIDR([]inet.Block{
+		mustParseBlock("2001:db8:1::/48"),
+		mustParseBlock("2001:db8:f::/48"),
+	})
+	fmt.Println(c)
+
+	// Output:
+	// 10.0.0.0/25
+	// 2001:db8::/44
+}
+
+func ExampleBlock_UsableHosts() {
+	for _, s := range []string{"192.168.0.0/24", "192.168.0.0/31", "2001:db8::/120"} {
+		b := mustParseBlock(s)
+		fmt.Printf("%-16s hosts: %4v, first: %v, last: %v\n", b, b.UsableHosts(), b.FirstHost(), b.LastHost())
+	}
+
+	// Output:
+	// 192.168.0.0/24   hosts:  254, first: 192.168.0.1, last: 192.168.0.254
+	// 192.168.0.0/31   hosts:    2, first: 192.168.0.0, last: 192.168.0.1
+	// 2001:db8::/120   hosts:  256, first: 2001:db8::, last: 2001:db8::ff
+}
@@ -79,3 +79,32 @@ func ExampleIP_Less() {
 	// fe80::1
 
 }
+
+func ExampleIP_Prefix() {
+	for _, s := range []string{"192.168.17.42", "2001:db8:dead:beef::1"} {
+		ip, _ := inet.ParseIP(s)
+
+		bits := 24
+		if ip.Is6() {
+			bits = 64
+		}
+		b, _ := ip.Prefix(bits)
+		fmt.Println(b)
+	}
+
+	// Output:
+	// 192.168.17.0/24
+	// 2001:db8:dead:beef::/64
+}
+
+func ExampleExpandTargets() {
+	ips, err := inet.ExpandTargets("10.0.0.1-3, 10.0.1.0/31", 256)
+	fmt.Println(ips, err)
+
+	_, err = inet.ExpandTargets("10.0.0.0/16", 256)
+	fmt.Println(err)
+
+	// Output:
+	// [10.0.0.1 10.0.0.2 10.0.0.3 10.0.1.0 10.0.1.1] <nil>
+	// limit exceeded: targets "10.0.0.0/16" exceed 256 addresses
+}
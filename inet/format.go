@@ -0,0 +1,86 @@
+package inet
+
+import "strconv"
+
+// AppendTo appends the string form of ip to dst and returns the extended buffer,
+// same text as String but without allocations for hot render paths.
+func (ip IP) AppendTo(dst []byte) []byte {
+	switch {
+	case !ip.IsValid():
+		return append(dst, invalidIP...)
+	case ip.version == v4:
+		return appendDotted(dst, uint32(ip.lo))
+	case ip.hi == 0 && ip.lo>>32 == 0xffff:
+		// IPv4-mapped, printed as IPv4 just like net.IP
+		return appendDotted(dst, uint32(ip.lo))
+	}
+	return appendHextets(dst, ip.uint128)
+}
+
+// AppendTo appends the string form of b to dst and returns the extended buffer,
+// same text as String but without allocations for hot render paths.
+func (b Block) AppendTo(dst []byte) []byte {
+	if !b.IsValid() {
+		return append(dst, invalidBlock...)
+	}
+
+	dst = b.base.AppendTo(dst)
+	if !b.IsCIDR() {
+		dst = append(dst, '-')
+		return b.last.AppendTo(dst)
+	}
+	dst = append(dst, '/')
+	return strconv.AppendInt(dst, int64(b.Bits()), 10)
+}
+
+// appendDotted appends the dotted decimal form of the IPv4 address u
+func appendDotted(dst []byte, u uint32) []byte {
+	for i := 3; i >= 0; i-- {
+		dst = strconv.AppendUint(dst, uint64(u>>(8*uint(i))&0xff), 10)
+		if i > 0 {
+			dst = append(dst, '.')
+		}
+	}
+	return dst
+}
+
+// appendHextets appends the IPv6 address u in the canonical form of RFC 5952,
+// the longest run of at least two zero hextets is compressed, the first one wins on a tie.
+func appendHextets(dst []byte, u uint128) []byte {
+	var hs [8]uint16
+	for i := 0; i < 4; i++ {
+		hs[i] = uint16(u.hi >> (48 - 16*uint(i)))
+		hs[i+4] = uint16(u.lo >> (48 - 16*uint(i)))
+	}
+
+	// find the longest run of zeros, [e0, e1)
+	e0, e1 := -1, -1
+	for i := 0; i < 8; i++ {
+		j := i
+		for j < 8 && hs[j] == 0 {
+			j++
+		}
+		if j-i > e1-e0 {
+			e0, e1 = i, j
+		}
+		if j > i {
+			i = j
+		}
+	}
+	if e1-e0 < 2 {
+		e0, e1 = -1, -1
+	}
+
+	for i := 0; i < 8; i++ {
+		if i == e0 {
+			dst = append(dst, ':', ':')
+			if i = e1; i >= 8 {
+				break
+			}
+		} else if i > 0 {
+			dst = append(dst, ':')
+		}
+		dst = strconv.AppendUint(dst, uint64(hs[i]), 16)
+	}
+	return dst
+}
@@ -0,0 +1,80 @@
+package inet
+
+import (
+	"math/rand"
+	"net"
+	"testing"
+)
+
+func TestAppendToMatchesStdlib(t *testing.T) {
+	prng := rand.New(rand.NewSource(1))
+
+	// random hextets, mostly zero to get runs of zeros
+	hextets := func() uint64 {
+		var u uint64
+		for i := 0; i < 4; i++ {
+			u <<= 16
+			if prng.Intn(2) == 0 {
+				u |= uint64(prng.Intn(1 << 16))
+			}
+		}
+		return u
+	}
+
+	ips := []IP{
+		mustIP("0.0.0.0"),
+		mustIP("255.255.255.255"),
+		mustIP("::"),
+		mustIP("::1"),
+		mustIP("1::"),
+		mustIP("2001:db8:0:1:0:0:0:1"),
+		mustIP("2001:0:0:1:0:0:1:1"),
+		mustIP("2001:db8:0:1:1:1:1:1"),
+		UnsafeIPFromParts(v6, 0, 0xffff_0102_0304),
+	}
+	for i := 0; i < 10000; i++ {
+		ips = append(ips, UnsafeIPFromParts(v6, hextets(), hextets()))
+		ips = append(ips, UnsafeIPFromParts(v4, 0, uint64(prng.Uint32())))
+	}
+
+	for _, ip := range ips {
+		want := net.IP(ip.toBytes()).String()
+		if got := string(ip.AppendTo(nil)); got != want {
+			t.Fatalf("AppendTo(%#v), got %s, want %s", ip, got, want)
+		}
+		if got := ip.String(); got != want {
+			t.Fatalf("String(%#v), got %s, want %s", ip, got, want)
+		}
+	}
+
+	if got := string(IP{}.AppendTo([]byte("x"))); got != "x"+invalidIP {
+		t.Errorf("IP{}.AppendTo, got %s", got)
+	}
+	if got := string(Block{}.AppendTo([]byte("x"))); got != "x"+invalidBlock {
+		t.Errorf("Block{}.AppendTo, got %s", got)
+	}
+	if got := string(mustBlock("10.0.0.3-10.0.0.17").AppendTo([]byte("> "))); got != "> 10.0.0.3-10.0.0.17" {
+		t.Errorf("Block.AppendTo, got %s", got)
+	}
+}
+
+func BenchmarkBlockString(b *testing.B) {
+	for _, s := range []string{"10.0.0.0/8", "10.0.0.3-10.0.17.134", "2001:db8::/32", "2001:db8::1-2001:db8::ff:ffff"} {
+		blk := mustBlock(s)
+
+		b.Run("String/"+s, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = blk.String()
+			}
+		})
+
+		buf := make([]byte, 0, 128)
+		b.Run("AppendTo/"+s, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				buf = blk.AppendTo(buf[:0])
+			}
+		})
+	}
+}
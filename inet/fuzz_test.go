@@ -0,0 +1,118 @@
+//go:build go1.18
+// +build go1.18
+
+package inet_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/inet/inettest"
+)
+
+// blocksFromBytes, valid IPv4 and IPv6 blocks from fuzz data, 5 bytes per block:
+// the version bit and two 16 bit offsets, the base and the last of the block.
+// The offsets are small, the blocks overlap and touch each other often.
+func blocksFromBytes(data []byte) []inet.Block {
+	var bs []inet.Block
+	for ; len(data) >= 5; data = data[5:] {
+		a := uint16(data[1])<<8 | uint16(data[2])
+		b := uint16(data[3])<<8 | uint16(data[4])
+		if a > b {
+			a, b = b, a
+		}
+
+		base, last := mkIP(data[0]&1 == 1, a), mkIP(data[0]&1 == 1, b)
+		blk, err := inet.BlockFromRange(base, last)
+		if err != nil {
+			panic(err)
+		}
+		bs = append(bs, blk)
+	}
+	return bs
+}
+
+// mkIP, 10.0.x.y or 2001:db8::x:y
+func mkIP(is6 bool, u uint16) inet.IP {
+	std := net.IPv4(10, 0, byte(u>>8), byte(u))
+	if is6 {
+		std = net.ParseIP("2001:db8::")
+		std[14], std[15] = byte(u>>8), byte(u)
+	}
+	ip, err := inet.FromStdIP(std)
+	if err != nil {
+		panic(err)
+	}
+	return ip
+}
+
+func FuzzParseIP(f *testing.F) {
+	for _, s := range []string{"0.0.0.0", "10.0.0.1", "255.255.255.255", "::", "2001:db8::1", "::ffff:10.0.0.1", "fe80::1%eth0", "1.2.3"} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		ip, err := inet.ParseIP(s)
+		if err != nil {
+			return
+		}
+		ip2, err := inet.ParseIP(ip.String())
+		if err != nil || ip2 != ip {
+			t.Fatalf("ParseIP(%q) = %v, round trip %v, %v", s, ip, ip2, err)
+		}
+
+		var ip3 inet.IP
+		text, _ := ip.MarshalText()
+		if err := ip3.UnmarshalText(text); err != nil || ip3 != ip {
+			t.Fatalf("ParseIP(%q) = %v, text round trip %v, %v", s, ip, ip3, err)
+		}
+	})
+}
+
+func FuzzParseBlock(f *testing.F) {
+	for _, s := range []string{"10.0.0.0/8", "10.0.0.3-10.0.0.17", "10.0.0.17-10.0.0.3", "0.0.0.0/0", "::/0", "2001:db8::/33", "2001:db8::1-2001:db8::ff", "1.2.3.4/33"} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		b, err := inet.ParseBlock(s)
+		if err != nil {
+			return
+		}
+		if err := inettest.CheckRoundTrip(b); err != nil {
+			t.Fatalf("ParseBlock(%q): %v", s, err)
+		}
+
+		// any range is split in at most 2*128 CIDRs, cheap
+		if err := inettest.CheckCIDRs(b); err != nil {
+			t.Fatalf("ParseBlock(%q): %v", s, err)
+		}
+	})
+}
+
+func FuzzMerge(f *testing.F) {
+	f.Add([]byte{0, 0, 0, 0, 255, 0, 0, 100, 1, 0})
+	f.Add([]byte{1, 0, 0, 255, 255, 0, 255, 255, 255, 255, 1, 1, 1, 1, 1})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := inettest.CheckMerge(blocksFromBytes(data)); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func FuzzDiff(f *testing.F) {
+	f.Add([]byte{0, 0, 0, 255, 255, 0, 0, 10, 0, 20, 0, 0, 15, 0, 30})
+	f.Add([]byte{1, 0, 0, 255, 255, 0, 0, 0, 0, 0, 1, 255, 255, 255, 255})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		bs := blocksFromBytes(data)
+		if len(bs) == 0 {
+			return
+		}
+		if err := inettest.CheckDiff(bs[0], bs[1:]); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
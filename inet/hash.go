@@ -0,0 +1,51 @@
+package inet
+
+// golden ratio, odd, for seed mixing
+const golden = 0x9e3779b97f4a7c15
+
+// Hash64 returns a 64 bit hash of ip for the given seed, e.g. for custom hash tables,
+// consistent hashing or sharding by address, without serializing to bytes first.
+//
+// The internal uint128 and the version are mixed with the splitmix64 finalizer,
+// the hash values are stable across releases and platforms.
+func (ip IP) Hash64(seed uint64) uint64 {
+	h := mix64(seed ^ golden*uint64(ip.version))
+	h = mix64(h ^ ip.hi)
+	return mix64(h + golden ^ ip.lo)
+}
+
+// Hash64 returns a 64 bit hash of b for the given seed, see IP.Hash64.
+func (b Block) Hash64(seed uint64) uint64 {
+	return mix64(b.base.Hash64(seed) ^ b.last.Hash64(seed+golden))
+}
+
+// Shard maps ip to one of n buckets in [0, n), stable and well distributed,
+// e.g. for partitioning flow processing across workers by source IP.
+// Returns -1 if n < 1 or ip is invalid.
+//
+// The algorithm is the jump consistent hash by Lamping and Veach, applied to ip.Hash64(0).
+// When n grows to n+1, only about 1/(n+1) of the addresses move to another bucket.
+func Shard(ip IP, n int) int {
+	if n < 1 || !ip.IsValid() {
+		return -1
+	}
+
+	key := ip.Hash64(0)
+	b, j := int64(-1), int64(0)
+	for j < int64(n) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
+
+// mix64, splitmix64 finalizer
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
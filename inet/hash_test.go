@@ -0,0 +1,96 @@
+package inet
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestHash64(t *testing.T) {
+	// stable across releases
+	if got, want := mustIP("10.0.0.1").Hash64(0), mustIP("10.0.0.1").Hash64(0); got != want {
+		t.Errorf("Hash64 not deterministic")
+	}
+	if mustIP("10.0.0.1").Hash64(0) == mustIP("10.0.0.1").Hash64(1) {
+		t.Errorf("Hash64, seed ignored")
+	}
+	if mustIP("0.0.0.1").Hash64(0) == mustIP("::1").Hash64(0) {
+		t.Errorf("Hash64, version ignored")
+	}
+	if mustBlock("10.0.0.0/8").Hash64(0) == mustBlock("10.0.0.0/9").Hash64(0) {
+		t.Errorf("Block.Hash64, last ignored")
+	}
+
+	// distribution of consecutive addresses, low bits
+	const buckets = 64
+	var counts [buckets]int
+	n := 64_000
+	ip := mustIP("10.0.0.0")
+	for i := 0; i < n; i++ {
+		counts[ip.Hash64(42)%buckets]++
+		ip = ip.addOne()
+	}
+	for i, c := range counts {
+		if c < n/buckets*8/10 || c > n/buckets*12/10 {
+			t.Errorf("bucket %d, count %d, want about %d", i, c, n/buckets)
+		}
+	}
+
+	// no collisions in a random sample
+	prng := rand.New(rand.NewSource(1))
+	ips := make(map[IP]bool)
+	hashes := make(map[uint64]bool)
+	for i := 0; i < 100_000; i++ {
+		ip := randIP(prng)
+		ips[ip] = true
+		hashes[ip.Hash64(0)] = true
+	}
+	if len(hashes) != len(ips) {
+		t.Errorf("collisions, %d distinct hashes for %d distinct IPs", len(hashes), len(ips))
+	}
+}
+
+func TestShard(t *testing.T) {
+	prng := rand.New(rand.NewSource(1))
+
+	ips := make([]IP, 50_000)
+	for i := range ips {
+		ips[i] = IP{v4, uint128{0, uint64(prng.Uint32())}}
+	}
+
+	const n = 10
+	var counts [n]int
+	for _, ip := range ips {
+		s := Shard(ip, n)
+		if s < 0 || s >= n {
+			t.Fatalf("Shard(%v, %d) = %d, out of range", ip, n, s)
+		}
+		counts[s]++
+	}
+	for i, c := range counts {
+		if c < len(ips)/n*9/10 || c > len(ips)/n*11/10 {
+			t.Errorf("bucket %d, count %d, want about %d", i, c, len(ips)/n)
+		}
+	}
+
+	// consistent, n -> n+1 moves about 1/(n+1) to the new bucket only
+	moved := 0
+	for _, ip := range ips {
+		a, b := Shard(ip, n), Shard(ip, n+1)
+		if a != b {
+			if b != n {
+				t.Fatalf("Shard(%v), moved from %d to %d, not to the new bucket", ip, a, b)
+			}
+			moved++
+		}
+	}
+	if want := len(ips) / (n + 1); moved < want*8/10 || moved > want*12/10 {
+		t.Errorf("moved %d, want about %d", moved, want)
+	}
+
+	if Shard(IP{}, n) != -1 || Shard(ips[0], 0) != -1 {
+		t.Errorf("Shard, invalid input, want -1")
+	}
+	if Shard(ips[0], 1) != 0 {
+		t.Errorf("Shard(n=1), want 0")
+	}
+}
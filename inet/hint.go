@@ -0,0 +1,114 @@
+package inet
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// NibbleAligned reports whether b is a CIDR with a prefix length on a 4 bit boundary,
+// the delegation boundary of IPv6 reverse DNS zones.
+func (b Block) NibbleAligned() bool {
+	n := b.Bits()
+	return n >= 0 && n%4 == 0
+}
+
+// HextetAligned reports whether b is a CIDR with a prefix length on a 16 bit boundary,
+// the groups of the IPv6 text form.
+func (b Block) HextetAligned() bool {
+	n := b.Bits()
+	return n >= 0 && n%16 == 0
+}
+
+// HintString returns the CIDR b with a hint on the alignment of the prefix length,
+// for DNS and documentation tooling. It returns one of 3 forms:
+//
+//   "2001:0db8:0a<</40"                                 if b is nibble aligned, IPv4 octet aligned,
+//                                                       the significant part of the expanded base
+//   "2001:0db8:0000:0000:0000:0000:0000:0000~~/33"      else, the expanded base
+//   "10.0.0.3-10.0.0.17"                                if b is no CIDR, as String
+func (b Block) HintString() string {
+	if !b.IsCIDR() {
+		return b.String()
+	}
+
+	bits := b.Bits()
+	exp := b.base.Expand()
+
+	// length of the significant part of the expanded base
+	var n int
+	switch {
+	case b.Is4() && bits%8 == 0:
+		// 3 digits and a dot per octet
+		if k := bits / 8; k > 0 {
+			n = 4*k - 1
+		}
+	case b.Is6() && b.NibbleAligned():
+		// a colon after every 4 nibbles
+		if k := bits / 4; k > 0 {
+			n = k + (k-1)/4
+		}
+	default:
+		return exp + "~~/" + strconv.Itoa(bits)
+	}
+
+	return exp[:n] + "<</" + strconv.Itoa(bits)
+}
+
+// AlignedTo reports whether b is a CIDR with a prefix length multiple of bits,
+// e.g. 4 for nibble or 8 for octet boundaries. Returns false for bits < 1.
+func (b Block) AlignedTo(bits int) bool {
+	n := b.Bits()
+	return bits > 0 && n >= 0 && n%bits == 0
+}
+
+// SplitToAligned cuts b into CIDRs with prefix lengths multiple of bits, in ascending order,
+// e.g. for reverse DNS zones or TCAM constraints. b may be any range.
+// The prefix lengths are rounded up to the next multiple of bits, capped at /32 or /128.
+// Returns error for invalid blocks and bits < 1.
+//
+// Beware, a CIDR is split in up to 2^(bits-1) CIDRs, returns an error wrapping ErrLimit beyond MaxCIDRSplit.
+func (b Block) SplitToAligned(bits int) ([]Block, error) {
+	if !b.IsValid() || bits < 1 {
+		return nil, fmt.Errorf("%v: can't align %v to %d bits", invalidBlock, b, bits)
+	}
+
+	max := 128
+	if b.Is4() {
+		max = 32
+	}
+
+	// the split bits per CIDR
+	cidrs := b.CIDRs()
+	splits := make([]int, len(cidrs))
+
+	total := 0
+	for i, c := range cidrs {
+		n := c.Bits()
+
+		// round up, capped
+		m := (n + bits - 1) / bits * bits
+		if m > max {
+			m = max
+		}
+		splits[i] = m - n
+
+		// -1 signals the overflow, even for 32 bit ints
+		if splits[i] > 30 {
+			return nil, checkSplit(b, -1)
+		}
+		total += 1 << splits[i]
+		if err := checkSplit(b, total); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]Block, 0, total)
+	for i, c := range cidrs {
+		parts, err := c.SplitCIDR(splits[i])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, parts...)
+	}
+	return out, nil
+}
@@ -0,0 +1,60 @@
+package inet
+
+import "math/bits"
+
+// Histogram counts blocks per IP version and per prefix length, see NewHistogram.
+type Histogram struct {
+	// number of blocks per IP version
+	V4, V6 int
+
+	// number of CIDRs, indexed by prefix length
+	CIDR4 [33]int
+	CIDR6 [129]int
+
+	// number of ranges, not CIDRs, indexed by the prefix length of the smallest CIDR size
+	// holding the range, e.g. ranges with 129..256 addresses are counted as /24 for IPv4.
+	Range4 [33]int
+	Range6 [129]int
+}
+
+// NewHistogram counts the blocks per IP version and prefix length, e.g. for capacity dashboards.
+// Ranges are counted in size buckets, see Histogram. Invalid blocks are skipped, bs is not modified.
+func NewHistogram(bs []Block) Histogram {
+	var h Histogram
+	for _, b := range bs {
+		h.Add(b)
+	}
+	return h
+}
+
+// Add counts the block b, invalid blocks are ignored.
+func (h *Histogram) Add(b Block) {
+	if !b.IsValid() {
+		return
+	}
+
+	// bit length of the size-1, the size bucket
+	d := b.last.sub(b.base.uint128)
+	n := bits.Len64(d.lo)
+	if d.hi != 0 {
+		n = 64 + bits.Len64(d.hi)
+	}
+
+	cidr := b.IsCIDR()
+	if b.Is4() {
+		h.V4++
+		if cidr {
+			h.CIDR4[32-n]++
+		} else {
+			h.Range4[32-n]++
+		}
+		return
+	}
+
+	h.V6++
+	if cidr {
+		h.CIDR6[128-n]++
+	} else {
+		h.Range6[128-n]++
+	}
+}
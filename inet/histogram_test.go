@@ -0,0 +1,39 @@
+package inet
+
+import "testing"
+
+func TestHistogram(t *testing.T) {
+	bs := []Block{
+		mustBlock("10.0.0.0/8"),
+		mustBlock("10.0.0.0/24"),
+		mustBlock("10.0.1.0/24"),
+		mustBlock("10.0.0.1"),
+		mustBlock("10.0.0.0-10.0.0.128"), // 129 addresses
+		mustBlock("10.0.0.1-10.0.0.4"),   // 4 addresses, misaligned
+		mustBlock("2001:db8::/32"),
+		mustBlock("::/0"),
+		mustBlock("::-8000::"),
+		{},
+	}
+
+	h := NewHistogram(bs)
+
+	if h.V4 != 6 || h.V6 != 3 {
+		t.Errorf("V4, V6, got %d, %d, want 6, 3", h.V4, h.V6)
+	}
+
+	var want Histogram
+	want.V4, want.V6 = 6, 3
+	want.CIDR4[8] = 1
+	want.CIDR4[24] = 2
+	want.CIDR4[32] = 1
+	want.Range4[24] = 1
+	want.Range4[30] = 1
+	want.CIDR6[32] = 1
+	want.CIDR6[0] = 1
+	want.Range6[0] = 1
+
+	if h != want {
+		t.Errorf("NewHistogram, got %+v, want %+v", h, want)
+	}
+}
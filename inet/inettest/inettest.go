@@ -0,0 +1,208 @@
+// Package inettest provides the property checks of the inet package for reuse downstream,
+// e.g. in tests and fuzz targets of packages built on inet.
+//
+// The checks return nil or an error describing the violated property.
+package inettest
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+)
+
+// CheckRoundTrip checks that the valid block b survives String and ParseBlock unchanged.
+func CheckRoundTrip(b inet.Block) error {
+	if !b.IsValid() {
+		return fmt.Errorf("round trip: invalid block")
+	}
+	if b.Last().Less(b.Base()) {
+		return fmt.Errorf("round trip: %v, base > last", b)
+	}
+
+	c, err := inet.ParseBlock(b.String())
+	if err != nil {
+		return fmt.Errorf("round trip: %v: %v", b, err)
+	}
+	if c != b {
+		return fmt.Errorf("round trip: %v parsed as %v", b, c)
+	}
+	return nil
+}
+
+// CheckCIDRs checks that the CIDRs of the valid block b are sorted and adjacent and span exactly b.
+func CheckCIDRs(b inet.Block) error {
+	cs := b.CIDRs()
+	if len(cs) == 0 {
+		return fmt.Errorf("CIDRs: %v, empty result", b)
+	}
+	if b.IsCIDR() && (len(cs) != 1 || cs[0] != b) {
+		return fmt.Errorf("CIDRs: %v, got %v, want the CIDR itself", b, cs)
+	}
+
+	if cs[0].Base() != b.Base() {
+		return fmt.Errorf("CIDRs: %v, first base %v", b, cs[0].Base())
+	}
+	if cs[len(cs)-1].Last() != b.Last() {
+		return fmt.Errorf("CIDRs: %v, last %v", b, cs[len(cs)-1].Last())
+	}
+
+	for i, c := range cs {
+		if !c.IsCIDR() {
+			return fmt.Errorf("CIDRs: %v, %v is no CIDR", b, c)
+		}
+		if i > 0 && !adjacent(cs[i-1], c) {
+			return fmt.Errorf("CIDRs: %v, %v and %v not adjacent", b, cs[i-1], c)
+		}
+	}
+	return nil
+}
+
+// CheckMerge checks that Merge of the valid blocks bs is sorted, disjunct and not adjacent,
+// idempotent and covers the same addresses as bs. bs is not modified.
+func CheckMerge(bs []inet.Block) error {
+	out := inet.Merge(clone(bs))
+
+	for i := 1; i < len(out); i++ {
+		if !out[i-1].Less(out[i]) {
+			return fmt.Errorf("Merge: %v before %v, not sorted", out[i-1], out[i])
+		}
+		if !out[i-1].IsDisjunct(out[i]) {
+			return fmt.Errorf("Merge: %v and %v not disjunct", out[i-1], out[i])
+		}
+		if adjacent(out[i-1], out[i]) {
+			return fmt.Errorf("Merge: %v and %v adjacent, not merged", out[i-1], out[i])
+		}
+	}
+
+	if again := inet.Merge(clone(out)); !equal(again, out) {
+		return fmt.Errorf("Merge: not idempotent, %v merged to %v", out, again)
+	}
+
+	// every input block is covered by an output block
+	for _, b := range bs {
+		if !coveredByAny(b, out) {
+			return fmt.Errorf("Merge: %v not covered by the result %v", b, out)
+		}
+	}
+
+	// and the result covers nothing else, computed without Merge
+	in4, in6 := coverage(bs)
+	out4, out6 := new(big.Int), new(big.Int)
+	for _, b := range out {
+		if b.Is4() {
+			out4.Add(out4, b.Size())
+		} else {
+			out6.Add(out6, b.Size())
+		}
+	}
+	if in4.Cmp(out4) != 0 || in6.Cmp(out6) != 0 {
+		return fmt.Errorf("Merge: size of %v differs from size of the input", out)
+	}
+	return nil
+}
+
+// coverage returns the number of IPv4 and IPv6 addresses covered by the valid blocks,
+// a naive sweep over the blocks sorted by base, independent of Merge.
+func coverage(bs []inet.Block) (v4, v6 *big.Int) {
+	sorted := clone(bs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Base().Less(sorted[j].Base()) })
+
+	v4, v6 = new(big.Int), new(big.Int)
+	add := func(base, last inet.IP) {
+		n := new(big.Int).Sub(toBig(last), toBig(base))
+		n.Add(n, big.NewInt(1))
+		if base.Is4() {
+			v4.Add(v4, n)
+		} else {
+			v6.Add(v6, n)
+		}
+	}
+
+	// the current disjunct range [base, last]
+	var base, last inet.IP
+	for i, b := range sorted {
+		if i > 0 && b.Is4() == base.Is4() && !last.Less(b.Base()) {
+			// overlaps the current range
+			if last.Less(b.Last()) {
+				last = b.Last()
+			}
+			continue
+		}
+		if i > 0 {
+			add(base, last)
+		}
+		base, last = b.Base(), b.Last()
+	}
+	if len(sorted) > 0 {
+		add(base, last)
+	}
+	return
+}
+
+// toBig returns the address as number
+func toBig(ip inet.IP) *big.Int {
+	_, hi, lo := ip.Raw()
+	z := new(big.Int).SetUint64(hi)
+	z.Lsh(z, 64)
+	return z.Or(z, new(big.Int).SetUint64(lo))
+}
+
+// CheckDiff checks that b.Diff(bs) is exactly the part of the valid block b not covered by the valid blocks bs.
+// bs is not modified.
+func CheckDiff(b inet.Block, bs []inet.Block) error {
+	diff := b.Diff(clone(bs))
+
+	for _, d := range diff {
+		if !(d == b || b.Covers(d)) {
+			return fmt.Errorf("Diff: %v not covered by %v", d, b)
+		}
+		for _, c := range bs {
+			if !d.IsDisjunct(c) {
+				return fmt.Errorf("Diff: %v not disjunct to %v", d, c)
+			}
+		}
+	}
+
+	// the rest of b, computed the other way round
+	want := inet.Gaps(b, inet.Merge(clone(bs)))
+	if got := inet.Merge(clone(diff)); !equal(got, want) {
+		return fmt.Errorf("Diff: %v minus %v, got %v, want %v", b, bs, got, want)
+	}
+	return nil
+}
+
+// adjacent reports whether c starts right after b ends
+func adjacent(b, c inet.Block) bool {
+	// the last CIDR of b and its successor
+	cs := b.CIDRs()
+	next, ok := cs[len(cs)-1].Next()
+	return ok && next.Base() == c.Base()
+}
+
+// coveredByAny reports whether b is equal to or covered by any of the blocks
+func coveredByAny(b inet.Block, bs []inet.Block) bool {
+	for _, c := range bs {
+		if c == b || c.Covers(b) {
+			return true
+		}
+	}
+	return false
+}
+
+func clone(bs []inet.Block) []inet.Block {
+	return append([]inet.Block(nil), bs...)
+}
+
+func equal(a, b []inet.Block) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package inettest
+
+import (
+	"testing"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+)
+
+func mustBlocks(ss ...string) []inet.Block {
+	var bs []inet.Block
+	for _, s := range ss {
+		b, err := inet.ParseBlock(s)
+		if err != nil {
+			panic(err)
+		}
+		bs = append(bs, b)
+	}
+	return bs
+}
+
+func TestChecks(t *testing.T) {
+	bs := mustBlocks(
+		"0.0.0.0/0",
+		"10.0.0.0/8",
+		"10.0.0.3-10.0.0.17",
+		"10.0.0.18-10.0.0.20",
+		"255.255.255.255",
+		"::/0",
+		"2001:db8::1-2001:db8::ffff",
+		"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fff0-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff",
+	)
+
+	for _, b := range bs {
+		if err := CheckRoundTrip(b); err != nil {
+			t.Error(err)
+		}
+		if err := CheckCIDRs(b); err != nil {
+			t.Error(err)
+		}
+		if err := CheckDiff(b, bs[2:4]); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if err := CheckMerge(bs); err != nil {
+		t.Error(err)
+	}
+	if err := CheckMerge(bs[2:4]); err != nil {
+		t.Error(err)
+	}
+
+	if err := CheckRoundTrip(inet.Block{}); err == nil {
+		t.Errorf("CheckRoundTrip(Block{}), want error")
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	bs := mustBlocks(
+		"10.0.0.0/24",
+		"10.0.0.128/25",
+		"10.0.0.200-10.0.1.9",
+		"10.0.2.0/31",
+		"2001:db8::/127",
+		"2001:db8::1-2001:db8::3",
+	)
+
+	v4, v6 := coverage(bs)
+	if v4.Int64() != 256+10+2 || v6.Int64() != 4 {
+		t.Errorf("coverage, got %v, %v, want %d, %d", v4, v6, 256+10+2, 4)
+	}
+
+	// the whole IPv6 space
+	_, v6 = coverage(mustBlocks("::/1", "8000::/1", "::1"))
+	if v6.BitLen() != 129 {
+		t.Errorf("coverage, got %v, want 2^128", v6)
+	}
+}
@@ -0,0 +1,61 @@
+package inet
+
+import "sync"
+
+// Interner maps equal blocks to one canonical *Block, e.g. for hundreds of millions of
+// flow records referencing a small set of distinct /24 blocks. A *Block takes 8 bytes
+// per reference instead of the 48 bytes of a Block value.
+//
+// Blocks are comparable anyway, interning doesn't change that: for blocks interned by the
+// same Interner, pointer equality is block equality, p == q if and only if *p == *q.
+// The canonical blocks are shared, never assign to them.
+//
+// The zero value is ready to use, an Interner is safe for concurrent use.
+type Interner struct {
+	mu sync.RWMutex
+	m  map[Block]*Block
+}
+
+// Intern returns the canonical *Block for b, nil for invalid blocks.
+func (in *Interner) Intern(b Block) *Block {
+	if !b.IsValid() {
+		return nil
+	}
+
+	in.mu.RLock()
+	p, ok := in.m[b]
+	in.mu.RUnlock()
+	if ok {
+		return p
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	// maybe interned in the meantime
+	if p, ok := in.m[b]; ok {
+		return p
+	}
+	if in.m == nil {
+		in.m = make(map[Block]*Block)
+	}
+	p = &b
+	in.m[b] = p
+	return p
+}
+
+// Len returns the number of distinct interned blocks.
+func (in *Interner) Len() int {
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+	return len(in.m)
+}
+
+// defaultInterner of the package level Intern
+var defaultInterner Interner
+
+// Intern returns the canonical *Block for b from the package level Interner, nil for invalid blocks.
+// The package level Interner never shrinks, use an own Interner for unbounded sets of blocks.
+func Intern(b Block) *Block {
+	return defaultInterner.Intern(b)
+}
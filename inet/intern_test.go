@@ -0,0 +1,58 @@
+package inet
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestInterner(t *testing.T) {
+	var in Interner
+
+	a := in.Intern(mustBlock("10.0.0.0/24"))
+	b := in.Intern(mustBlock("10.0.0.0-10.0.0.255"))
+	c := in.Intern(mustBlock("10.0.1.0/24"))
+
+	if a != b || *a != mustBlock("10.0.0.0/24") {
+		t.Errorf("Intern, equal blocks, got different pointers %p, %p", a, b)
+	}
+	if a == c {
+		t.Errorf("Intern, different blocks, got the same pointer")
+	}
+	if in.Intern(Block{}) != nil {
+		t.Errorf("Intern(Block{}), want nil")
+	}
+	if in.Len() != 2 {
+		t.Errorf("Len(), got %d, want 2", in.Len())
+	}
+
+	// package level
+	if Intern(mustBlock("::1")) != Intern(mustBlock("::1/128")) {
+		t.Errorf("package level Intern, equal blocks, got different pointers")
+	}
+}
+
+func TestInternerConcurrent(t *testing.T) {
+	var in Interner
+	bs := []Block{mustBlock("10.0.0.0/24"), mustBlock("10.0.1.0/24"), mustBlock("2001:db8::/32")}
+
+	ptrs := make([][]*Block, 8)
+	var wg sync.WaitGroup
+	for g := range ptrs {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for _, b := range bs {
+				ptrs[g] = append(ptrs[g], in.Intern(b))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	for g := range ptrs {
+		for i := range bs {
+			if ptrs[g][i] != ptrs[0][i] {
+				t.Fatalf("goroutine %d, block %v, got different pointers", g, bs[i])
+			}
+		}
+	}
+}
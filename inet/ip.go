@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math/bits"
 	"net"
 	"strconv"
 )
@@ -66,6 +67,15 @@ func (ip IP) toStdIP() net.IP {
 	return net.IP(ip.toBytes())
 }
 
+// ToStdIP converts to net.IP, the counterpart of FromStdIP.
+// Returns nil for the zero value.
+func (ip IP) ToStdIP() net.IP {
+	if !ip.IsValid() {
+		return nil
+	}
+	return ip.toStdIP()
+}
+
 // IsValid reports whether ip is a valid address and not the zero value of the IP type.
 // The zero value is not a valid IP address of any type.
 //
@@ -98,7 +108,29 @@ func (ip IP) String() string {
 	if !ip.IsValid() {
 		return invalidIP
 	}
-	return ip.toStdIP().String()
+	var buf [46]byte
+	return string(ip.AppendTo(buf[:0]))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface,
+// the zero value is encoded as empty string.
+func (ip IP) MarshalText() ([]byte, error) {
+	if !ip.IsValid() {
+		return []byte(""), nil
+	}
+	return ip.AppendTo(nil), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface,
+// an empty string is decoded as zero value.
+func (ip *IP) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*ip = IP{}
+		return nil
+	}
+	var err error
+	*ip, err = ParseIP(string(text))
+	return err
 }
 
 // Less reports whether the ip should sort before ip2.
@@ -113,6 +145,160 @@ func (ip IP) Less(ip2 IP) bool {
 	return ip.lo < ip2.lo
 }
 
+// Prefix returns the CIDR with prefix length bits containing ip, the mask applied, like netip.Addr.Prefix.
+// bits must be in the range 0..32 for IPv4 and 0..128 for IPv6.
+func (ip IP) Prefix(bits int) (Block, error) {
+	if !ip.IsValid() {
+		return Block{}, errInvalidIP
+	}
+
+	// internal prefix lengths are 128 bit based
+	n, max := bits, 128
+	if ip.version == v4 {
+		n, max = bits+96, 32
+	}
+	if bits < 0 || bits > max {
+		return Block{}, fmt.Errorf("prefix length %d out of range for %v", bits, ip)
+	}
+
+	mask := maskUint128[n]
+	return Block{ip.mkBaseIP(mask), ip.mkLastIP(mask)}, nil
+}
+
+// BitLen returns the number of bits to represent ip as number, the position of the highest set bit,
+// 0 for "0.0.0.0" and "::". Returns -1 for the zero value.
+func (ip IP) BitLen() int {
+	switch ip.version {
+	case v4:
+		return bits.Len64(ip.lo)
+	case v6:
+		if ip.hi != 0 {
+			return 64 + bits.Len64(ip.hi)
+		}
+		return bits.Len64(ip.lo)
+	}
+	return -1
+}
+
+// LeadingZeros returns the number of leading zero bits of ip, 32 or 128 for "0.0.0.0" and "::".
+// Returns -1 for the zero value.
+func (ip IP) LeadingZeros() int {
+	switch ip.version {
+	case v4:
+		return 32 - ip.BitLen()
+	case v6:
+		return 128 - ip.BitLen()
+	}
+	return -1
+}
+
+// TrailingZeros returns the number of trailing zero bits of ip, 32 or 128 for "0.0.0.0" and "::".
+// Returns -1 for the zero value.
+//
+// The alignment of ip, e.g. the largest CIDR starting at ip has the prefix length 32-TrailingZeros for IPv4.
+func (ip IP) TrailingZeros() int {
+	switch ip.version {
+	case v4:
+		if ip.lo == 0 {
+			return 32
+		}
+		return bits.TrailingZeros64(ip.lo)
+	case v6:
+		if ip.lo != 0 {
+			return bits.TrailingZeros64(ip.lo)
+		}
+		return 64 + bits.TrailingZeros64(ip.hi)
+	}
+	return -1
+}
+
+// Netmask returns the netmask with prefix length bits for the IP version 4 or 6 as IP,
+// e.g. 255.255.255.0 for Netmask(4, 24), without constructing blocks.
+// bits must be in the range 0..32 for IPv4 and 0..128 for IPv6.
+func Netmask(version, bits int) (IP, error) {
+	switch {
+	case version == v4 && bits >= 0 && bits <= 32:
+		// internal prefix lengths are 128 bit based, IPv4 in the lower 32 bits
+		m := maskUint128[bits+96]
+		return IP{v4, uint128{0, m.lo & 0xffffffff}}, nil
+	case version == v6 && bits >= 0 && bits <= 128:
+		return IP{v6, maskUint128[bits]}, nil
+	}
+	return IP{}, fmt.Errorf("invalid mask: IP version %d, prefix length %d", version, bits)
+}
+
+// Hostmask returns the hostmask, the inverted netmask, with prefix length bits
+// for the IP version 4 or 6 as IP, e.g. 0.0.0.255 for Hostmask(4, 24), see Netmask.
+func Hostmask(version, bits int) (IP, error) {
+	m, err := Netmask(version, bits)
+	if err != nil {
+		return IP{}, err
+	}
+	m.uint128 = not(m.uint128)
+	if m.version == v4 {
+		m.uint128 = uint128{0, m.lo & 0xffffffff}
+	}
+	return m, nil
+}
+
+// Truncate returns ip with all but the leading bits zeroed, e.g. for the anonymization of logs.
+// bits must be in the range 0..32 for IPv4 and 0..128 for IPv6, else the zero value is returned.
+func (ip IP) Truncate(bits int) IP {
+	b, err := ip.Prefix(bits)
+	if err != nil {
+		return IP{}
+	}
+	return b.base
+}
+
+// GroupByPrefix buckets the IP addresses into their containing CIDRs with prefix length
+// bits4 for IPv4 and bits6 for IPv6 addresses, in one pass. The order of the addresses is retained
+// in the buckets. Invalid addresses and addresses of a version with out of range prefix length are skipped.
+func GroupByPrefix(ips []IP, bits4, bits6 int) map[Block][]IP {
+	out := make(map[Block][]IP)
+
+	// masks computed once, internal prefix lengths are 128 bit based
+	ok4 := bits4 >= 0 && bits4 <= 32
+	ok6 := bits6 >= 0 && bits6 <= 128
+	var mask4, mask6 uint128
+	if ok4 {
+		mask4 = maskUint128[bits4+96]
+	}
+	if ok6 {
+		mask6 = maskUint128[bits6]
+	}
+
+	for _, ip := range ips {
+		var mask uint128
+		switch {
+		case ip.version == v4 && ok4:
+			mask = mask4
+		case ip.version == v6 && ok6:
+			mask = mask6
+		default:
+			continue
+		}
+
+		b := Block{ip.mkBaseIP(mask), ip.mkLastIP(mask)}
+		out[b] = append(out[b], ip)
+	}
+	return out
+}
+
+// SplitIPsByVersion returns the IPv4 and IPv6 addresses of ips, the order is retained.
+// Invalid addresses are skipped, ips is not modified.
+func SplitIPsByVersion(ips []IP) (v4, v6 []IP) {
+	for _, ip := range ips {
+		switch {
+		case ip.Is4():
+			v4 = append(v4, ip)
+		case ip.Is6():
+			v6 = append(v6, ip)
+		}
+	}
+	return
+}
+
 // Expand IP address into canonical form, useful for grep, aligned output and lexical sort.
 func (ip IP) Expand() string {
 	if ip.version == v4 {
@@ -2,6 +2,7 @@ package inet
 
 import (
 	"net"
+	"reflect"
 	"testing"
 )
 
@@ -93,6 +94,93 @@ func TestLess(t *testing.T) {
 	}
 }
 
+func TestIPPrefix(t *testing.T) {
+	tests := []struct {
+		ip   string
+		bits int
+		want string
+	}{
+		{"10.1.2.3", 24, "10.1.2.0/24"},
+		{"10.1.2.3", 32, "10.1.2.3/32"},
+		{"10.1.2.3", 0, "0.0.0.0/0"},
+		{"10.1.2.3", 7, "10.0.0.0/7"},
+		{"2001:db8:1:2::3", 64, "2001:db8:1:2::/64"},
+		{"2001:db8:1:2::3", 128, "2001:db8:1:2::3/128"},
+		{"2001:db8:1:2::3", 0, "::/0"},
+	}
+	for _, tt := range tests {
+		got, err := mustIP(tt.ip).Prefix(tt.bits)
+		if err != nil || got.String() != tt.want {
+			t.Errorf("Prefix(%s, %d), got: %v, %v, want: %s", tt.ip, tt.bits, got, err, tt.want)
+		}
+	}
+
+	for _, tt := range []struct {
+		ip   IP
+		bits int
+	}{
+		{mustIP("10.1.2.3"), 33},
+		{mustIP("10.1.2.3"), -1},
+		{mustIP("::1"), 129},
+		{IP{}, 0},
+	} {
+		if _, err := tt.ip.Prefix(tt.bits); err == nil {
+			t.Errorf("Prefix(%v, %d), want error", tt.ip, tt.bits)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := mustIP("192.168.17.42").Truncate(16); got != mustIP("192.168.0.0") {
+		t.Errorf("Truncate(16), got %v", got)
+	}
+	if got := mustIP("2001:db8::1").Truncate(32); got != mustIP("2001:db8::") {
+		t.Errorf("Truncate(32), got %v", got)
+	}
+	if got := mustIP("10.0.0.1").Truncate(33); got.IsValid() {
+		t.Errorf("Truncate(33), got %v, want zero value", got)
+	}
+	if got := (IP{}).ToStdIP(); got != nil {
+		t.Errorf("ToStdIP() on zero value, got %v, want nil", got)
+	}
+}
+
+func TestGroupByPrefix(t *testing.T) {
+	var ips []IP
+	for _, s := range []string{"10.0.0.1", "10.0.1.1", "10.0.0.2", "2001:db8::1", "2001:db8:0:1::1", "2001:db8::2"} {
+		ips = append(ips, mustIP(s))
+	}
+	ips = append(ips, IP{})
+
+	got := GroupByPrefix(ips, 24, 64)
+	want := map[Block][]IP{
+		mustBlock("10.0.0.0/24"):       {mustIP("10.0.0.1"), mustIP("10.0.0.2")},
+		mustBlock("10.0.1.0/24"):       {mustIP("10.0.1.1")},
+		mustBlock("2001:db8::/64"):     {mustIP("2001:db8::1"), mustIP("2001:db8::2")},
+		mustBlock("2001:db8:0:1::/64"): {mustIP("2001:db8:0:1::1")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupByPrefix, got: %v\nwant: %v", got, want)
+	}
+
+	// out of range for IPv6, only IPv4 buckets
+	if got := GroupByPrefix(ips, 0, 129); len(got) != 1 || len(got[mustBlock("0.0.0.0/0")]) != 3 {
+		t.Errorf("GroupByPrefix(0, 129), got: %v", got)
+	}
+}
+
+func TestSplitIPsByVersion(t *testing.T) {
+	ips := []IP{mustIP("::1"), mustIP("10.0.0.1"), {}, mustIP("2001:db8::1"), mustIP("1.2.3.4")}
+
+	v4, v6 := SplitIPsByVersion(ips)
+	if want := []IP{mustIP("10.0.0.1"), mustIP("1.2.3.4")}; !reflect.DeepEqual(v4, want) {
+		t.Errorf("SplitIPsByVersion, v4 got %v, want %v", v4, want)
+	}
+	if want := []IP{mustIP("::1"), mustIP("2001:db8::1")}; !reflect.DeepEqual(v6, want) {
+		t.Errorf("SplitIPsByVersion, v6 got %v, want %v", v6, want)
+	}
+}
+
 func TestIP_addOne(t *testing.T) {
 	ips := []struct {
 		in   IP
@@ -157,3 +245,76 @@ func TestIP_fromBytes(t *testing.T) {
 		}
 	}
 }
+
+func TestNetmaskHostmask(t *testing.T) {
+	tests := []struct {
+		version, bits int
+		net, host     string
+	}{
+		{4, 0, "0.0.0.0", "255.255.255.255"},
+		{4, 24, "255.255.255.0", "0.0.0.255"},
+		{4, 19, "255.255.224.0", "0.0.31.255"},
+		{4, 32, "255.255.255.255", "0.0.0.0"},
+		{6, 0, "::", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+		{6, 64, "ffff:ffff:ffff:ffff::", "::ffff:ffff:ffff:ffff"},
+		{6, 65, "ffff:ffff:ffff:ffff:8000::", "::7fff:ffff:ffff:ffff"},
+		{6, 128, "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", "::"},
+	}
+
+	for _, tt := range tests {
+		net, err := Netmask(tt.version, tt.bits)
+		if err != nil || net != mustIP(tt.net) {
+			t.Errorf("Netmask(%d, %d), got %v, %v, want %s", tt.version, tt.bits, net, err, tt.net)
+		}
+		host, err := Hostmask(tt.version, tt.bits)
+		if err != nil || host != mustIP(tt.host) {
+			t.Errorf("Hostmask(%d, %d), got %v, %v, want %s", tt.version, tt.bits, host, err, tt.host)
+		}
+	}
+
+	for _, tt := range [][2]int{{4, 33}, {4, -1}, {6, 129}, {5, 8}, {0, 0}} {
+		if _, err := Netmask(tt[0], tt[1]); err == nil {
+			t.Errorf("Netmask(%d, %d), want error", tt[0], tt[1])
+		}
+		if _, err := Hostmask(tt[0], tt[1]); err == nil {
+			t.Errorf("Hostmask(%d, %d), want error", tt[0], tt[1])
+		}
+	}
+}
+
+func TestBitLenTrailingZeros(t *testing.T) {
+	tests := []struct {
+		ip                  string
+		bitLen, lead, trail int
+	}{
+		{"0.0.0.0", 0, 32, 32},
+		{"0.0.0.1", 1, 31, 0},
+		{"10.0.0.0", 28, 4, 25},
+		{"10.0.4.0", 28, 4, 10},
+		{"255.255.255.255", 32, 0, 0},
+		{"::", 0, 128, 128},
+		{"::1", 1, 127, 0},
+		{"::1:0:0:0:0", 65, 63, 64},
+		{"2001:db8::", 126, 2, 99},
+		{"2001:db8::100", 126, 2, 8},
+		{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", 128, 0, 0},
+	}
+
+	for _, tt := range tests {
+		ip := mustIP(tt.ip)
+		if got := ip.BitLen(); got != tt.bitLen {
+			t.Errorf("%s.BitLen(), got %d, want %d", tt.ip, got, tt.bitLen)
+		}
+		if got := ip.LeadingZeros(); got != tt.lead {
+			t.Errorf("%s.LeadingZeros(), got %d, want %d", tt.ip, got, tt.lead)
+		}
+		if got := ip.TrailingZeros(); got != tt.trail {
+			t.Errorf("%s.TrailingZeros(), got %d, want %d", tt.ip, got, tt.trail)
+		}
+	}
+
+	var zero IP
+	if zero.BitLen() != -1 || zero.LeadingZeros() != -1 || zero.TrailingZeros() != -1 {
+		t.Errorf("zero value, want -1")
+	}
+}
@@ -0,0 +1,53 @@
+package inet
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrLimit is wrapped by the errors of operations exceeding MaxCIDRSplit or other size limits, e.g. of ExpandTargets.
+var ErrLimit = errors.New("limit exceeded")
+
+// MaxCIDRSplit limits the number of blocks returned by SplitCIDR, SplitRecursive and SplitToAligned,
+// bounding memory and cpu of services splitting untrusted input. A value <= 0 disables the limit,
+// but not the hard limit of 2^30 blocks, beyond that the allocation itself fails.
+//
+// Set it at program start, it isn't synchronized.
+//
+// CIDRs needs no limit, the output is bounded by 62 CIDRs for IPv4 and 254 CIDRs for IPv6,
+// use CIDRsN for tighter per-call limits.
+var MaxCIDRSplit = 1 << 20
+
+// maxSplit is the hard limit for splits, even with MaxCIDRSplit disabled.
+const maxSplit = 1 << 30
+
+// checkSplit returns an error if n blocks exceed MaxCIDRSplit or maxSplit, n < 0 signals an overflow.
+func checkSplit(b Block, n int) error {
+	if n < 0 || n > maxSplit {
+		return fmt.Errorf("%w: splitting %v exceeds %d blocks", ErrLimit, b, maxSplit)
+	}
+	if MaxCIDRSplit > 0 && n > MaxCIDRSplit {
+		return fmt.Errorf("%w: splitting %v exceeds MaxCIDRSplit %d", ErrLimit, b, MaxCIDRSplit)
+	}
+	return nil
+}
+
+// splitCount returns the number of blocks split recursively by bits, -1 beyond maxSplit.
+// The count is clamped at maxSplit, no overflow even for 32 bit ints.
+func splitCount(bits []int) int {
+	n, level := 0, 0
+	for _, b := range bits {
+		if b < 0 {
+			// error reported by SplitCIDR
+			return 0
+		}
+		if b > 30 || level+b > 30 {
+			return -1
+		}
+		level += b
+		if n += 1 << level; n < 0 || n > maxSplit {
+			return -1
+		}
+	}
+	return n
+}
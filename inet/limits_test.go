@@ -0,0 +1,77 @@
+package inet
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMaxCIDRSplit(t *testing.T) {
+	defer func(old int) { MaxCIDRSplit = old }(MaxCIDRSplit)
+	MaxCIDRSplit = 16
+
+	b := mustBlock("10.0.0.0/8")
+
+	if got, err := b.SplitCIDR(4); err != nil || len(got) != 16 {
+		t.Errorf("SplitCIDR(4), got %d blocks, %v, want 16, nil", len(got), err)
+	}
+	if _, err := b.SplitCIDR(5); !errors.Is(err, ErrLimit) {
+		t.Errorf("SplitCIDR(5), got %v, want ErrLimit", err)
+	}
+
+	// 2 + 4 + 8 blocks
+	if got, err := b.SplitRecursive(1, 1, 1); err != nil || len(got) != 14 {
+		t.Errorf("SplitRecursive(1, 1, 1), got %d blocks, %v, want 14, nil", len(got), err)
+	}
+	if _, err := b.SplitRecursive(1, 1, 1, 1); !errors.Is(err, ErrLimit) {
+		t.Errorf("SplitRecursive(1, 1, 1, 1), got %v, want ErrLimit", err)
+	}
+	if _, err := mustBlock("::/0").SplitRecursive(40, 40); !errors.Is(err, ErrLimit) {
+		t.Errorf("SplitRecursive(40, 40), got %v, want ErrLimit", err)
+	}
+
+	// 10.0.0.0/8 is split into 16 /12, 10.0.0.0/7 into 32 /12
+	if got, err := b.SplitToAligned(12); err != nil || len(got) != 16 {
+		t.Errorf("SplitToAligned(12), got %d blocks, %v, want 16, nil", len(got), err)
+	}
+	if _, err := mustBlock("10.0.0.0/7").SplitToAligned(12); !errors.Is(err, ErrLimit) {
+		t.Errorf("SplitToAligned(12), got %v, want ErrLimit", err)
+	}
+
+	// disabled
+	MaxCIDRSplit = 0
+	if got, err := b.SplitCIDR(5); err != nil || len(got) != 32 {
+		t.Errorf("disabled, SplitCIDR(5), got %d blocks, %v, want 32, nil", len(got), err)
+	}
+	if _, err := mustBlock("::/0").SplitRecursive(40, 40); !errors.Is(err, ErrLimit) {
+		t.Errorf("disabled, SplitRecursive(40, 40), overflow, got %v, want ErrLimit", err)
+	}
+	if _, err := mustBlock("::/0").SplitRecursive(20, 20); !errors.Is(err, ErrLimit) {
+		t.Errorf("disabled, SplitRecursive(20, 20), got %v, want ErrLimit", err)
+	}
+	for _, bits := range []int{31, 40, 62} {
+		if _, err := mustBlock("::/0").SplitCIDR(bits); !errors.Is(err, ErrLimit) {
+			t.Errorf("disabled, SplitCIDR(%d), got %v, want ErrLimit", bits, err)
+		}
+	}
+}
+
+func TestSplitCount(t *testing.T) {
+	tests := []struct {
+		bits []int
+		want int
+	}{
+		{[]int{1, 1, 1}, 14},
+		{[]int{14, 15}, 1<<14 + 1<<29},
+		{[]int{15, 15}, -1},
+		{[]int{20, 20}, -1},
+		{[]int{16, 15}, -1},
+		{[]int{31}, -1},
+		{[]int{64}, -1},
+		{[]int{1, -1}, 0},
+	}
+	for _, tt := range tests {
+		if got := splitCount(tt.bits); got != tt.want {
+			t.Errorf("splitCount(%v), got %d, want %d", tt.bits, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package inet
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ListError is returned by ParseIPList for invalid tokens and by VerifyBlocksInvariants.
+type ListError struct {
+	// Errs are the errors of the invalid tokens or blocks, in input order.
+	Errs []error
+}
+
+// Error implements the error interface.
+func (e *ListError) Error() string {
+	msgs := make([]string, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ParseIPList parses a comma, semicolon or whitespace separated list of IP addresses, ranges and CIDRs
+// as found in ACL spreadsheets and ticket texts, e.g.
+//
+//  10.0.0.1, 10.0.0.5 - 10.0.0.9; 192.168.1.0/28
+//
+// Blanks around the dash of ranges are allowed. The blocks are returned normalized, sorted and merged,
+// see Merge. Invalid tokens are skipped and returned as *ListError, all valid blocks are returned anyway.
+func ParseIPList(s string) ([]Block, error) {
+	var bs []Block
+	var errs []error
+
+	for _, tok := range tokenizeList(s) {
+		b, err := ParseBlock(tok)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		bs = append(bs, b)
+	}
+
+	bs = Merge(bs)
+	if errs != nil {
+		return bs, &ListError{errs}
+	}
+	return bs, nil
+}
+
+// tokenizeList splits the list at the separators, the parts of ranges with blanks around the dash are rejoined
+func tokenizeList(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || unicode.IsSpace(r)
+	})
+
+	var toks []string
+	for i := 0; i < len(fields); i++ {
+		tok := fields[i]
+
+		// "a -", "a-" followed by "b" or "a" followed by "-b", "- b"
+		for i+1 < len(fields) && (strings.HasSuffix(tok, "-") || strings.HasPrefix(fields[i+1], "-")) {
+			i++
+			tok += fields[i]
+		}
+		toks = append(toks, tok)
+	}
+	return toks
+}
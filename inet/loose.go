@@ -0,0 +1,65 @@
+package inet
+
+import (
+	"regexp"
+	"strings"
+)
+
+// a fix for a common typo in human-maintained input
+type looseFix struct {
+	desc string
+	fix  func(string) string
+}
+
+var (
+	reBlanksAroundSep = regexp.MustCompile(`\s*([/-])\s*`)
+	reTrailingDots    = regexp.MustCompile(`\.+([/-]|$)`)
+)
+
+// the fixes, in order
+var looseFixes = []looseFix{
+	{"trimmed surrounding blanks", strings.TrimSpace},
+	{"replaced dash by '-'", strings.NewReplacer("–", "-", "—", "-", "‒", "-", "−", "-").Replace},
+	{"replaced '..' by '-'", func(s string) string {
+		// not in IPv6 addresses, "::" is valid there
+		if strings.Contains(s, ":") {
+			return s
+		}
+		return strings.Replace(s, "..", "-", 1)
+	}},
+	{"removed blanks around separator", func(s string) string { return reBlanksAroundSep.ReplaceAllString(s, "$1") }},
+	{"removed trailing dots", func(s string) string { return reTrailingDots.ReplaceAllString(s, "$1") }},
+}
+
+// ParseBlockLoose parses s like ParseBlock, but fixes trivially recoverable typos first,
+// as found in human-maintained sheets:
+//
+//  10.0.0.1 – 10.0.0.9    dashes other than '-'
+//  10.0.0.1..10.0.0.9     '..' instead of '-'
+//  10.0.0.0 / 8           blanks around '/' and '-'
+//  10.0.0.1.              trailing dots
+//
+// The applied fixes are returned as descriptions, nil if s parsed without fixes.
+// Returns error and Block{} if the input is invalid even after the fixes.
+func ParseBlockLoose(s string) (b Block, fixes []string, err error) {
+	if b, err = ParseBlock(s); err == nil {
+		return
+	}
+
+	fixed := s
+	for _, f := range looseFixes {
+		if t := f.fix(fixed); t != fixed {
+			fixed = t
+			fixes = append(fixes, f.desc)
+		}
+	}
+
+	if fixes == nil {
+		return
+	}
+
+	if b, err = ParseBlock(fixed); err != nil {
+		return Block{}, nil, err
+	}
+	return b, fixes, nil
+}
@@ -2,6 +2,7 @@ package inet
 
 import (
 	"encoding/binary"
+	"math/big"
 	"math/bits"
 )
 
@@ -31,6 +32,13 @@ func (u uint128) and(m uint128) uint128 {
 	return uint128{u.hi & m.hi, u.lo & m.lo}
 }
 
+// less reports whether u < m, branchless by the borrow of u-m
+func (u uint128) less(m uint128) bool {
+	_, borrow := bits.Sub64(u.lo, m.lo, 0)
+	_, borrow = bits.Sub64(u.hi, m.hi, borrow)
+	return borrow != 0
+}
+
 // cmp
 func (u uint128) cmp(m uint128) int {
 	if u == m {
@@ -48,6 +56,45 @@ func (u uint128) cmp(m uint128) int {
 	return 1
 }
 
+// sub returns u-m, u must not be less than m
+func (u uint128) sub(m uint128) uint128 {
+	lo, borrow := bits.Sub64(u.lo, m.lo, 0)
+	hi, _ := bits.Sub64(u.hi, m.hi, borrow)
+	return uint128{hi, lo}
+}
+
+// add returns u+m and the carry
+func (u uint128) add(m uint128) (uint128, uint64) {
+	lo, carry := bits.Add64(u.lo, m.lo, 0)
+	hi, carry := bits.Add64(u.hi, m.hi, carry)
+	return uint128{hi, lo}, carry
+}
+
+// subBorrow returns u-m and the borrow
+func (u uint128) subBorrow(m uint128) (uint128, uint64) {
+	lo, borrow := bits.Sub64(u.lo, m.lo, 0)
+	hi, borrow := bits.Sub64(u.hi, m.hi, borrow)
+	return uint128{hi, lo}, borrow
+}
+
+// lsh returns u<<n, n in [0, 128)
+func (u uint128) lsh(n int) uint128 {
+	switch {
+	case n == 0:
+		return u
+	case n >= 64:
+		return uint128{u.lo << uint(n-64), 0}
+	}
+	return uint128{u.hi<<uint(n) | u.lo>>uint(64-n), u.lo << uint(n)}
+}
+
+// toBig converts to math/big
+func (u uint128) toBig() *big.Int {
+	z := new(big.Int).SetUint64(u.hi)
+	z.Lsh(z, 64)
+	return z.Or(z, new(big.Int).SetUint64(u.lo))
+}
+
 // strip high 96 bits
 func (ip IP) strip96() IP {
 	ip.uint128 = uint128{0, uint64(uint32(ip.lo))}
@@ -0,0 +1,100 @@
+package inet
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// randUint128s, with many equal hi and lo halves, the corner cases of the comparisons
+func randUint128s(n int) []uint128 {
+	prng := rand.New(rand.NewSource(1))
+	pick := []uint64{0, 1, 1<<63 - 1, 1 << 63, ^uint64(0)}
+
+	us := make([]uint128, n)
+	for i := range us {
+		hi, lo := prng.Uint64(), prng.Uint64()
+		if prng.Intn(2) == 0 {
+			hi = pick[prng.Intn(len(pick))]
+		}
+		if prng.Intn(2) == 0 {
+			lo = pick[prng.Intn(len(pick))]
+		}
+		us[i] = uint128{hi, lo}
+	}
+	return us
+}
+
+func TestUint128Cmp(t *testing.T) {
+	us := randUint128s(200)
+	for _, u := range us {
+		for _, m := range us {
+			want := u.toBig().Cmp(m.toBig())
+			if got := u.cmp(m); got != want {
+				t.Fatalf("%v.cmp(%v), got %d, want %d", u, m, got, want)
+			}
+			if got := u.less(m); got != (want < 0) {
+				t.Fatalf("%v.less(%v), got %v, want %v", u, m, got, want < 0)
+			}
+		}
+	}
+}
+
+func BenchmarkUint128Cmp(b *testing.B) {
+	us := randUint128s(1024)
+	var sink int
+	for i := 0; i < b.N; i++ {
+		sink += us[i%1024].cmp(us[(i+1)%1024])
+	}
+	_ = sink
+}
+
+func BenchmarkIPLess(b *testing.B) {
+	ips := make([]IP, 1024)
+	for i, u := range randUint128s(len(ips)) {
+		ips[i] = IP{v6, u}
+	}
+
+	var sink int
+	for i := 0; i < b.N; i++ {
+		if ips[i%1024].Less(ips[(i+1)%1024]) {
+			sink++
+		}
+	}
+	_ = sink
+}
+
+func BenchmarkBlockLess(b *testing.B) {
+	bs := rand128Blocks(1024)
+	var sink int
+	for i := 0; i < b.N; i++ {
+		if bs[i%1024].Less(bs[(i+1)%1024]) {
+			sink++
+		}
+	}
+	_ = sink
+}
+
+func BenchmarkBlockCovers(b *testing.B) {
+	bs := rand128Blocks(1024)
+	var sink int
+	for i := 0; i < b.N; i++ {
+		if bs[i%1024].Covers(bs[(i+1)%1024]) {
+			sink++
+		}
+	}
+	_ = sink
+}
+
+// rand128Blocks, IPv6 blocks, nested and disjunct
+func rand128Blocks(n int) []Block {
+	us := randUint128s(2 * n)
+	bs := make([]Block, n)
+	for i := range bs {
+		base, last := us[2*i], us[2*i+1]
+		if last.less(base) {
+			base, last = last, base
+		}
+		bs[i] = Block{IP{v6, base}, IP{v6, last}}
+	}
+	return bs
+}
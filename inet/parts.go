@@ -0,0 +1,62 @@
+package inet
+
+import "fmt"
+
+// Parts is the structured form of a Block, e.g. for REST APIs with
+// structured fields instead of the opaque string form.
+type Parts struct {
+	Base IP `json:"base"`
+	Last IP `json:"last"`
+
+	// Bits is the prefix length, -1 for ranges.
+	Bits   int  `json:"bits"`
+	IsCIDR bool `json:"isCIDR"`
+}
+
+// Parts returns the structured form of b.
+func (b Block) Parts() Parts {
+	return Parts{
+		Base:   b.base,
+		Last:   b.last,
+		Bits:   b.Bits(),
+		IsCIDR: b.IsCIDR(),
+	}
+}
+
+// BlockFromParts returns the Block for p, the inverse of Block.Parts.
+// Bits and IsCIDR are checked against the addresses, they must be consistent.
+func BlockFromParts(p Parts) (Block, error) {
+	b, err := BlockFromRange(p.Base, p.Last)
+	if err != nil {
+		return Block{}, err
+	}
+
+	if bits := b.Bits(); p.Bits != bits || p.IsCIDR != (bits >= 0) {
+		return Block{}, fmt.Errorf("%v: %v, inconsistent bits %d, isCIDR %v", invalidBlock, b, p.Bits, p.IsCIDR)
+	}
+	return b, nil
+}
+
+// Raw returns the internal representation of ip, the IP version 4 or 6, 0 for the zero value,
+// and the address as 128 bit number, IPv4 addresses in the lower 32 bits.
+// The inverse is UnsafeIPFromParts, e.g. for binary snapshots.
+func (ip IP) Raw() (version uint8, hi, lo uint64) {
+	return ip.version, ip.hi, ip.lo
+}
+
+// UnsafeIPFromParts returns the IP from the internal representation, see IP.Raw.
+//
+// UNSAFE: nothing is validated, for bulk loads from trusted binary input only,
+// e.g. snapshots written with IP.Raw. Inconsistent input results in undefined behavior
+// of all functions and methods. Check untrusted input with VerifyIPInvariants.
+func UnsafeIPFromParts(version uint8, hi, lo uint64) IP {
+	return IP{version, uint128{hi, lo}}
+}
+
+// UnsafeBlockFromParts returns the Block [base, last] from trusted input, see UnsafeIPFromParts.
+//
+// UNSAFE: nothing is validated, neither the versions nor base <= last, use BlockFromRange
+// for untrusted input or check with VerifyBlockInvariants.
+func UnsafeBlockFromParts(base, last IP) Block {
+	return Block{base, last}
+}
@@ -0,0 +1,94 @@
+package inet
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParts(t *testing.T) {
+	for _, s := range []string{"10.0.0.0/8", "10.0.0.3-10.0.17.134", "::/0", "2001:db8::1"} {
+		b := mustBlock(s)
+		p := b.Parts()
+
+		got, err := BlockFromParts(p)
+		if err != nil || got != b {
+			t.Errorf("BlockFromParts(%v.Parts()), got %v, %v", s, got, err)
+		}
+
+		// JSON round trip
+		buf, err := json.Marshal(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var q Parts
+		if err := json.Unmarshal(buf, &q); err != nil || q != p {
+			t.Errorf("JSON round trip %s, got %+v, %v, want %+v", buf, q, err, p)
+		}
+	}
+
+	want := `{"base":"10.0.0.0","last":"10.255.255.255","bits":8,"isCIDR":true}`
+	if buf, _ := json.Marshal(mustBlock("10.0.0.0/8").Parts()); string(buf) != want {
+		t.Errorf("json.Marshal, got %s, want %s", buf, want)
+	}
+
+	for _, p := range []Parts{
+		{},
+		{Base: mustIP("10.0.0.1"), Last: mustIP("::1")},
+		{Base: mustIP("10.0.0.2"), Last: mustIP("10.0.0.1")},
+		{Base: mustIP("10.0.0.0"), Last: mustIP("10.0.0.255"), Bits: 25, IsCIDR: true},
+		{Base: mustIP("10.0.0.0"), Last: mustIP("10.0.0.255"), Bits: 24, IsCIDR: false},
+		{Base: mustIP("10.0.0.1"), Last: mustIP("10.0.0.2"), Bits: 0, IsCIDR: false},
+	} {
+		if _, err := BlockFromParts(p); err == nil {
+			t.Errorf("BlockFromParts(%+v), want error", p)
+		}
+	}
+
+	var ip IP
+	if err := ip.UnmarshalText([]byte("foo")); err == nil {
+		t.Errorf("UnmarshalText(foo), want error")
+	}
+}
+
+func TestUnsafeFromParts(t *testing.T) {
+	for _, s := range []string{"10.0.0.0/8", "10.0.0.3-10.0.17.134", "::/0", "2001:db8::1", "255.255.255.255"} {
+		b := mustBlock(s)
+
+		base := UnsafeIPFromParts(b.Base().Raw())
+		last := UnsafeIPFromParts(b.Last().Raw())
+
+		c := UnsafeBlockFromParts(base, last)
+		if c != b {
+			t.Errorf("UnsafeBlockFromParts(%s), got %v", s, c)
+		}
+		if err := VerifyBlockInvariants(c); err != nil {
+			t.Errorf("UnsafeBlockFromParts(%s), unexpected error: %v", s, err)
+		}
+	}
+
+	if version, hi, lo := (IP{}).Raw(); version != 0 || hi != 0 || lo != 0 {
+		t.Errorf("zero value, Raw() = %d, %d, %d, want 0, 0, 0", version, hi, lo)
+	}
+
+	// not validated
+	b := UnsafeBlockFromParts(mustIP("10.0.0.2"), mustIP("10.0.0.1"))
+	if err := VerifyBlockInvariants(b); err == nil {
+		t.Errorf("UnsafeBlockFromParts, base > last, want Verify error")
+	}
+}
+
+func BenchmarkBlockFromParts(b *testing.B) {
+	base, last := mustIP("10.0.0.0"), mustIP("10.0.0.255")
+
+	b.Run("BlockFromRange", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = BlockFromRange(base, last)
+		}
+	})
+
+	b.Run("UnsafeBlockFromParts", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = UnsafeBlockFromParts(base, last)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package inet
+
+import "sort"
+
+// radixThreshold, below this length the comparison sort is faster
+const radixThreshold = 4096
+
+// radixSortBlocks is an LSD radix sort on the key (version, base.hi, base.lo),
+// runs of equal base addresses are sorted afterwards by last address, see Block.Less.
+// Byte positions with just one value in all keys are skipped, e.g. the high bits of IPv4 addresses.
+func radixSortBlocks(bs []Block) {
+	n := len(bs)
+
+	// key words, least significant first
+	word := func(b *Block, w int) uint64 {
+		switch w {
+		case 0:
+			return b.base.lo
+		case 1:
+			return b.base.hi
+		default:
+			return uint64(b.base.version)
+		}
+	}
+
+	// histograms of all byte positions in one scan
+	const passes = 2*8 + 1
+	var counts [passes][256]int
+	for i := range bs {
+		for p := 0; p < passes; p++ {
+			counts[p][byte(word(&bs[i], p/8)>>(uint(p%8)*8))]++
+		}
+	}
+
+	src, dst := bs, make([]Block, n)
+	for p := 0; p < passes; p++ {
+		w, shift := p/8, uint(p%8)*8
+
+		// skip, all keys have the same byte at this position
+		if counts[p][byte(word(&src[0], w)>>shift)] == n {
+			continue
+		}
+
+		// offsets, prefix sums
+		var offs [256]int
+		sum := 0
+		for k, c := range counts[p] {
+			offs[k] = sum
+			sum += c
+		}
+
+		for i := range src {
+			k := byte(word(&src[i], w) >> shift)
+			dst[offs[k]] = src[i]
+			offs[k]++
+		}
+		src, dst = dst, src
+	}
+
+	// sorted in the buffer, copy back
+	if &src[0] != &bs[0] {
+		copy(bs, src)
+	}
+
+	// equal bases, e.g. nested prefixes, containers to the left
+	for i := 0; i < n; {
+		j := i + 1
+		for j < n && bs[j].base == bs[i].base {
+			j++
+		}
+		if j-i > 1 {
+			sort.Sort(blockSlice(bs[i:j]))
+		}
+		i = j
+	}
+}
+
+// radixSortIPs is an LSD radix sort on the key (version, hi, lo), the same order as IP.Less.
+func radixSortIPs(ips []IP) {
+	n := len(ips)
+
+	// key words, least significant first
+	word := func(ip *IP, w int) uint64 {
+		switch w {
+		case 0:
+			return ip.lo
+		case 1:
+			return ip.hi
+		default:
+			return uint64(ip.version)
+		}
+	}
+
+	const passes = 2*8 + 1
+	var counts [passes][256]int
+	for i := range ips {
+		for p := 0; p < passes; p++ {
+			counts[p][byte(word(&ips[i], p/8)>>(uint(p%8)*8))]++
+		}
+	}
+
+	src, dst := ips, make([]IP, n)
+	for p := 0; p < passes; p++ {
+		w, shift := p/8, uint(p%8)*8
+
+		if counts[p][byte(word(&src[0], w)>>shift)] == n {
+			continue
+		}
+
+		var offs [256]int
+		sum := 0
+		for k, c := range counts[p] {
+			offs[k] = sum
+			sum += c
+		}
+
+		for i := range src {
+			k := byte(word(&src[i], w) >> shift)
+			dst[offs[k]] = src[i]
+			offs[k]++
+		}
+		src, dst = dst, src
+	}
+
+	if &src[0] != &ips[0] {
+		copy(ips, src)
+	}
+}
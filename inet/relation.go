@@ -0,0 +1,159 @@
+package inet
+
+import "sort"
+
+// Relation of two blocks, see Block.Relation.
+type Relation int
+
+// The relations of block b to block c, as returned by b.Relation(c).
+//
+//  Equal           b |-------|
+//                  c |-------|
+//
+//  Covers          b |-----------|
+//                  c    |----|
+//
+//  CoveredBy       b    |----|
+//                  c |-----------|
+//
+//  OverlapsLeft    b |-------|
+//                  c     |-------|
+//
+//  OverlapsRight   b     |-------|
+//                  c |-------|
+//
+//  DisjointBefore  b |---|
+//                  c       |---|
+//
+//  DisjointAfter   b       |---|
+//                  c |---|
+const (
+	// NoRelation, at least one block is invalid
+	NoRelation Relation = iota
+	Equal
+	Covers
+	CoveredBy
+	OverlapsLeft
+	OverlapsRight
+	DisjointBefore
+	DisjointAfter
+	// VersionMismatch, the blocks are of different IP versions
+	VersionMismatch
+)
+
+var relationNames = [...]string{
+	NoRelation:      "NoRelation",
+	Equal:           "Equal",
+	Covers:          "Covers",
+	CoveredBy:       "CoveredBy",
+	OverlapsLeft:    "OverlapsLeft",
+	OverlapsRight:   "OverlapsRight",
+	DisjointBefore:  "DisjointBefore",
+	DisjointAfter:   "DisjointAfter",
+	VersionMismatch: "VersionMismatch",
+}
+
+// String implements the fmt.Stringer interface.
+func (r Relation) String() string {
+	if r < 0 || int(r) >= len(relationNames) {
+		return "Relation(?)"
+	}
+	return relationNames[r]
+}
+
+// Relation returns the relation of b to c in one comparison, for exhaustive switches
+// instead of calling Covers, Overlaps and IsDisjunct separately.
+func (b Block) Relation(c Block) Relation {
+	switch {
+	case !b.IsValid() || !c.IsValid():
+		return NoRelation
+	case b.base.version != c.base.version:
+		return VersionMismatch
+	case b.last.uint128.cmp(c.base.uint128) < 0:
+		return DisjointBefore
+	case b.base.uint128.cmp(c.last.uint128) > 0:
+		return DisjointAfter
+	}
+
+	base := b.base.uint128.cmp(c.base.uint128)
+	last := b.last.uint128.cmp(c.last.uint128)
+
+	switch {
+	case base == 0 && last == 0:
+		return Equal
+	case base <= 0 && last >= 0:
+		return Covers
+	case base >= 0 && last <= 0:
+		return CoveredBy
+	case base < 0:
+		return OverlapsLeft
+	default:
+		return OverlapsRight
+	}
+}
+
+// Conflict is a pair of input blocks, equal or partially overlapping, see Conflicts.
+type Conflict struct {
+	// the positions in the input, I < J
+	I, J int
+
+	// the blocks at the positions I and J
+	A, B Block
+
+	// the relation of A to B, Equal, OverlapsLeft or OverlapsRight
+	Relation Relation
+}
+
+// Conflicts returns all pairs of blocks in bs that are equal or partially overlapping,
+// the dangerous cases for IPAM imports. Nested blocks are no conflict, invalid blocks are ignored.
+// The conflicts are sorted by the input positions, bs is not modified.
+//
+// The blocks are swept in sort order, O(n log n + n*d + k) with d the nesting depth and k the conflicts.
+func Conflicts(bs []Block) []Conflict {
+	idx := make([]int, 0, len(bs))
+	for i, b := range bs {
+		if b.IsValid() {
+			idx = append(idx, i)
+		}
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return bs[idx[i]].Less(bs[idx[j]]) })
+
+	var out []Conflict
+
+	// the indexes of the prior blocks not ending before the current block
+	var active []int
+	for _, j := range idx {
+		b := bs[j]
+
+		// drop the ended blocks
+		k := 0
+		for _, i := range active {
+			if bs[i].base.version == b.base.version && bs[i].last.uint128.cmp(b.base.uint128) >= 0 {
+				active[k] = i
+				k++
+			}
+		}
+		active = active[:k]
+
+		for _, i := range active {
+			a := bs[i]
+			switch a.Relation(b) {
+			case Equal, OverlapsLeft:
+				if i < j {
+					out = append(out, Conflict{I: i, J: j, A: a, B: b, Relation: a.Relation(b)})
+				} else {
+					out = append(out, Conflict{I: j, J: i, A: b, B: a, Relation: b.Relation(a)})
+				}
+			}
+		}
+		active = append(active, j)
+	}
+
+	sort.Slice(out, func(x, y int) bool {
+		if out[x].I != out[y].I {
+			return out[x].I < out[y].I
+		}
+		return out[x].J < out[y].J
+	})
+	return out
+}
@@ -0,0 +1,105 @@
+package inet
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestRelation(t *testing.T) {
+	tests := []struct {
+		b, c string
+		want Relation
+	}{
+		{"10.0.0.0/24", "10.0.0.0/24", Equal},
+		{"10.0.0.0/8", "10.0.0.0/24", Covers},
+		{"10.0.0.0/24", "10.0.0.0/8", CoveredBy},
+		{"10.0.0.0-10.0.0.17", "10.0.0.5-10.0.0.20", OverlapsLeft},
+		{"10.0.0.5-10.0.0.20", "10.0.0.0-10.0.0.17", OverlapsRight},
+		{"10.0.0.0/24", "10.0.1.0/24", DisjointBefore},
+		{"10.0.1.0/24", "10.0.0.0/24", DisjointAfter},
+		{"10.0.0.0/8", "::/0", VersionMismatch},
+	}
+
+	for _, tt := range tests {
+		if got := mustBlock(tt.b).Relation(mustBlock(tt.c)); got != tt.want {
+			t.Errorf("%s.Relation(%s), got %v, want %v", tt.b, tt.c, got, tt.want)
+		}
+	}
+
+	if got := (Block{}).Relation(mustBlock("::/0")); got != NoRelation {
+		t.Errorf("invalid block, got %v, want %v", got, NoRelation)
+	}
+	if got := Relation(42).String(); got != "Relation(?)" {
+		t.Errorf("Relation(42).String(), got %q", got)
+	}
+}
+
+// consistent with Covers, Overlaps and IsDisjunct
+func TestRelationRandom(t *testing.T) {
+	prng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 100_000; i++ {
+		a, b := IP{v4, uint128{0, uint64(prng.Intn(32))}}, IP{v4, uint128{0, uint64(prng.Intn(32))}}
+		c, d := IP{v4, uint128{0, uint64(prng.Intn(32))}}, IP{v4, uint128{0, uint64(prng.Intn(32))}}
+		if b.Less(a) {
+			a, b = b, a
+		}
+		if d.Less(c) {
+			c, d = d, c
+		}
+		x, y := Block{a, b}, Block{c, d}
+
+		r := x.Relation(y)
+		if (r == Equal) != (x == y) ||
+			(r == Covers) != x.Covers(y) ||
+			(r == CoveredBy) != y.Covers(x) ||
+			(r == OverlapsLeft || r == OverlapsRight) != x.Overlaps(y) ||
+			(r == DisjointBefore || r == DisjointAfter) != x.IsDisjunct(y) {
+			t.Fatalf("%v.Relation(%v) = %v, inconsistent", x, y, r)
+		}
+		if r == OverlapsLeft && !x.Less(y) || r == DisjointBefore && !x.Less(y) {
+			t.Fatalf("%v.Relation(%v) = %v, inconsistent with Less", x, y, r)
+		}
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	bs := []Block{
+		mustBlock("10.0.0.0/8"),
+		mustBlock("10.0.0.0-10.0.0.17"),
+		mustBlock("10.0.0.0/24"),
+		{},
+		mustBlock("10.0.0.5-10.0.0.20"),
+		mustBlock("10.0.0.0/8"),
+		mustBlock("::/0"),
+	}
+
+	want := []Conflict{
+		{I: 0, J: 5, A: bs[0], B: bs[5], Relation: Equal},
+		{I: 1, J: 4, A: bs[1], B: bs[4], Relation: OverlapsLeft},
+	}
+	if got := Conflicts(bs); !reflect.DeepEqual(got, want) {
+		t.Errorf("Conflicts, got %v, want %v", got, want)
+	}
+
+	// brute force
+	prng := rand.New(rand.NewSource(1))
+	for n := 0; n < 20; n++ {
+		bs := randBlocks(prng, 200)
+
+		var want []Conflict
+		for i := range bs {
+			for j := i + 1; j < len(bs); j++ {
+				switch r := bs[i].Relation(bs[j]); r {
+				case Equal, OverlapsLeft, OverlapsRight:
+					want = append(want, Conflict{I: i, J: j, A: bs[i], B: bs[j], Relation: r})
+				}
+			}
+		}
+
+		if got := Conflicts(bs); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Conflicts, got %d conflicts, want %d", len(got), len(want))
+		}
+	}
+}
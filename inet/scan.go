@@ -0,0 +1,42 @@
+package inet
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// Scan implements the fmt.Scanner interface for the verbs %v and %s, e.g.
+//
+//  fmt.Sscanf("from 10.0.0.1", "from %v", &ip)
+func (ip *IP) Scan(state fmt.ScanState, verb rune) error {
+	tok, err := scanToken(state, verb)
+	if err != nil {
+		return err
+	}
+	*ip, err = ParseIP(tok)
+	return err
+}
+
+// Scan implements the fmt.Scanner interface for the verbs %v and %s, e.g.
+//
+//  fmt.Sscanf("permit 10.0.0.0/8", "permit %v", &b)
+func (b *Block) Scan(state fmt.ScanState, verb rune) error {
+	tok, err := scanToken(state, verb)
+	if err != nil {
+		return err
+	}
+	*b, err = ParseBlock(tok)
+	return err
+}
+
+// scanToken returns the next space delimited token
+func scanToken(state fmt.ScanState, verb rune) (string, error) {
+	if verb != 'v' && verb != 's' {
+		return "", fmt.Errorf("bad verb '%%%c' for scanning", verb)
+	}
+	tok, err := state.Token(true, func(r rune) bool { return !unicode.IsSpace(r) })
+	if err != nil {
+		return "", err
+	}
+	return string(tok), nil
+}
@@ -0,0 +1,35 @@
+package inet
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	var b Block
+	var ip IP
+	var action string
+
+	n, err := fmt.Sscanf("permit 10.0.0.0/8 from 2001:db8::1", "%s %v from %s", &action, &b, &ip)
+	if err != nil || n != 3 {
+		t.Fatalf("Sscanf, got %d, %v", n, err)
+	}
+	if action != "permit" || b != mustBlock("10.0.0.0/8") || ip != mustIP("2001:db8::1") {
+		t.Errorf("Sscanf, got %s %v %v", action, b, ip)
+	}
+
+	// ranges are single tokens
+	if _, err := fmt.Sscan("  10.0.0.3-10.0.17.134\n", &b); err != nil || b != mustBlock("10.0.0.3-10.0.17.134") {
+		t.Errorf("Sscan range, got %v, %v", b, err)
+	}
+
+	if _, err := fmt.Sscan("10.0.0.0/33", &b); err == nil {
+		t.Errorf("Sscan invalid block, want error")
+	}
+	if _, err := fmt.Sscan("foo", &ip); err == nil {
+		t.Errorf("Sscan invalid IP, want error")
+	}
+	if _, err := fmt.Sscanf("10.0.0.1", "%d", &ip); err == nil {
+		t.Errorf("Sscanf %%d, want error")
+	}
+}
@@ -0,0 +1,57 @@
+package inet
+
+import "sort"
+
+// SortBlocks sorts the blocks in place in ascending order, see Block.Less.
+// Large slices are radix sorted on the uint128 keys, with a temporary buffer of the same size.
+func SortBlocks(bs []Block) {
+	if len(bs) < radixThreshold {
+		sort.Sort(blockSlice(bs))
+		return
+	}
+	radixSortBlocks(bs)
+}
+
+// SortIPs sorts the IP addresses in place in ascending order, see IP.Less.
+// Large slices are radix sorted on the uint128 keys, with a temporary buffer of the same size.
+func SortIPs(ips []IP) {
+	if len(ips) < radixThreshold {
+		sort.Sort(ipSlice(ips))
+		return
+	}
+	radixSortIPs(ips)
+}
+
+// BlocksAreSorted reports whether the blocks are sorted in ascending order, see Block.Less.
+func BlocksAreSorted(bs []Block) bool {
+	for i := len(bs) - 1; i > 0; i-- {
+		if bs[i].Less(bs[i-1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// IPsAreSorted reports whether the IP addresses are sorted in ascending order, see IP.Less.
+func IPsAreSorted(ips []IP) bool {
+	for i := len(ips) - 1; i > 0; i-- {
+		if ips[i].Less(ips[i-1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// blockSlice implements sort.Interface, faster than sort.Slice with a closure
+type blockSlice []Block
+
+func (x blockSlice) Len() int           { return len(x) }
+func (x blockSlice) Less(i, j int) bool { return x[i].Less(x[j]) }
+func (x blockSlice) Swap(i, j int)      { x[i], x[j] = x[j], x[i] }
+
+// ipSlice implements sort.Interface, faster than sort.Slice with a closure
+type ipSlice []IP
+
+func (x ipSlice) Len() int           { return len(x) }
+func (x ipSlice) Less(i, j int) bool { return x[i].Less(x[j]) }
+func (x ipSlice) Swap(i, j int)      { x[i], x[j] = x[j], x[i] }
@@ -0,0 +1,186 @@
+package inet
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// randBlocks, random v4 and v6 blocks, CIDRs and ranges
+func randBlocks(prng *rand.Rand, n int) []Block {
+	bs := make([]Block, 0, n)
+	for i := 0; i < n; i++ {
+		a, b := randIP(prng), randIP(prng)
+		if a.version != b.version {
+			b = a
+		}
+		if b.Less(a) {
+			a, b = b, a
+		}
+		bs = append(bs, Block{a, b})
+	}
+	return bs
+}
+
+// randCIDRs, random v4 and v6 CIDRs with realistic prefix lengths, like a BGP table
+func randCIDRs(prng *rand.Rand, n int) []Block {
+	bs := make([]Block, 0, n)
+	for i := 0; i < n; i++ {
+		ip := randIP(prng)
+		bits := 96 + 8 + prng.Intn(17)
+		if ip.version == v6 {
+			bits = 19 + prng.Intn(30)
+		}
+		mask := maskUint128[bits]
+		bs = append(bs, Block{ip.mkBaseIP(mask), ip.mkLastIP(mask)})
+	}
+	return bs
+}
+
+// randIP, random v4 or v6 address, v6 mostly with few significant bits
+func randIP(prng *rand.Rand) IP {
+	if prng.Intn(2) == 0 {
+		return IP{v4, uint128{0, uint64(prng.Uint32() >> uint(prng.Intn(32)))}}
+	}
+	return IP{v6, uint128{prng.Uint64() >> uint(prng.Intn(64)), prng.Uint64()}}
+}
+
+func TestSortBlocks(t *testing.T) {
+	prng := rand.New(rand.NewSource(1))
+
+	for _, n := range []int{0, 1, 2, 100, 10_000} {
+		bs := randBlocks(prng, n)
+
+		// duplicates and nested blocks
+		if n > 0 {
+			bs = append(bs, bs[0], Block{bs[0].base, bs[0].base})
+		}
+
+		want := make([]Block, len(bs))
+		copy(want, bs)
+		sort.Slice(want, func(i, j int) bool { return want[i].Less(want[j]) })
+
+		SortBlocks(bs)
+		if !reflect.DeepEqual(bs, want) {
+			t.Errorf("SortBlocks, n=%d, not equal to sort.Slice", n)
+		}
+		if !BlocksAreSorted(bs) {
+			t.Errorf("BlocksAreSorted, n=%d, want true", n)
+		}
+		if n > 1 && bs[0] != bs[len(bs)-1] {
+			bs[0], bs[len(bs)-1] = bs[len(bs)-1], bs[0]
+			if BlocksAreSorted(bs) {
+				t.Errorf("BlocksAreSorted, n=%d, want false", n)
+			}
+		}
+	}
+}
+
+func TestSortIPs(t *testing.T) {
+	prng := rand.New(rand.NewSource(1))
+
+	for _, n := range []int{0, 1, 2, 100, 10_000} {
+		ips := make([]IP, 0, n)
+		for i := 0; i < n; i++ {
+			ips = append(ips, randIP(prng))
+		}
+
+		want := make([]IP, len(ips))
+		copy(want, ips)
+		sort.Slice(want, func(i, j int) bool { return want[i].Less(want[j]) })
+
+		SortIPs(ips)
+		if !reflect.DeepEqual(ips, want) {
+			t.Errorf("SortIPs, n=%d, not equal to sort.Slice", n)
+		}
+		if !IPsAreSorted(ips) {
+			t.Errorf("IPsAreSorted, n=%d, want true", n)
+		}
+		if n > 1 {
+			ips[0], ips[len(ips)-1] = ips[len(ips)-1], ips[0]
+			if IPsAreSorted(ips) {
+				t.Errorf("IPsAreSorted, n=%d, want false", n)
+			}
+		}
+	}
+}
+
+func TestRadixSort(t *testing.T) {
+	prng := rand.New(rand.NewSource(2))
+
+	// below the threshold too, the radix sort must work for all lengths > 0
+	for _, n := range []int{1, 2, 17, 1000} {
+		bs := append(randBlocks(prng, n), randCIDRs(prng, n)...)
+		bs = append(bs, Block{}, bs[0], Block{bs[0].base, bs[0].base})
+		radixSortBlocks(bs)
+		if !BlocksAreSorted(bs) {
+			t.Errorf("radixSortBlocks, n=%d, not sorted", n)
+		}
+
+		ips := make([]IP, 0, n)
+		for i := 0; i < n; i++ {
+			ips = append(ips, randIP(prng))
+		}
+		ips = append(ips, IP{}, ips[0])
+		radixSortIPs(ips)
+		if !IPsAreSorted(ips) {
+			t.Errorf("radixSortIPs, n=%d, not sorted", n)
+		}
+	}
+}
+
+func BenchmarkSortBlocks(b *testing.B) {
+	for _, n := range []int{1_000, 10_000, 100_000, 1_000_000} {
+		prng := rand.New(rand.NewSource(1))
+		bs := randCIDRs(prng, n)
+		buf := make([]Block, n)
+
+		b.Run(fmt.Sprintf("sort.Slice/n=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				copy(buf, bs)
+				sort.Slice(buf, func(i, j int) bool { return buf[i].Less(buf[j]) })
+			}
+		})
+
+		b.Run(fmt.Sprintf("sort.Sort/n=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				copy(buf, bs)
+				sort.Sort(blockSlice(buf))
+			}
+		})
+
+		b.Run(fmt.Sprintf("radix/n=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				copy(buf, bs)
+				radixSortBlocks(buf)
+			}
+		})
+	}
+}
+
+func BenchmarkSortIPs(b *testing.B) {
+	for _, n := range []int{1_000, 10_000, 100_000, 1_000_000} {
+		prng := rand.New(rand.NewSource(1))
+		ips := make([]IP, 0, n)
+		for i := 0; i < n; i++ {
+			ips = append(ips, randIP(prng))
+		}
+		buf := make([]IP, n)
+
+		b.Run(fmt.Sprintf("sort.Slice/n=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				copy(buf, ips)
+				sort.Slice(buf, func(i, j int) bool { return buf[i].Less(buf[j]) })
+			}
+		})
+
+		b.Run(fmt.Sprintf("radix/n=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				copy(buf, ips)
+				radixSortIPs(buf)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package inet
+
+// Special is an entry of the IANA IPv4 and IPv6 special-purpose address registries.
+type Special struct {
+	Block Block
+	Name  string
+	RFC   string
+
+	// Global reports whether the addresses are globally reachable.
+	Global bool
+}
+
+// String returns the block, name and RFC of the entry.
+func (s Special) String() string {
+	return s.Block.String() + " " + s.Name + " [" + s.RFC + "]"
+}
+
+// specials, the IANA special-purpose address registries, sorted.
+//
+// The IPv4-mapped addresses ::ffff:0:0/96 are missing on purpose,
+// they are stripped down to plain IPv4 by this package.
+var specials = []Special{
+	{mustParseBlock("0.0.0.0/8"), "This network", "RFC791", false},
+	{mustParseBlock("0.0.0.0/32"), "This host on this network", "RFC1122", false},
+	{mustParseBlock("10.0.0.0/8"), "Private-Use", "RFC1918", false},
+	{mustParseBlock("100.64.0.0/10"), "Shared Address Space", "RFC6598", false},
+	{mustParseBlock("127.0.0.0/8"), "Loopback", "RFC1122", false},
+	{mustParseBlock("169.254.0.0/16"), "Link Local", "RFC3927", false},
+	{mustParseBlock("172.16.0.0/12"), "Private-Use", "RFC1918", false},
+	{mustParseBlock("192.0.0.0/24"), "IETF Protocol Assignments", "RFC6890", false},
+	{mustParseBlock("192.0.0.0/29"), "IPv4 Service Continuity Prefix", "RFC7335", false},
+	{mustParseBlock("192.0.0.8/32"), "IPv4 dummy address", "RFC7600", false},
+	{mustParseBlock("192.0.0.9/32"), "Port Control Protocol Anycast", "RFC7723", true},
+	{mustParseBlock("192.0.0.10/32"), "Traversal Using Relays around NAT Anycast", "RFC8155", true},
+	{mustParseBlock("192.0.0.170/32"), "NAT64/DNS64 Discovery", "RFC8880", false},
+	{mustParseBlock("192.0.0.171/32"), "NAT64/DNS64 Discovery", "RFC8880", false},
+	{mustParseBlock("192.0.2.0/24"), "Documentation (TEST-NET-1)", "RFC5737", false},
+	{mustParseBlock("192.31.196.0/24"), "AS112-v4", "RFC7535", true},
+	{mustParseBlock("192.52.193.0/24"), "AMT", "RFC7450", true},
+	{mustParseBlock("192.88.99.0/24"), "Deprecated (6to4 Relay Anycast)", "RFC7526", false},
+	{mustParseBlock("192.168.0.0/16"), "Private-Use", "RFC1918", false},
+	{mustParseBlock("192.175.48.0/24"), "Direct Delegation AS112 Service", "RFC7534", true},
+	{mustParseBlock("198.18.0.0/15"), "Benchmarking", "RFC2544", false},
+	{mustParseBlock("198.51.100.0/24"), "Documentation (TEST-NET-2)", "RFC5737", false},
+	{mustParseBlock("203.0.113.0/24"), "Documentation (TEST-NET-3)", "RFC5737", false},
+	{mustParseBlock("240.0.0.0/4"), "Reserved", "RFC1112", false},
+	{mustParseBlock("255.255.255.255/32"), "Limited Broadcast", "RFC8190", false},
+	//
+	{mustParseBlock("::/128"), "Unspecified Address", "RFC4291", false},
+	{mustParseBlock("::1/128"), "Loopback Address", "RFC4291", false},
+	{mustParseBlock("64:ff9b::/96"), "IPv4-IPv6 Translat.", "RFC6052", true},
+	{mustParseBlock("64:ff9b:1::/48"), "IPv4-IPv6 Translat.", "RFC8215", false},
+	{mustParseBlock("100::/64"), "Discard-Only Address Block", "RFC6666", false},
+	{mustParseBlock("2001::/23"), "IETF Protocol Assignments", "RFC2928", false},
+	{mustParseBlock("2001::/32"), "TEREDO", "RFC4380", false},
+	{mustParseBlock("2001:1::1/128"), "Port Control Protocol Anycast", "RFC7723", true},
+	{mustParseBlock("2001:1::2/128"), "Traversal Using Relays around NAT Anycast", "RFC8155", true},
+	{mustParseBlock("2001:2::/48"), "Benchmarking", "RFC5180", false},
+	{mustParseBlock("2001:3::/32"), "AMT", "RFC7450", true},
+	{mustParseBlock("2001:4:112::/48"), "AS112-v6", "RFC7535", true},
+	{mustParseBlock("2001:10::/28"), "Deprecated (previously ORCHID)", "RFC4843", false},
+	{mustParseBlock("2001:20::/28"), "ORCHIDv2", "RFC7343", true},
+	{mustParseBlock("2001:db8::/32"), "Documentation", "RFC3849", false},
+	{mustParseBlock("2002::/16"), "6to4", "RFC3056", false},
+	{mustParseBlock("2620:4f:8000::/48"), "Direct Delegation AS112 Service", "RFC7534", true},
+	{mustParseBlock("3fff::/20"), "Documentation", "RFC9637", false},
+	{mustParseBlock("fc00::/7"), "Unique-Local", "RFC4193", false},
+	{mustParseBlock("fe80::/10"), "Link-Local Unicast", "RFC4291", false},
+}
+
+var (
+	loopbackBlocks = []Block{
+		mustParseBlock("127.0.0.0/8"),
+		mustParseBlock("::1/128"),
+	}
+	privateBlocks = []Block{
+		mustParseBlock("10.0.0.0/8"),
+		mustParseBlock("172.16.0.0/12"),
+		mustParseBlock("192.168.0.0/16"),
+		mustParseBlock("fc00::/7"),
+	}
+	cgnatBlocks = []Block{
+		mustParseBlock("100.64.0.0/10"),
+	}
+	linkLocalBlocks = []Block{
+		mustParseBlock("169.254.0.0/16"),
+		mustParseBlock("fe80::/10"),
+	}
+	multicastBlocks = []Block{
+		mustParseBlock("224.0.0.0/4"),
+		mustParseBlock("ff00::/8"),
+	}
+	documentationBlocks = []Block{
+		mustParseBlock("192.0.2.0/24"),
+		mustParseBlock("198.51.100.0/24"),
+		mustParseBlock("203.0.113.0/24"),
+		mustParseBlock("2001:db8::/32"),
+		mustParseBlock("3fff::/20"),
+	}
+)
+
+// mustParseBlock for the package tables, panics on invalid input.
+func mustParseBlock(s string) Block {
+	b, err := ParseBlock(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// containedIn reports whether b is equal to or covered by any of the blocks in bs.
+func (b Block) containedIn(bs []Block) bool {
+	for _, c := range bs {
+		if c == b || c.Covers(b) {
+			return true
+		}
+	}
+	return false
+}
+
+// SpecialPurpose returns the most specific entry of the IANA special-purpose
+// address registries equal to or covering b.
+func SpecialPurpose(b Block) (Special, bool) {
+	var match Special
+	var ok bool
+	for _, s := range specials {
+		// sorted, later matches are more specific
+		if s.Block == b || s.Block.Covers(b) {
+			match, ok = s, true
+		}
+	}
+	return match, ok
+}
+
+// IsLoopback reports whether ip is a loopback address, 127.0.0.0/8 or ::1.
+func (ip IP) IsLoopback() bool {
+	return ip.IsValid() && Block{ip, ip}.containedIn(loopbackBlocks)
+}
+
+// IsPrivate reports whether ip is a private address, RFC 1918 or RFC 4193 (ULA).
+// The shared address space for carrier-grade NAT isn't private, see IsCGNAT.
+func (ip IP) IsPrivate() bool {
+	return ip.IsValid() && Block{ip, ip}.containedIn(privateBlocks)
+}
+
+// IsCGNAT reports whether ip is in the shared address space for carrier-grade NAT, 100.64.0.0/10 (RFC 6598).
+// The space is neither private nor global, ISPs use it between the CGN and the subscriber.
+func (ip IP) IsCGNAT() bool {
+	return ip.IsValid() && Block{ip, ip}.containedIn(cgnatBlocks)
+}
+
+// IsLinkLocal reports whether ip is a link-local unicast address, 169.254.0.0/16 or fe80::/10.
+func (ip IP) IsLinkLocal() bool {
+	return ip.IsValid() && Block{ip, ip}.containedIn(linkLocalBlocks)
+}
+
+// IsMulticast reports whether ip is a multicast address, 224.0.0.0/4 or ff00::/8.
+func (ip IP) IsMulticast() bool {
+	return ip.IsValid() && Block{ip, ip}.containedIn(multicastBlocks)
+}
+
+// IsDocumentation reports whether ip is reserved for documentation, RFC 5737, RFC 3849 and RFC 9637.
+func (ip IP) IsDocumentation() bool {
+	return ip.IsValid() && Block{ip, ip}.containedIn(documentationBlocks)
+}
+
+// IsGlobal reports whether ip is a globally reachable unicast address.
+// The most specific entry of the special-purpose registries decides, see SpecialPurpose.
+func (ip IP) IsGlobal() bool {
+	if !ip.IsValid() || ip.IsMulticast() {
+		return false
+	}
+	if s, ok := SpecialPurpose(Block{ip, ip}); ok {
+		return s.Global
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package inet
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestSpecialsSorted(t *testing.T) {
+	if !sort.SliceIsSorted(specials, func(i, j int) bool { return specials[i].Block.Less(specials[j].Block) }) {
+		t.Errorf("special-purpose registry table isn't sorted")
+	}
+}
+
+func TestSpecialPurpose(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"10.1.2.3", "10.0.0.0/8", true},
+		{"192.0.0.9", "192.0.0.9/32", true},
+		{"192.0.0.200", "192.0.0.0/24", true},
+		{"0.0.0.0", "0.0.0.0/32", true},
+		{"2001:1::1", "2001:1::1/128", true},
+		{"2001:1::3", "2001::/23", true},
+		{"2001:db8::/48", "2001:db8::/32", true},
+		{"8.8.8.8", "", false},
+		{"2001:db8::/31", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := SpecialPurpose(mustBlock(tt.in))
+		if ok != tt.ok {
+			t.Errorf("SpecialPurpose(%v), got ok %v, want %v", tt.in, ok, tt.ok)
+			continue
+		}
+		if ok && got.Block != mustBlock(tt.want) {
+			t.Errorf("SpecialPurpose(%v), got %v, want %v", tt.in, got.Block, tt.want)
+		}
+	}
+}
+
+func TestClassification(t *testing.T) {
+	tests := []struct {
+		in                                                string
+		loopback, private, linkLocal, multicast, doc, glb bool
+	}{
+		{"127.0.0.1", true, false, false, false, false, false},
+		{"::1", true, false, false, false, false, false},
+		{"172.31.255.255", false, true, false, false, false, false},
+		{"172.32.0.0", false, false, false, false, false, true},
+		{"fd00::1", false, true, false, false, false, false},
+		{"169.254.1.1", false, false, true, false, false, false},
+		{"fe80::1", false, false, true, false, false, false},
+		{"239.1.2.3", false, false, false, true, false, false},
+		{"ff02::1", false, false, false, true, false, false},
+		{"203.0.113.7", false, false, false, false, true, false},
+		{"2001:db8::1", false, false, false, false, true, false},
+		{"192.0.0.9", false, false, false, false, false, true},
+		{"134.60.1.1", false, false, false, false, false, true},
+		{"2001:7c0::1", false, false, false, false, false, true},
+	}
+
+	for _, tt := range tests {
+		ip := mustIP(tt.in)
+		if got := ip.IsLoopback(); got != tt.loopback {
+			t.Errorf("(%v).IsLoopback(), got %v, want %v", ip, got, tt.loopback)
+		}
+		if got := ip.IsPrivate(); got != tt.private {
+			t.Errorf("(%v).IsPrivate(), got %v, want %v", ip, got, tt.private)
+		}
+		if got := ip.IsLinkLocal(); got != tt.linkLocal {
+			t.Errorf("(%v).IsLinkLocal(), got %v, want %v", ip, got, tt.linkLocal)
+		}
+		if got := ip.IsMulticast(); got != tt.multicast {
+			t.Errorf("(%v).IsMulticast(), got %v, want %v", ip, got, tt.multicast)
+		}
+		if got := ip.IsDocumentation(); got != tt.doc {
+			t.Errorf("(%v).IsDocumentation(), got %v, want %v", ip, got, tt.doc)
+		}
+		if got := ip.IsGlobal(); got != tt.glb {
+			t.Errorf("(%v).IsGlobal(), got %v, want %v", ip, got, tt.glb)
+		}
+	}
+
+	for _, tt := range []struct {
+		in          string
+		cgnat, priv bool
+	}{
+		{"100.64.0.0", true, false},
+		{"100.127.255.255", true, false},
+		{"100.63.255.255", false, false},
+		{"100.128.0.0", false, false},
+		{"10.0.0.1", false, true},
+		{"::ffff:100.64.0.1", true, false},
+		{"64:ff9b::100.64.0.1", false, false},
+	} {
+		ip := mustIP(tt.in)
+		if got := ip.IsCGNAT(); got != tt.cgnat {
+			t.Errorf("(%v).IsCGNAT(), got %v, want %v", ip, got, tt.cgnat)
+		}
+		if got := ip.IsPrivate(); got != tt.priv {
+			t.Errorf("(%v).IsPrivate(), got %v, want %v", ip, got, tt.priv)
+		}
+		if ip.IsGlobal() && tt.cgnat {
+			t.Errorf("(%v).IsGlobal(), CGNAT space isn't global", ip)
+		}
+	}
+
+	var ip IP
+	if ip.IsGlobal() || ip.IsPrivate() || ip.IsLoopback() || ip.IsCGNAT() {
+		t.Errorf("classification of invalid IP must be false")
+	}
+}
@@ -0,0 +1,152 @@
+package inet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ExpandTargets expands the nmap-style target spec to the list of IP addresses, e.g.
+//
+//  10.0.0.1-20, 10.0.0.0/29 192.168.0-3.1,2001:db8::1-2001:db8::f
+//
+// The targets are separated by commas or whitespace, each one is a block, see ParseBlock,
+// or an IPv4 address with octet ranges "a-b" or "*" for 0-255, e.g. "10.0.0.1-20" or "10.0.*.1".
+//
+// The addresses are returned in spec order, duplicates removed. Returns an error wrapping ErrLimit
+// if the spec expands to more than limit addresses, counted with duplicates, before allocating any.
+// The limit must be positive.
+func ExpandTargets(spec string, limit int) ([]IP, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid target limit: %d", limit)
+	}
+
+	var targets []target
+	total := 0
+	for _, tok := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		t, err := parseTarget(tok)
+		if err != nil {
+			return nil, err
+		}
+
+		n, ok := t.count(limit - total)
+		if !ok {
+			return nil, fmt.Errorf("%w: targets %q exceed %d addresses", ErrLimit, spec, limit)
+		}
+		total += n
+		targets = append(targets, t)
+	}
+
+	ips := make([]IP, 0, total)
+	seen := make(map[IP]bool, total)
+	for _, t := range targets {
+		t.each(func(ip IP) {
+			if !seen[ip] {
+				seen[ip] = true
+				ips = append(ips, ip)
+			}
+		})
+	}
+	return ips, nil
+}
+
+// target, a block or IPv4 octet ranges
+type target struct {
+	block  Block
+	octets [4][2]int
+}
+
+// parseTarget parses a block or else IPv4 octet ranges
+func parseTarget(tok string) (target, error) {
+	if b, err := ParseBlock(tok); err == nil {
+		return target{block: b}, nil
+	}
+
+	var t target
+	parts := strings.Split(tok, ".")
+	if len(parts) != 4 {
+		return target{}, fmt.Errorf("invalid target: %q", tok)
+	}
+	for i, part := range parts {
+		lo, hi, ok := parseOctetRange(part)
+		if !ok {
+			return target{}, fmt.Errorf("invalid target: %q", tok)
+		}
+		t.octets[i] = [2]int{lo, hi}
+	}
+	return t, nil
+}
+
+// parseOctetRange parses "a", "a-b" or "*"
+func parseOctetRange(s string) (lo, hi int, ok bool) {
+	if s == "*" {
+		return 0, 255, true
+	}
+
+	a, b := s, s
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		a, b = s[:i], s[i+1:]
+	}
+
+	if !isDigits(a) || !isDigits(b) {
+		return 0, 0, false
+	}
+
+	var err1, err2 error
+	lo, err1 = strconv.Atoi(a)
+	hi, err2 = strconv.Atoi(b)
+	if err1 != nil || err2 != nil || hi > 255 || lo > hi {
+		return 0, 0, false
+	}
+	return lo, hi, true
+}
+
+// isDigits reports whether s is a non-empty decimal number, no signs
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// count returns the number of addresses of t, ok is false if they exceed max
+func (t target) count(max int) (n int, ok bool) {
+	if t.block.IsValid() {
+		size := t.block.last.sub(t.block.base.uint128)
+		if size.hi != 0 || size.lo >= uint64(max) {
+			return 0, false
+		}
+		return int(size.lo) + 1, true
+	}
+
+	// up to 2^32 addresses, no overflow with 32 bit ints
+	var u uint64 = 1
+	for _, o := range t.octets {
+		u *= uint64(o[1] - o[0] + 1)
+	}
+	if u > uint64(max) {
+		return 0, false
+	}
+	return int(u), true
+}
+
+// each calls fn for all addresses of t in ascending order
+func (t target) each(fn func(IP)) {
+	if t.block.IsValid() {
+		for ip := t.block.base; ; ip = ip.addOne() {
+			fn(ip)
+			if ip == t.block.last {
+				return
+			}
+		}
+	}
+
+	o := t.octets
+	for a := o[0][0]; a <= o[0][1]; a++ {
+		for b := o[1][0]; b <= o[1][1]; b++ {
+			for c := o[2][0]; c <= o[2][1]; c++ {
+				for d := o[3][0]; d <= o[3][1]; d++ {
+					fn(IP{v4, uint128{0, uint64(a<<24 | b<<16 | c<<8 | d)}})
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package inet
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExpandTargets(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{"10.0.0.1-3", []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+		{"10.0.0.0/30", []string{"10.0.0.0", "10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+		{"10.0.0.1,10.0.0.9 10.0.0.5", []string{"10.0.0.1", "10.0.0.9", "10.0.0.5"}},
+		{"10.0.0.255-10.0.1.0", []string{"10.0.0.255", "10.0.1.0"}},
+		{"10.0-1.5.1-2", []string{"10.0.5.1", "10.0.5.2", "10.1.5.1", "10.1.5.2"}},
+		{"2001:db8::1-2001:db8::2, ::1", []string{"2001:db8::1", "2001:db8::2", "::1"}},
+		// duplicates removed
+		{"10.0.0.2, 10.0.0.1-3, 10.0.0.0/31", []string{"10.0.0.2", "10.0.0.1", "10.0.0.3", "10.0.0.0"}},
+		{"", []string{}},
+	}
+
+	for _, tt := range tests {
+		ips, err := ExpandTargets(tt.spec, 16)
+		if err != nil {
+			t.Errorf("ExpandTargets(%q), unexpected error: %v", tt.spec, err)
+			continue
+		}
+		got := []string{}
+		for _, ip := range ips {
+			got = append(got, ip.String())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ExpandTargets(%q), got %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+
+	if ips, err := ExpandTargets("10.0.*.1", 256); err != nil || len(ips) != 256 || ips[255] != mustIP("10.0.255.1") {
+		t.Errorf("ExpandTargets(10.0.*.1), got %d addresses, %v", len(ips), err)
+	}
+}
+
+func TestExpandTargetsFail(t *testing.T) {
+	limits := []string{
+		"10.0.0.0/27",
+		"10.0.0.0/28, 10.0.0.16",
+		"::/0",
+		"*.*.*.*",
+		"10.0.0.0/31, 10.0.0.0/31, 10.0.0.0/31, 10.0.0.0/31, 10.0.0.0/31, 10.0.0.0/31, 10.0.0.0/31, 10.0.0.0/31, 10.0.0.0",
+	}
+	for _, spec := range limits {
+		if _, err := ExpandTargets(spec, 16); !errors.Is(err, ErrLimit) {
+			t.Errorf("ExpandTargets(%q), got %v, want ErrLimit", spec, err)
+		}
+	}
+
+	invalid := []string{
+		"10.0.0.1-",
+		"10.0.0.3-1",
+		"10.0.0.256",
+		"10.0.0.+1",
+		"10.0.0",
+		"10.0.0.1.1",
+		"example.com",
+		"2001:db8::1-f",
+	}
+	for _, spec := range invalid {
+		if _, err := ExpandTargets(spec, 16); err == nil || errors.Is(err, ErrLimit) {
+			t.Errorf("ExpandTargets(%q), got %v, want invalid target error", spec, err)
+		}
+	}
+
+	if _, err := ExpandTargets("10.0.0.1", 0); err == nil {
+		t.Errorf("ExpandTargets, limit 0, want error")
+	}
+}
@@ -0,0 +1,75 @@
+package inet
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvariant is wrapped by the errors of the Verify functions.
+var ErrInvariant = errors.New("invariant violated")
+
+// VerifyIPInvariants checks the internal invariants of ip: a known IP version
+// and IPv4 addresses within 32 bits. The zero value is reported as error.
+//
+// Useful after unsafe deserialization, e.g. from binary snapshots or databases.
+// The values returned by the parse functions and constructors are always consistent.
+func VerifyIPInvariants(ip IP) error {
+	switch ip.version {
+	case v4:
+		if ip.hi != 0 || ip.lo>>32 != 0 {
+			return fmt.Errorf("%w: IPv4 address beyond 32 bits", ErrInvariant)
+		}
+	case v6:
+		// all bits valid
+	case 0:
+		if ip.uint128 == (uint128{}) {
+			return fmt.Errorf("%w: zero value IP", ErrInvariant)
+		}
+		return fmt.Errorf("%w: IP without version", ErrInvariant)
+	default:
+		return fmt.Errorf("%w: unknown IP version %d", ErrInvariant, ip.version)
+	}
+	return nil
+}
+
+// VerifyBlockInvariants checks the internal invariants of b: valid base and last IPs
+// of the same IP version, base <= last, and the String form parses back to b.
+// The zero value is reported as error, see also VerifyIPInvariants.
+func VerifyBlockInvariants(b Block) error {
+	if !b.IsValid() {
+		return fmt.Errorf("%w: zero value Block", ErrInvariant)
+	}
+	if err := VerifyIPInvariants(b.base); err != nil {
+		return fmt.Errorf("base: %w", err)
+	}
+	if err := VerifyIPInvariants(b.last); err != nil {
+		return fmt.Errorf("last: %w", err)
+	}
+	if b.base.version != b.last.version {
+		return fmt.Errorf("%w: IP versions of base and last differ", ErrInvariant)
+	}
+	if b.last.Less(b.base) {
+		return fmt.Errorf("%w: base > last, %v-%v", ErrInvariant, b.base, b.last)
+	}
+
+	// round trip, the CIDR or range form
+	if c, err := ParseBlock(b.String()); err != nil || c != b {
+		return fmt.Errorf("%w: %v doesn't round trip", ErrInvariant, b)
+	}
+	return nil
+}
+
+// VerifyBlocksInvariants checks the invariants of all blocks in bs, see VerifyBlockInvariants.
+// The violations are returned as *ListError, prefixed with the index in bs.
+func VerifyBlocksInvariants(bs []Block) error {
+	var errs []error
+	for i, b := range bs {
+		if err := VerifyBlockInvariants(b); err != nil {
+			errs = append(errs, fmt.Errorf("block %d: %w", i, err))
+		}
+	}
+	if errs != nil {
+		return &ListError{Errs: errs}
+	}
+	return nil
+}
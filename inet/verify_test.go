@@ -0,0 +1,43 @@
+package inet
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVerifyInvariants(t *testing.T) {
+	valid := []Block{
+		mustBlock("10.0.0.0/8"),
+		mustBlock("10.0.0.3-10.0.0.17"),
+		mustBlock("10.0.0.1"),
+		mustBlock("::/0"),
+		mustBlock("2001:db8::1-2001:db8::ff"),
+	}
+	if err := VerifyBlocksInvariants(valid); err != nil {
+		t.Errorf("VerifyBlocksInvariants, unexpected error: %v", err)
+	}
+
+	ip4 := mustIP("10.0.0.1")
+	ip6 := mustIP("2001:db8::1")
+
+	invalid := []Block{
+		{},
+		{ip4, IP{}},
+		{IP{v4, uint128{0, 1 << 32}}, IP{v4, uint128{0, 1<<32 + 1}}},
+		{IP{7, uint128{0, 1}}, IP{7, uint128{0, 2}}},
+		{IP{0, uint128{0, 1}}, ip4},
+		{ip4, ip6},
+		{mustIP("10.0.0.2"), ip4},
+	}
+	for _, b := range invalid {
+		if err := VerifyBlockInvariants(b); !errors.Is(err, ErrInvariant) {
+			t.Errorf("VerifyBlockInvariants(%#v), got %v, want ErrInvariant", b, err)
+		}
+	}
+
+	err := VerifyBlocksInvariants(append(valid, invalid...))
+	var le *ListError
+	if !errors.As(err, &le) || len(le.Errs) != len(invalid) {
+		t.Errorf("VerifyBlocksInvariants, got %v, want %d errors", err, len(invalid))
+	}
+}
@@ -0,0 +1,80 @@
+package inetio_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/inetio"
+)
+
+func ExampleReadBlocksCSV() {
+	in := `# block, text
+10.0.0.0/8, private
+192.168.0.0/16, private
+10.0.0.0/8, duplicate
+fe80::/10, link-local
+10.0.0.0/33, invalid
+`
+	recs, errs := inetio.ReadBlocksCSV(strings.NewReader(in), inetio.WithDuplicates(inetio.RejectDups))
+
+	for _, r := range recs {
+		fmt.Printf("%d: %-14v %s\n", r.Line, r.Block, r.Text)
+	}
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// 2: 10.0.0.0/8     private
+	// 3: 192.168.0.0/16 private
+	// 5: fe80::/10      link-local
+	// inetio: line 4: duplicate block: 10.0.0.0/8, see line 2
+	// inetio: line 6: invalid Block: 10.0.0.0/33
+}
+
+func ExampleWriteTable() {
+	in := `10.0.0.0/8, private
+192.168.0.0/16, private
+10.0.0.3-10.0.17.134, a range
+2001:db8::/32, documentation
+`
+	recs, _ := inetio.ReadBlocksCSV(strings.NewReader(in))
+
+	_ = inetio.WriteTable(os.Stdout, recs, inetio.BlockColumn, inetio.MaskColumn, inetio.SizeColumn, inetio.TextColumn)
+	fmt.Println()
+	_ = inetio.WriteMarkdown(os.Stdout, recs[:2], inetio.ExpandedColumn, inetio.TextColumn)
+
+	// Output:
+	// Block                 Mask                           Size  Description
+	// 10.0.0.0/8              /8                       16777216  private
+	// 192.168.0.0/16         /16                          65536  private
+	// 10.0.0.3-10.0.17.134                                 4484  a range
+	// 2001:db8::/32          /32  79228162514264337593543950336  documentation
+	//
+	// | Block              | Description |
+	// | ------------------ | ----------- |
+	// | 010.000.000.000/8  | private     |
+	// | 192.168.000.000/16 | private     |
+}
+
+func ExampleWritePrefixList() {
+	in := `10.0.0.0/24, lan
+10.0.1.0/24, lan
+10.0.1.128/25, dmz
+2001:db8::/48, lan
+`
+	recs, _ := inetio.ReadBlocksCSV(strings.NewReader(in))
+
+	var bs []inet.Block
+	for _, r := range recs {
+		bs = append(bs, r.Block)
+	}
+
+	_ = inetio.WritePrefixList(os.Stdout, inetio.FRR, "CUSTOMER", inetio.PrefixEntries(bs, true))
+
+	// Output:
+	// ip prefix-list CUSTOMER seq 5 permit 10.0.0.0/23 le 25
+	// ipv6 prefix-list CUSTOMER seq 5 permit 2001:db8::/48
+}
@@ -0,0 +1,160 @@
+package inetio
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/inettree"
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+// ReadRangesCSV reads range records from r, one record per line:
+//
+//  start_ip, end_ip, payload...
+//
+// as used by GeoIP-style databases, e.g. IP2Location. The addresses are parsed as
+// IP strings or as decimal numbers, the end number decides the IP version of the row,
+// below 2^32 IPv4 addresses, IPv4-mapped IPv6 numbers are IPv4 addresses too.
+//
+// The options are the same as for ReadBlocksCSV, WithBlockColumn sets the start column,
+// the end column follows. For the "network, payload..." format, e.g. the MaxMind GeoLite CSV,
+// use ReadBlocksCSV with WithHeader.
+func ReadRangesCSV(r io.Reader, opts ...Option) ([]Record, []ParseError) {
+	c := newConfig(opts)
+	return c.read(r, c.parseRange)
+}
+
+// parseRange parses a single CSV line with start and end column
+func (c config) parseRange(s string) (Record, error) {
+	fields, err := c.split(s)
+	if err != nil {
+		return Record{}, err
+	}
+
+	if c.blockCol < 0 || c.blockCol+1 >= len(fields) {
+		return Record{}, fmt.Errorf("missing range columns %d, %d", c.blockCol, c.blockCol+1)
+	}
+
+	base, last, err := parseAddrs(fields[c.blockCol], fields[c.blockCol+1])
+	if err != nil {
+		return Record{}, err
+	}
+
+	block, err := inet.BlockFromRange(base, last)
+	if err != nil {
+		return Record{}, err
+	}
+
+	text, err := c.text(fields, c.blockCol+2)
+	if err != nil {
+		return Record{}, err
+	}
+
+	return Record{Block: block, Text: text, Fields: fields}, nil
+}
+
+// maxUint128, the largest IPv6 address as number
+var maxUint128 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+
+// parseAddrs parses start and end as IP strings or decimal numbers.
+// The IP version of numbers is decided by the end, the IPv6 databases start with 0.
+func parseAddrs(start, end string) (base, last inet.IP, err error) {
+	m, err := parseNumber(end)
+	if err != nil {
+		return
+	}
+
+	// IPv4 below 2^32, else IPv6
+	size := 4
+	if m != nil && m.BitLen() > 32 {
+		size = 16
+	}
+
+	if base, err = parseAddr(start, size); err != nil {
+		return
+	}
+	last, err = parseAddr(end, size)
+	return
+}
+
+// parseNumber parses s as decimal number, nil if s is no number
+func parseNumber(s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, nil
+	}
+	if n.Sign() < 0 || n.Cmp(maxUint128) > 0 {
+		return nil, fmt.Errorf("invalid IP: %v, number out of range", s)
+	}
+	return n, nil
+}
+
+// parseAddr parses s as IP string or decimal number of size bytes,
+// FromStdIP handles the IPv4-mapped addresses
+func parseAddr(s string, size int) (inet.IP, error) {
+	n, err := parseNumber(s)
+	if err != nil {
+		return inet.IP{}, err
+	}
+	if n == nil {
+		return inet.ParseIP(s)
+	}
+
+	if n.BitLen() > 8*size {
+		return inet.IP{}, fmt.Errorf("invalid IP: %v, number out of range", s)
+	}
+	return inet.FromStdIP(n.FillBytes(make(net.IP, size)))
+}
+
+// DB is a ready lookup table for GeoIP-style databases, the records must not overlap partially.
+type DB struct {
+	matcher *inettree.Matcher
+	records map[inet.Block]Record
+}
+
+// NewDB returns the DB for the records, see ReadBlocksCSV and ReadRangesCSV.
+// Returns an error on duplicate or partially overlapping blocks, duplicates wrap ErrDuplicate.
+func NewDB(recs []Record) (*DB, error) {
+	db := &DB{records: make(map[inet.Block]Record, len(recs))}
+
+	items := make([]tree.Interface, 0, len(recs))
+	for _, r := range recs {
+		// the map and the tree must agree on the record, reject instead of picking one
+		if prev, ok := db.records[r.Block]; ok {
+			return nil, fmt.Errorf("%w: %v, see line %d", ErrDuplicate, r.Block, prev.Line)
+		}
+		items = append(items, inettree.Item{Block: r.Block, Text: r.Text})
+		db.records[r.Block] = r
+	}
+
+	t, err := tree.New(items)
+	if err != nil {
+		return nil, err
+	}
+	db.matcher = inettree.Compile(t)
+	return db, nil
+}
+
+// Lookup returns the most specific record covering ip.
+// ok is false if ip isn't covered by any record.
+func (db *DB) Lookup(ip inet.IP) (rec Record, ok bool) {
+	if db == nil {
+		return
+	}
+	item, ok := db.matcher.Match(ip)
+	if !ok {
+		return
+	}
+	return db.records[item.Block], true
+}
+
+// Len returns the number of records in db.
+func (db *DB) Len() int {
+	if db == nil {
+		return 0
+	}
+	return len(db.records)
+}
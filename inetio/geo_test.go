@@ -0,0 +1,117 @@
+package inetio
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+)
+
+func mustIP(s string) inet.IP {
+	ip, err := inet.ParseIP(s)
+	if err != nil {
+		panic(err)
+	}
+	return ip
+}
+
+func TestReadRangesCSV(t *testing.T) {
+	// IP2Location style, quoted decimal numbers, IPv4 and IPv4-mapped in the IPv6 database
+	in := `"16777216","16777471","AU","Australia"
+"281470698520832","281470698521087","CN","China"
+"42540766411282592856903984951653826560","42540766490510755371168322545197776895","-","doc"
+10.0.0.0,10.0.0.255,"XX","private"
+"4294967296","1","XX","backwards"
+"-1","1","XX","negative"
+10.0.0.0
+`
+	recs, errs := ReadRangesCSV(strings.NewReader(in))
+
+	var got []string
+	for _, r := range recs {
+		got = append(got, r.Block.String()+"|"+r.Text)
+	}
+	want := "1.0.0.0/24|AU Australia 1.0.1.0/24|CN China 2001:db8::/32|- doc 10.0.0.0/24|XX private"
+	if s := strings.Join(got, " "); s != want {
+		t.Errorf("got: %q\nwant: %q", s, want)
+	}
+
+	if len(errs) != 3 {
+		t.Errorf("got %d errors, want 3: %v", len(errs), errs)
+	}
+}
+
+func TestReadRangesCSVIPv6DB(t *testing.T) {
+	// the first rows of the IP2Location IPv6 database, the start 0 is the IPv6 address ::
+	in := `"0","281470681743359","-","-"
+"281470681743360","281474976710655","-","-"
+`
+	recs, errs := ReadRangesCSV(strings.NewReader(in))
+	if errs != nil {
+		t.Fatal(errs)
+	}
+
+	var got []string
+	for _, r := range recs {
+		got = append(got, r.Block.String())
+	}
+	want := "::-::fffe:ffff:ffff 0.0.0.0/0"
+	if s := strings.Join(got, " "); s != want {
+		t.Errorf("got: %q\nwant: %q", s, want)
+	}
+}
+
+func TestDB(t *testing.T) {
+	// MaxMind GeoLite style with header
+	in := `network,geoname_id,registered_country_geoname_id
+1.0.0.0/24,2077456,2077456
+1.0.1.0/24,1814991,1814991
+2001:db8::/32,6252001,6252001
+2001:db8:1::/48,6251999,6252001
+`
+	recs, errs := ReadBlocksCSV(strings.NewReader(in), WithHeader(), WithTextColumns(1))
+	if errs != nil {
+		t.Fatal(errs)
+	}
+
+	db, err := NewDB(recs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 4 {
+		t.Errorf("Len(), got %d, want 4", db.Len())
+	}
+
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"1.0.0.17", "2077456"},
+		{"1.0.1.255", "1814991"},
+		{"2001:db8:1::1", "6251999"},
+		{"2001:db8:2::1", "6252001"},
+		{"1.0.2.0", ""},
+	}
+	for _, tt := range tests {
+		rec, ok := db.Lookup(mustIP(tt.ip))
+		if ok != (tt.want != "") || rec.Text != tt.want {
+			t.Errorf("Lookup(%s), got %q, %v, want %q", tt.ip, rec.Text, ok, tt.want)
+		}
+	}
+	if rec, ok := db.Lookup(mustIP("1.0.0.1")); !ok || len(rec.Fields) != 3 || rec.Line != 2 {
+		t.Errorf("Lookup, got record %+v, want all fields and line", rec)
+	}
+
+	// duplicates, also with different payload
+	recs, _ = ReadRangesCSV(strings.NewReader("1.0.0.0,1.0.0.10,a\n1.0.0.0,1.0.0.10,b\n"))
+	if _, err := NewDB(recs); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("NewDB with duplicates, got %v, want ErrDuplicate", err)
+	}
+
+	// partially overlapping ranges
+	recs, _ = ReadRangesCSV(strings.NewReader("1.0.0.0,1.0.0.10,a\n1.0.0.5,1.0.0.20,b\n"))
+	if _, err := NewDB(recs); err == nil {
+		t.Errorf("NewDB with overlaps, want error")
+	}
+}
@@ -0,0 +1,192 @@
+package inetio
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+)
+
+// Dialect of the router config syntax, see WritePrefixList.
+type Dialect int
+
+const (
+	// IOS, Cisco ip prefix-list
+	IOS Dialect = iota
+	// FRR, FRRouting ip prefix-list
+	FRR
+	// Junos, Juniper policy-statement with route-filters, in set format
+	Junos
+	// BIRD, BIRD 2 prefix set
+	BIRD
+)
+
+// PrefixEntry is a CIDR with optional length constraints, 0 means unset.
+type PrefixEntry struct {
+	Prefix inet.Block
+	Ge, Le int
+}
+
+// String returns the entry in IOS notation, e.g. "10.0.0.0/23 le 24".
+func (e PrefixEntry) String() string {
+	s := e.Prefix.String()
+	if e.Ge > 0 {
+		s += fmt.Sprintf(" ge %d", e.Ge)
+	}
+	if e.Le > 0 {
+		s += fmt.Sprintf(" le %d", e.Le)
+	}
+	return s
+}
+
+// PrefixEntries returns the entries for the blocks, ranges are split into CIDRs, in ascending order.
+//
+// With aggregate, the blocks are merged into the fewest CIDRs, each with the constraint
+// le set to the longest prefix length of the blocks beneath, if longer than the CIDR itself.
+// The entries then match all prefixes within the blocks up to that length.
+func PrefixEntries(bs []inet.Block, aggregate bool) []PrefixEntry {
+	var cidrs []inet.Block
+	for _, b := range bs {
+		if b.IsValid() {
+			cidrs = append(cidrs, b.CIDRs()...)
+		}
+	}
+	inet.SortBlocks(cidrs)
+
+	if !aggregate {
+		var out []PrefixEntry
+		for i, c := range cidrs {
+			if i > 0 && c == cidrs[i-1] {
+				continue
+			}
+			out = append(out, PrefixEntry{Prefix: c})
+		}
+		return out
+	}
+
+	var out []PrefixEntry
+	j := 0
+	for _, m := range inet.Merge(cidrs) {
+		for _, agg := range m.CIDRs() {
+			e := PrefixEntry{Prefix: agg}
+
+			// the sorted cidrs beneath agg, the longest prefix length
+			for ; j < len(cidrs) && (agg == cidrs[j] || agg.Covers(cidrs[j])); j++ {
+				if bits := cidrs[j].Bits(); bits > agg.Bits() && bits > e.Le {
+					e.Le = bits
+				}
+			}
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// WritePrefixList writes the entries as prefix-list named name in the router config dialect to w.
+// Without entries the Junos policy-statement rejects all routes.
+func WritePrefixList(w io.Writer, d Dialect, name string, entries []PrefixEntry) error {
+	var buf strings.Builder
+
+	switch d {
+	case IOS, FRR:
+		seq := map[bool]int{}
+		for _, e := range entries {
+			cmd := "ipv6"
+			if e.Prefix.Is4() {
+				cmd = "ip"
+			}
+			seq[e.Prefix.Is4()] += 5
+			fmt.Fprintf(&buf, "%s prefix-list %s seq %d permit %v\n", cmd, name, seq[e.Prefix.Is4()], e)
+		}
+
+	case Junos:
+		// a term without from conditions matches every route, reject them all
+		if len(entries) == 0 {
+			fmt.Fprintf(&buf, "set policy-options policy-statement %s term prefixes then reject\n", name)
+			break
+		}
+		for _, e := range entries {
+			fmt.Fprintf(&buf, "set policy-options policy-statement %s term prefixes from route-filter %v %s\n", name, e.Prefix, junosMatch(e))
+		}
+		fmt.Fprintf(&buf, "set policy-options policy-statement %s term prefixes then accept\n", name)
+
+	case BIRD:
+		var v4, v6 []string
+		for _, e := range entries {
+			if e.Prefix.Is4() {
+				v4 = append(v4, birdPrefix(e))
+			} else {
+				v6 = append(v6, birdPrefix(e))
+			}
+		}
+
+		// BIRD sets are typed, one set per IP version
+		name4, name6 := name, name
+		if v4 != nil && v6 != nil {
+			name4, name6 = name+"_v4", name+"_v6"
+		}
+		if v4 != nil {
+			fmt.Fprintf(&buf, "define %s = [ %s ];\n", name4, strings.Join(v4, ", "))
+		}
+		if v6 != nil {
+			fmt.Fprintf(&buf, "define %s = [ %s ];\n", name6, strings.Join(v6, ", "))
+		}
+
+	default:
+		return fmt.Errorf("inetio: unknown dialect %d", d)
+	}
+
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// maxLen of the IP version
+func maxLen(b inet.Block) int {
+	if b.Is4() {
+		return 32
+	}
+	return 128
+}
+
+// lengths returns the effective length range of e
+func lengths(e PrefixEntry) (lo, hi int) {
+	lo, hi = e.Prefix.Bits(), e.Prefix.Bits()
+	if e.Ge > 0 {
+		lo, hi = e.Ge, maxLen(e.Prefix)
+	}
+	if e.Le > 0 {
+		hi = e.Le
+	}
+	return
+}
+
+// junosMatch, the route-filter match type
+func junosMatch(e PrefixEntry) string {
+	bits := e.Prefix.Bits()
+	lo, hi := lengths(e)
+	switch {
+	case lo == bits && hi == bits:
+		return "exact"
+	case lo == bits && hi == maxLen(e.Prefix):
+		return "orlonger"
+	case lo == bits:
+		return fmt.Sprintf("upto /%d", hi)
+	default:
+		return fmt.Sprintf("prefix-length-range /%d-/%d", lo, hi)
+	}
+}
+
+// birdPrefix, the prefix set pattern
+func birdPrefix(e PrefixEntry) string {
+	bits := e.Prefix.Bits()
+	lo, hi := lengths(e)
+	switch {
+	case lo == bits && hi == bits:
+		return e.Prefix.String()
+	case lo == bits && hi == maxLen(e.Prefix):
+		return e.Prefix.String() + "+"
+	default:
+		return fmt.Sprintf("%v{%d,%d}", e.Prefix, lo, hi)
+	}
+}
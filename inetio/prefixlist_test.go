@@ -0,0 +1,97 @@
+package inetio
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+)
+
+func mustBlocks(ss ...string) []inet.Block {
+	var bs []inet.Block
+	for _, s := range ss {
+		b, err := inet.ParseBlock(s)
+		if err != nil {
+			panic(err)
+		}
+		bs = append(bs, b)
+	}
+	return bs
+}
+
+func TestPrefixEntries(t *testing.T) {
+	bs := mustBlocks("10.0.1.0/24", "10.0.0.0/24", "10.0.0.0/25", "10.0.0.0/24", "192.168.0.1-192.168.0.2", "2001:db8::/32")
+
+	var got []string
+	for _, e := range PrefixEntries(bs, false) {
+		got = append(got, e.String())
+	}
+	want := "10.0.0.0/24 10.0.0.0/25 10.0.1.0/24 192.168.0.1/32 192.168.0.2/32 2001:db8::/32"
+	if strings.Join(got, " ") != want {
+		t.Errorf("PrefixEntries(bs, false)\ngot:  %v\nwant: %v", got, want)
+	}
+
+	got = nil
+	for _, e := range PrefixEntries(bs, true) {
+		got = append(got, e.String())
+	}
+	want = "10.0.0.0/23 le 25,192.168.0.1/32,192.168.0.2/32,2001:db8::/32"
+	if strings.Join(got, ",") != want {
+		t.Errorf("PrefixEntries(bs, true)\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestWritePrefixList(t *testing.T) {
+	es := []PrefixEntry{
+		{Prefix: mustBlocks("10.0.0.0/8")[0]},
+		{Prefix: mustBlocks("10.0.0.0/23")[0], Le: 24},
+		{Prefix: mustBlocks("172.16.0.0/12")[0], Ge: 16, Le: 24},
+		{Prefix: mustBlocks("192.168.0.0/16")[0], Ge: 16},
+		{Prefix: mustBlocks("2001:db8::/32")[0], Ge: 48},
+	}
+
+	tests := []struct {
+		d    Dialect
+		want string
+	}{
+		{IOS, `ip prefix-list PL seq 5 permit 10.0.0.0/8
+ip prefix-list PL seq 10 permit 10.0.0.0/23 le 24
+ip prefix-list PL seq 15 permit 172.16.0.0/12 ge 16 le 24
+ip prefix-list PL seq 20 permit 192.168.0.0/16 ge 16
+ipv6 prefix-list PL seq 5 permit 2001:db8::/32 ge 48
+`},
+		{Junos, `set policy-options policy-statement PL term prefixes from route-filter 10.0.0.0/8 exact
+set policy-options policy-statement PL term prefixes from route-filter 10.0.0.0/23 upto /24
+set policy-options policy-statement PL term prefixes from route-filter 172.16.0.0/12 prefix-length-range /16-/24
+set policy-options policy-statement PL term prefixes from route-filter 192.168.0.0/16 orlonger
+set policy-options policy-statement PL term prefixes from route-filter 2001:db8::/32 prefix-length-range /48-/128
+set policy-options policy-statement PL term prefixes then accept
+`},
+		{BIRD, `define PL_v4 = [ 10.0.0.0/8, 10.0.0.0/23{23,24}, 172.16.0.0/12{16,24}, 192.168.0.0/16+ ];
+define PL_v6 = [ 2001:db8::/32{48,128} ];
+`},
+	}
+
+	for _, tt := range tests {
+		var buf strings.Builder
+		if err := WritePrefixList(&buf, tt.d, "PL", es); err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("WritePrefixList(%d)\ngot:\n%s\nwant:\n%s", tt.d, got, tt.want)
+		}
+	}
+
+	// without route-filters the term must not accept everything
+	var buf strings.Builder
+	if err := WritePrefixList(&buf, Junos, "PL", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "set policy-options policy-statement PL term prefixes then reject\n"; got != want {
+		t.Errorf("WritePrefixList(Junos, nil)\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	if err := WritePrefixList(&strings.Builder{}, Dialect(42), "PL", es); err == nil {
+		t.Errorf("WritePrefixList(42), want error")
+	}
+}
@@ -0,0 +1,251 @@
+// Package inetio reads and writes lists of blocks with payload,
+// the CSV/TSV files every consumer of the inet package parses.
+package inetio
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+)
+
+// ErrDuplicate is reported as ParseError.Err for duplicate blocks, see RejectDups.
+var ErrDuplicate = errors.New("duplicate block")
+
+// ErrRead is reported as ParseError.Err for I/O errors, reading stopped and the records are incomplete.
+// The ParseError unwraps to the I/O error as well.
+var ErrRead = errors.New("read error")
+
+// readError wraps the I/O error, it is ErrRead and the I/O error
+type readError struct{ err error }
+
+func (e readError) Error() string        { return fmt.Sprintf("%v: %v", ErrRead, e.err) }
+func (e readError) Unwrap() error        { return e.err }
+func (e readError) Is(target error) bool { return target == ErrRead }
+
+// Record is a parsed input line.
+type Record struct {
+	// the parsed block
+	Block inet.Block
+
+	// the text columns, joined by a blank
+	Text string
+
+	// all columns, whitespace trimmed, for custom column mappings
+	Fields []string
+
+	// the line number in input, starting at 1
+	Line int
+}
+
+// ParseError is a skipped input line.
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+// Error implements the error interface.
+func (e ParseError) Error() string {
+	return fmt.Sprintf("inetio: line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e ParseError) Unwrap() error {
+	return e.Err
+}
+
+// DupPolicy controls the handling of duplicate blocks.
+type DupPolicy int
+
+const (
+	// KeepDups returns all records, duplicates included, the default.
+	KeepDups DupPolicy = iota
+
+	// SkipDups returns the first record of duplicate blocks, the others are dropped silently.
+	SkipDups
+
+	// ReplaceDups returns the last record of duplicate blocks, at the position of the first.
+	ReplaceDups
+
+	// RejectDups returns the first record of duplicate blocks, the others are reported as ParseError.
+	RejectDups
+)
+
+// Option configures ReadBlocksCSV.
+type Option func(*config)
+
+type config struct {
+	comma    rune
+	comment  rune
+	header   bool
+	blockCol int
+	textCols []int
+	dups     DupPolicy
+}
+
+// WithComma sets the field delimiter, default ','. Use '\t' for TSV.
+func WithComma(r rune) Option {
+	return func(c *config) { c.comma = r }
+}
+
+// WithComment sets the comment character, default '#'. Lines starting with it are skipped,
+// 0 disables comments.
+func WithComment(r rune) Option {
+	return func(c *config) { c.comment = r }
+}
+
+// WithHeader skips the first line after comments and empty lines, e.g. the column names.
+func WithHeader() Option {
+	return func(c *config) { c.header = true }
+}
+
+// WithBlockColumn sets the column index of the block, default 0.
+func WithBlockColumn(i int) Option {
+	return func(c *config) { c.blockCol = i }
+}
+
+// WithTextColumns sets the column indexes joined to Record.Text,
+// default all columns after the block column.
+func WithTextColumns(cols ...int) Option {
+	return func(c *config) { c.textCols = cols }
+}
+
+// WithDuplicates sets the policy for duplicate blocks, default KeepDups.
+func WithDuplicates(p DupPolicy) Option {
+	return func(c *config) { c.dups = p }
+}
+
+// ReadBlocksCSV reads CSV records from r, one record per line:
+//
+//  block, text...
+//
+// Empty lines and comments are skipped. Lines with invalid blocks, missing columns or
+// rejected duplicates are returned as ParseErrors, reading continues.
+// An I/O error stops reading and is returned as last ParseError, wrapping ErrRead.
+func ReadBlocksCSV(r io.Reader, opts ...Option) ([]Record, []ParseError) {
+	c := newConfig(opts)
+	return c.read(r, c.parse)
+}
+
+// newConfig with defaults
+func newConfig(opts []Option) config {
+	c := config{comma: ',', comment: '#'}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// read the lines from r, parsed by parse, the dup policy applied
+func (c config) read(r io.Reader, parse func(string) (Record, error)) ([]Record, []ParseError) {
+	var out []Record
+	var errs []ParseError
+
+	// index of blocks in out, for the dup policies
+	seen := make(map[inet.Block]int)
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	header := c.header
+	for scanner.Scan() {
+		line++
+
+		s := strings.TrimSpace(scanner.Text())
+		if s == "" || c.comment != 0 && strings.HasPrefix(s, string(c.comment)) {
+			continue
+		}
+		if header {
+			header = false
+			continue
+		}
+
+		rec, err := parse(s)
+		if err != nil {
+			errs = append(errs, ParseError{Line: line, Err: err})
+			continue
+		}
+		rec.Line = line
+
+		if c.dups != KeepDups {
+			if i, ok := seen[rec.Block]; ok {
+				switch c.dups {
+				case ReplaceDups:
+					out[i] = rec
+				case RejectDups:
+					errs = append(errs, ParseError{Line: line, Err: fmt.Errorf("%w: %v, see line %d", ErrDuplicate, rec.Block, out[i].Line)})
+				}
+				continue
+			}
+			seen[rec.Block] = len(out)
+		}
+
+		out = append(out, rec)
+	}
+
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, ParseError{Line: line + 1, Err: readError{err}})
+	}
+
+	return out, errs
+}
+
+// parse a single CSV line
+func (c config) parse(s string) (Record, error) {
+	fields, err := c.split(s)
+	if err != nil {
+		return Record{}, err
+	}
+
+	if c.blockCol < 0 || c.blockCol >= len(fields) {
+		return Record{}, fmt.Errorf("missing block column %d", c.blockCol)
+	}
+
+	block, err := inet.ParseBlock(fields[c.blockCol])
+	if err != nil {
+		return Record{}, err
+	}
+
+	text, err := c.text(fields, c.blockCol+1)
+	if err != nil {
+		return Record{}, err
+	}
+
+	return Record{Block: block, Text: text, Fields: fields}, nil
+}
+
+// split the CSV line into whitespace trimmed fields
+func (c config) split(s string) ([]string, error) {
+	cr := csv.NewReader(strings.NewReader(s))
+	cr.Comma = c.comma
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	fields, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields, nil
+}
+
+// text joins the text columns, default all columns from next on
+func (c config) text(fields []string, next int) (string, error) {
+	var texts []string
+	if c.textCols == nil {
+		texts = fields[next:]
+	} else {
+		for _, i := range c.textCols {
+			if i < 0 || i >= len(fields) {
+				return "", fmt.Errorf("missing text column %d", i)
+			}
+			texts = append(texts, fields[i])
+		}
+	}
+	return strings.TrimSpace(strings.Join(texts, " ")), nil
+}
@@ -0,0 +1,128 @@
+package inetio
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+const input = `
+# comment
+10.0.0.0/8, net ten, rfc1918
+10.0.0.0/24,"quoted, text"
+foo, invalid
+2001:db8::/32
+  # indented comment
+10.0.0.0/8, again
+`
+
+func TestReadBlocksCSV(t *testing.T) {
+	recs, errs := ReadBlocksCSV(strings.NewReader(input))
+
+	var got []string
+	for _, r := range recs {
+		got = append(got, r.Block.String()+"|"+r.Text)
+	}
+	want := "10.0.0.0/8|net ten rfc1918 10.0.0.0/24|quoted, text 2001:db8::/32| 10.0.0.0/8|again"
+	if s := strings.Join(got, " "); s != want {
+		t.Errorf("got: %q\nwant: %q", s, want)
+	}
+
+	if recs[0].Line != 3 || recs[3].Line != 8 {
+		t.Errorf("lines: %d, %d, want 3, 8", recs[0].Line, recs[3].Line)
+	}
+
+	if len(errs) != 1 || errs[0].Line != 5 {
+		t.Errorf("errs: %v, want one error in line 5", errs)
+	}
+}
+
+func TestReadBlocksCSVDups(t *testing.T) {
+	tests := []struct {
+		policy DupPolicy
+		texts  string
+		errs   int
+	}{
+		{KeepDups, "net ten rfc1918,quoted, text,,again", 0},
+		{SkipDups, "net ten rfc1918,quoted, text,", 0},
+		{ReplaceDups, "again,quoted, text,", 0},
+		{RejectDups, "net ten rfc1918,quoted, text,", 1},
+	}
+
+	for _, tt := range tests {
+		recs, errs := ReadBlocksCSV(strings.NewReader(input), WithDuplicates(tt.policy))
+
+		var texts []string
+		for _, r := range recs {
+			texts = append(texts, r.Text)
+		}
+		if s := strings.Join(texts, ","); s != tt.texts {
+			t.Errorf("policy %d, got: %q, want: %q", tt.policy, s, tt.texts)
+		}
+
+		// the invalid block in line 5 is always reported
+		if len(errs) != 1+tt.errs {
+			t.Errorf("policy %d, got %d errors, want %d", tt.policy, len(errs), 1+tt.errs)
+		}
+		if tt.errs > 0 && !errors.Is(errs[1], ErrDuplicate) {
+			t.Errorf("policy %d, got %v, want ErrDuplicate", tt.policy, errs[1])
+		}
+	}
+}
+
+func TestReadBlocksCSVReadError(t *testing.T) {
+	errIO := errors.New("disk on fire")
+	r := io.MultiReader(strings.NewReader("10.0.0.0/8, ten\nfoo\n"), iotest.ErrReader(errIO))
+
+	recs, errs := ReadBlocksCSV(r)
+	if len(recs) != 1 || len(errs) != 2 {
+		t.Fatalf("got %d records, %v, want 1 record, 2 errors", len(recs), errs)
+	}
+	if errors.Is(errs[0], ErrRead) {
+		t.Errorf("malformed record, got %v, want no ErrRead", errs[0])
+	}
+	if !errors.Is(errs[1], ErrRead) || !errors.Is(errs[1], errIO) {
+		t.Errorf("I/O error, got %v, want ErrRead and the I/O error", errs[1])
+	}
+}
+
+func TestReadBlocksCSVColumns(t *testing.T) {
+	tsv := "; comment\nhost1\t192.168.1.0/24\tlab\tbuilding 7\nhost2\n"
+
+	recs, errs := ReadBlocksCSV(strings.NewReader(tsv),
+		WithComma('\t'),
+		WithComment(';'),
+		WithBlockColumn(1),
+		WithTextColumns(3, 0),
+	)
+
+	if len(recs) != 1 || recs[0].Block.String() != "192.168.1.0/24" || recs[0].Text != "building 7 host1" {
+		t.Errorf("got: %v", recs)
+	}
+	if len(recs) == 1 && len(recs[0].Fields) != 4 {
+		t.Errorf("got fields: %q, want 4", recs[0].Fields)
+	}
+	if len(errs) != 1 || errs[0].Line != 3 {
+		t.Errorf("errs: %v, want missing column in line 3", errs)
+	}
+}
+
+func TestFromRecords(t *testing.T) {
+	recs, _ := ReadBlocksCSV(strings.NewReader(input), WithDuplicates(SkipDups))
+
+	tr, err := FromRecords(recs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.Len() != 3 {
+		t.Errorf("Len(), got %d, want 3", tr.Len())
+	}
+
+	// dups are reported by tree.New
+	recs, _ = ReadBlocksCSV(strings.NewReader(input))
+	if _, err := FromRecords(recs); err == nil {
+		t.Errorf("FromRecords with dups, want error")
+	}
+}
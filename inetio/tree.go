@@ -0,0 +1,17 @@
+package inetio
+
+import (
+	"github.com/gaissmai/go-inet/v2/inettree"
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+// FromRecords returns the tree of inettree.Items with block and text of the records.
+// The error is returned from tree.New, e.g. for duplicate or partially overlapping blocks,
+// see also WithDuplicates.
+func FromRecords(recs []Record, opts ...tree.Option) (*tree.Tree, error) {
+	items := make([]tree.Interface, 0, len(recs))
+	for _, r := range recs {
+		items = append(items, inettree.Item{Block: r.Block, Text: r.Text})
+	}
+	return tree.New(items, opts...)
+}
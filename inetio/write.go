@@ -0,0 +1,161 @@
+package inetio
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Column of a table, see WriteTable.
+type Column struct {
+	// the column header
+	Header string
+
+	// the cell value for a record
+	Value func(Record) string
+
+	// right aligned, e.g. for numbers
+	Right bool
+}
+
+// Some predefined columns.
+var (
+	// BlockColumn, the block as string.
+	BlockColumn = Column{Header: "Block", Value: func(r Record) string { return r.Block.String() }}
+
+	// ExpandedColumn, the block with expanded IP addresses, sorts lexically and aligns vertically.
+	ExpandedColumn = Column{Header: "Block", Value: expandBlock}
+
+	// SizeColumn, the number of addresses in block.
+	SizeColumn = Column{Header: "Size", Value: func(r Record) string { return r.Block.Size().String() }, Right: true}
+
+	// MaskColumn, the prefix length of the block, empty for ranges.
+	MaskColumn = Column{Header: "Mask", Value: mask, Right: true}
+
+	// TextColumn, the text of the record.
+	TextColumn = Column{Header: "Description", Value: func(r Record) string { return r.Text }}
+)
+
+// expandBlock, base and last address expanded
+func expandBlock(r Record) string {
+	if !r.Block.IsValid() {
+		return r.Block.String()
+	}
+	if bits := r.Block.Bits(); bits >= 0 {
+		return r.Block.Base().Expand() + "/" + strconv.Itoa(bits)
+	}
+	return r.Block.Base().Expand() + "-" + r.Block.Last().Expand()
+}
+
+// mask, the prefix length as /bits
+func mask(r Record) string {
+	if bits := r.Block.Bits(); bits >= 0 {
+		return "/" + strconv.Itoa(bits)
+	}
+	return ""
+}
+
+// WriteTable writes the records as aligned plain-text table to w, with a header line.
+// Without columns, BlockColumn and TextColumn are written.
+func WriteTable(w io.Writer, rows []Record, columns ...Column) error {
+	return writeTable(w, rows, columns, false)
+}
+
+// WriteMarkdown writes the records as aligned markdown table to w.
+// Without columns, BlockColumn and TextColumn are written.
+func WriteMarkdown(w io.Writer, rows []Record, columns ...Column) error {
+	return writeTable(w, rows, columns, true)
+}
+
+func writeTable(w io.Writer, rows []Record, columns []Column, markdown bool) error {
+	if len(columns) == 0 {
+		columns = []Column{BlockColumn, TextColumn}
+	}
+
+	// all cells, header included
+	cells := make([][]string, 0, len(rows)+1)
+
+	header := make([]string, len(columns))
+	for j, col := range columns {
+		header[j] = col.Header
+	}
+	cells = append(cells, header)
+
+	for _, r := range rows {
+		line := make([]string, len(columns))
+		for j, col := range columns {
+			line[j] = col.Value(r)
+		}
+		cells = append(cells, line)
+	}
+
+	// a pipe in a cell would end the markdown cell, escape it before the widths are counted
+	if markdown {
+		for _, line := range cells {
+			for j, cell := range line {
+				line[j] = strings.ReplaceAll(cell, "|", `\|`)
+			}
+		}
+	}
+
+	// column widths in runes
+	widths := make([]int, len(columns))
+	for _, line := range cells {
+		for j, cell := range line {
+			if n := utf8.RuneCountInString(cell); n > widths[j] {
+				widths[j] = n
+			}
+		}
+	}
+
+	var buf strings.Builder
+	for i, line := range cells {
+		buf.WriteString(formatLine(line, columns, widths, markdown))
+
+		// markdown separator after header
+		if i == 0 && markdown {
+			sep := make([]string, len(columns))
+			for j, col := range columns {
+				dashes := strings.Repeat("-", max(widths[j], 3))
+				if col.Right {
+					dashes = dashes[1:] + ":"
+				}
+				sep[j] = dashes
+			}
+			buf.WriteString("| " + strings.Join(sep, " | ") + " |\n")
+		}
+	}
+
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// formatLine, pad the cells to the column widths
+func formatLine(line []string, columns []Column, widths []int, markdown bool) string {
+	padded := make([]string, len(line))
+	for j, cell := range line {
+		width := widths[j]
+		if markdown {
+			width = max(width, 3)
+		}
+		pad := strings.Repeat(" ", width-utf8.RuneCountInString(cell))
+		if columns[j].Right {
+			padded[j] = pad + cell
+		} else {
+			padded[j] = cell + pad
+		}
+	}
+
+	if markdown {
+		return "| " + strings.Join(padded, " | ") + " |\n"
+	}
+	return strings.TrimRight(strings.Join(padded, "  "), " ") + "\n"
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,47 @@
+package inetio
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWriteTableDefault(t *testing.T) {
+	recs, _ := ReadBlocksCSV(strings.NewReader("10.0.0.0/8, ü-net\n::1/128\n"))
+
+	var buf strings.Builder
+	if err := WriteTable(&buf, recs); err != nil {
+		t.Fatal(err)
+	}
+
+	// rune aligned, trailing blanks trimmed
+	want := "Block       Description\n10.0.0.0/8  ü-net\n::1/128\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteMarkdownPipe(t *testing.T) {
+	recs, _ := ReadBlocksCSV(strings.NewReader("10.0.0.0/8, a|b\n"))
+
+	var buf strings.Builder
+	if err := WriteMarkdown(&buf, recs); err != nil {
+		t.Fatal(err)
+	}
+
+	// escaped and aligned
+	want := "| Block      | Description |\n| ---------- | ----------- |\n| 10.0.0.0/8 | a\\|b        |\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) { return 0, errors.New("write error") }
+
+func TestWriteTableError(t *testing.T) {
+	if err := WriteMarkdown(errWriter{}, nil); err == nil {
+		t.Errorf("WriteMarkdown, want error")
+	}
+}
@@ -6,10 +6,15 @@ import (
 	"github.com/gaissmai/go-inet/v2/tree"
 )
 
-// compiler check, Item implements tree.Interface
+// compiler check, Item implements tree.Interface and tree.Overlapper
 var _ tree.Interface = Item{}
+var _ tree.Overlapper = Item{}
 
 // Item augments inet.Block, implementing the tree.Interface
+//
+// Items are ordered and compared by the Block only, the Text is no secondary key.
+// Items with equal blocks but different text are duplicates, the first one in
+// input order wins in the tree, see tree.New.
 type Item struct {
 	// the augmented Block
 	inet.Block
@@ -18,6 +23,16 @@ type Item struct {
 	Text string
 }
 
+// ItemFromIP returns the Item for ip as /32 or /128 CIDR with text.
+// If ip is invalid, returns Item{} and error.
+func ItemFromIP(ip inet.IP, text string) (Item, error) {
+	b, err := inet.BlockFromIP(ip)
+	if err != nil {
+		return Item{}, err
+	}
+	return Item{Block: b, Text: text}, nil
+}
+
 // Less implements the tree.Interface for Item
 func (a Item) Less(i tree.Interface) bool {
 	b := i.(Item)
@@ -36,6 +51,13 @@ func (a Item) Covers(i tree.Interface) bool {
 	return a.Block.Covers(b.Block)
 }
 
+// Overlaps implements the tree.Overlapper interface for Item,
+// partially overlapping blocks are rejected by tree.New.
+func (a Item) Overlaps(i tree.Interface) bool {
+	b := i.(Item)
+	return a.Block.Overlaps(b.Block)
+}
+
 // String implements the tree.Interface for Item
 func (a Item) String() string {
 	if a.Text == "" {
@@ -45,3 +67,11 @@ func (a Item) String() string {
 	// augment the Block with additional text
 	return a.Text
 }
+
+// AppendTo implements the tree.Appender interface for Item, renders like String.
+func (a Item) AppendTo(dst []byte) []byte {
+	if a.Text == "" {
+		return a.Block.AppendTo(dst)
+	}
+	return append(dst, a.Text...)
+}
@@ -0,0 +1,16 @@
+package inettree
+
+import (
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+// FromMap returns the tree of Items for the common block → text mapping.
+// The error is returned from tree.New, e.g. for partially overlapping blocks.
+func FromMap(m map[inet.Block]string, opts ...tree.Option) (*tree.Tree, error) {
+	items := make([]tree.Interface, 0, len(m))
+	for b, text := range m {
+		items = append(items, Item{Block: b, Text: text})
+	}
+	return tree.New(items, opts...)
+}
@@ -0,0 +1,79 @@
+package inettree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+// compiler check, Codec implements tree.Codec
+var _ tree.Codec = Codec{}
+
+// Codec is the binary tree.Codec for Item, e.g. for tree.ExternalSort.
+//
+// Record: IP version (1 byte), base and last address (2*16 bytes), text length (4 bytes) and text.
+// The text is limited to MaxCodecText bytes.
+type Codec struct{}
+
+// MaxCodecText limits the text length of a Codec record,
+// a corrupt length in a spill file must not allocate gigabytes.
+const MaxCodecText = 1 << 24
+
+// Encode implements the tree.Codec interface.
+func (Codec) Encode(w io.Writer, i tree.Interface) error {
+	a := i.(Item)
+	if len(a.Text) > MaxCodecText {
+		return fmt.Errorf("inettree: text of %v exceeds %d bytes", a.Block, MaxCodecText)
+	}
+	version, hi, lo := a.Block.Base().Raw()
+	_, lastHi, lastLo := a.Block.Last().Raw()
+
+	buf := make([]byte, 37, 37+len(a.Text))
+	buf[0] = version
+	binary.BigEndian.PutUint64(buf[1:], hi)
+	binary.BigEndian.PutUint64(buf[9:], lo)
+	binary.BigEndian.PutUint64(buf[17:], lastHi)
+	binary.BigEndian.PutUint64(buf[25:], lastLo)
+	binary.BigEndian.PutUint32(buf[33:], uint32(len(a.Text)))
+	buf = append(buf, a.Text...)
+
+	_, err := w.Write(buf)
+	return err
+}
+
+// Decode implements the tree.Codec interface.
+func (Codec) Decode(r io.Reader) (tree.Interface, error) {
+	var head [37]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		// io.EOF only at record boundaries, else io.ErrUnexpectedEOF
+		return nil, err
+	}
+
+	n := binary.BigEndian.Uint32(head[33:])
+	if n > MaxCodecText {
+		return nil, fmt.Errorf("inettree: corrupt record: text length %d exceeds %d", n, MaxCodecText)
+	}
+
+	// the buffer grows with the input read, not with the untrusted length
+	var text bytes.Buffer
+	if _, err := io.CopyN(&text, r, int64(n)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	version := head[0]
+	base := inet.UnsafeIPFromParts(version, binary.BigEndian.Uint64(head[1:]), binary.BigEndian.Uint64(head[9:]))
+	last := inet.UnsafeIPFromParts(version, binary.BigEndian.Uint64(head[17:]), binary.BigEndian.Uint64(head[25:]))
+
+	b := inet.UnsafeBlockFromParts(base, last)
+	if err := inet.VerifyBlockInvariants(b); err != nil {
+		return nil, fmt.Errorf("inettree: corrupt record: %w", err)
+	}
+	return Item{Block: b, Text: text.String()}, nil
+}
@@ -0,0 +1,87 @@
+package inettree
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+func TestCodec(t *testing.T) {
+	prng := rand.New(rand.NewSource(1))
+	items := randItems(prng, 100)
+
+	var buf bytes.Buffer
+	for _, item := range items {
+		if err := (Codec{}).Encode(&buf, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	raw := append([]byte(nil), buf.Bytes()...)
+
+	for i, want := range items {
+		got, err := (Codec{}).Decode(&buf)
+		if err != nil || got != want {
+			t.Fatalf("Decode, item %d, got %v, %v, want %v", i, got, err, want)
+		}
+	}
+	if _, err := (Codec{}).Decode(&buf); err != io.EOF {
+		t.Errorf("Decode at end, got %v, want io.EOF", err)
+	}
+
+	// truncated
+	if _, err := (Codec{}).Decode(bytes.NewReader(raw[:40])); err != io.ErrUnexpectedEOF {
+		t.Errorf("Decode truncated, got %v, want io.ErrUnexpectedEOF", err)
+	}
+
+	// corrupt, text length beyond the limit and beyond the input
+	huge := append([]byte(nil), raw[:37]...)
+	huge[33], huge[34], huge[35], huge[36] = 0xff, 0xff, 0xff, 0xff
+	if _, err := (Codec{}).Decode(bytes.NewReader(huge)); err == nil || err == io.ErrUnexpectedEOF {
+		t.Errorf("Decode huge text length, got %v, want error", err)
+	}
+	huge[33], huge[34], huge[35], huge[36] = 0, 0xff, 0xff, 0xff
+	if _, err := (Codec{}).Decode(bytes.NewReader(huge)); err != io.ErrUnexpectedEOF {
+		t.Errorf("Decode truncated text, got %v, want io.ErrUnexpectedEOF", err)
+	}
+	if err := (Codec{}).Encode(io.Discard, Item{Block: items[0].(Item).Block, Text: string(make([]byte, MaxCodecText+1))}); err == nil {
+		t.Errorf("Encode huge text, want error")
+	}
+
+	// corrupt, unknown IP version
+	raw[0] = 5
+	if _, err := (Codec{}).Decode(bytes.NewReader(raw)); !errors.Is(err, inet.ErrInvariant) {
+		t.Errorf("Decode corrupt, got %v, want ErrInvariant", err)
+	}
+}
+
+func TestExternalSort(t *testing.T) {
+	prng := rand.New(rand.NewSource(1))
+	items := randItems(prng, 5000)
+
+	want, _ := tree.New(items)
+
+	for _, runSize := range []int{1, 7, 1000, 5000, 10000} {
+		sorted, done, err := tree.ExternalSort(tree.SliceIterator(items), Codec{}, runSize, t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, _ := tree.NewFromSorted(sorted)
+		if err := done(); err != nil {
+			t.Errorf("runSize %d, close, unexpected error: %v", runSize, err)
+		}
+
+		if got.String() != want.String() {
+			t.Errorf("runSize %d, tree differs from New", runSize)
+		}
+		if !reflect.DeepEqual(got.Duplicates(), want.Duplicates()) {
+			t.Errorf("runSize %d, Duplicates differ from New", runSize)
+		}
+	}
+}
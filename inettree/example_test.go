@@ -65,3 +65,142 @@ func Example_usage() {
 	// └─ 6000::/3 ... Reserved by IETF     [RFC3513][RFC4291]
 
 }
+
+func ExampleLookupIPString() {
+	var items []tree.Interface
+	for _, s := range []string{"10.0.0.0/8", "10.0.0.0/24", "2001:db8::/32"} {
+		block, _ := inet.ParseBlock(s)
+		items = append(items, inettree.Item{Block: block, Text: "net " + s})
+	}
+	t, _ := tree.New(items)
+
+	for _, s := range []string{"10.0.0.17", "10.1.2.3", "192.168.1.1", "foo"} {
+		match, ok, err := inettree.LookupIPString(t, s)
+		fmt.Printf("%-12s match: %-5v block: %-14v text: %q, err: %v\n", s, ok, match.Block, match.Text, err)
+	}
+
+	// Output:
+	// 10.0.0.17    match: true  block: 10.0.0.0/24    text: "net 10.0.0.0/24", err: <nil>
+	// 10.1.2.3     match: true  block: 10.0.0.0/8     text: "net 10.0.0.0/8", err: <nil>
+	// 192.168.1.1  match: false block: invalid Block  text: "", err: <nil>
+	// foo          match: false block: invalid Block  text: "", err: invalid IP: foo
+}
+
+func ExampleCompile() {
+	var items []tree.Interface
+	for _, s := range []string{"10.0.0.0/8", "10.0.0.0/24", "10.0.0.128/25", "2001:db8::/32"} {
+		block, _ := inet.ParseBlock(s)
+		items = append(items, inettree.Item{Block: block, Text: "net " + s})
+	}
+	t, _ := tree.New(items)
+	m := inettree.Compile(t)
+
+	for _, s := range []string{"10.0.0.17", "10.0.0.200", "10.1.2.3", "2001:db8::1", "192.168.1.1"} {
+		ip, _ := inet.ParseIP(s)
+		match, ok := m.Match(ip)
+		fmt.Printf("%-12s match: %-5v text: %q\n", s, ok, match.Text)
+	}
+
+	// Output:
+	// 10.0.0.17    match: true  text: "net 10.0.0.0/24"
+	// 10.0.0.200   match: true  text: "net 10.0.0.128/25"
+	// 10.1.2.3     match: true  text: "net 10.0.0.0/8"
+	// 2001:db8::1  match: true  text: "net 2001:db8::/32"
+	// 192.168.1.1  match: false text: ""
+}
+
+func ExampleFreeUnder() {
+	var items []tree.Interface
+	for _, s := range []string{"10.0.0.0/8", "10.0.0.0/26", "10.0.0.16/28", "10.0.0.128/25", "10.0.1.0/24"} {
+		block, _ := inet.ParseBlock(s)
+		items = append(items, inettree.Item{Block: block})
+	}
+	t, _ := tree.New(items)
+
+	outer, _ := inet.ParseBlock("10.0.0.0/23")
+	fmt.Println(inettree.FreeUnder(t, outer))
+
+	// Output:
+	// [10.0.0.64/26]
+}
+
+func ExampleFromMap() {
+	m := make(map[inet.Block]string)
+	for s, text := range map[string]string{"10.0.0.0/8": "rfc1918", "10.0.0.0/24": "lan", "10.0.1.0/24": "dmz"} {
+		block, _ := inet.ParseBlock(s)
+		m[block] = text
+	}
+
+	t, _ := inettree.FromMap(m)
+	fmt.Print(t)
+
+	// Output:
+	// ▼
+	// └─ rfc1918
+	//    ├─ lan
+	//    └─ dmz
+}
+
+func ExampleItemFromIP() {
+	ip, _ := inet.ParseIP("10.0.0.1")
+	item, _ := inettree.ItemFromIP(ip, "gateway")
+
+	fmt.Println(item.Block, item.Text)
+
+	// Output:
+	// 10.0.0.1/32 gateway
+}
+
+func ExampleHistogram() {
+	var items []tree.Interface
+	for _, s := range []string{"10.0.0.0/8", "10.0.0.0/24", "10.0.1.0/24", "10.0.2.0-10.0.2.99", "2001:db8::/32"} {
+		block, _ := inet.ParseBlock(s)
+		items = append(items, inettree.Item{Block: block})
+	}
+	t, _ := tree.New(items)
+
+	h := inettree.Histogram(t)
+	fmt.Println("IPv4:", h.V4, "IPv6:", h.V6)
+	fmt.Println("/24 CIDRs:", h.CIDR4[24], "ranges up to /25:", h.Range4[25])
+
+	// Output:
+	// IPv4: 4 IPv6: 1
+	// /24 CIDRs: 2 ranges up to /25: 1
+}
+
+func Example_multi() {
+	records := []struct{ block, text string }{
+		{"10.0.0.0/8", "ticket 4711"},
+		{"10.0.0.0/24", "vlan 10"},
+		{"10.0.0.0/8", "owner netops"},
+		{"10.0.0.0/24", "owner alice"},
+	}
+
+	var items []tree.Interface
+	for _, r := range records {
+		block, err := inet.ParseBlock(r.block)
+		if err != nil {
+			panic(err)
+		}
+		items = append(items, inettree.Item{Block: block, Text: r.text})
+	}
+
+	t, err := tree.NewMulti(items)
+	if err != nil {
+		panic(err)
+	}
+
+	ip, _ := inet.ParseIP("10.0.0.17")
+	query, _ := inettree.ItemFromIP(ip, "")
+	for _, item := range t.LookupAll(query) {
+		fmt.Println(item.(inettree.Item).Block, item)
+	}
+
+	// the other queries return the groups
+	fmt.Println(t.Superset(query))
+
+	// Output:
+	// 10.0.0.0/24 vlan 10
+	// 10.0.0.0/24 owner alice
+	// ticket 4711 | owner netops
+}
@@ -0,0 +1,53 @@
+package inettree
+
+import (
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+// FreeUnder returns the free CIDRs under outer, the space not covered by any item
+// of the tree t below outer, in ascending order. outer itself needn't be an item in t,
+// items equal to or covering outer don't count as allocated.
+//
+// Only the subtree below outer is visited, O(log n + k), plus the items overlapping
+// outer partially at its edges.
+func FreeUnder(t *tree.Tree, outer inet.Block) []inet.Block {
+	if !outer.IsValid() {
+		return nil
+	}
+	query := Item{Block: outer}
+
+	// the topmost items below outer, in sort order
+	var taken []inet.Block
+	var last inet.Block
+
+	// items overlapping outer partially, at most one at each edge
+	if pred, ok := t.Precedes(query); ok && pred.(Item).Block.Overlaps(outer) {
+		taken = append(taken, pred.(Item).Block)
+	}
+
+	for _, item := range t.Within(query) {
+		b := item.(Item).Block
+		if b == outer {
+			continue
+		}
+
+		// subtree of the last collected item
+		if len(taken) > 0 && last.Covers(b) {
+			continue
+		}
+
+		taken = append(taken, b)
+		last = b
+	}
+
+	if succ, ok := t.Follows(query); ok && succ.(Item).Block.Overlaps(outer) {
+		taken = append(taken, succ.(Item).Block)
+	}
+
+	var out []inet.Block
+	for _, gap := range inet.Gaps(outer, taken) {
+		out = append(out, gap.CIDRs()...)
+	}
+	return out
+}
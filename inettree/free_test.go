@@ -0,0 +1,79 @@
+package inettree
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+func TestFreeUnder(t *testing.T) {
+	prng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 200; i++ {
+		items := randItems(prng, 1+prng.Intn(100))
+		tr, _ := tree.New(items)
+
+		// outer from the items or not in tree
+		outer := items[prng.Intn(len(items))].(Item).Block
+		if prng.Intn(2) == 0 {
+			outer = randItems(prng, 1)[0].(Item).Block
+		}
+
+		// brute force, all items below outer
+		var bs []inet.Block
+		for _, it := range items {
+			b := it.(Item).Block
+			if b == outer || b.Covers(outer) || b.IsDisjunct(outer) {
+				continue
+			}
+			bs = append(bs, b)
+		}
+		sort.Slice(bs, func(i, j int) bool { return bs[i].Less(bs[j]) })
+
+		var want []inet.Block
+		for _, gap := range inet.Gaps(outer, bs) {
+			want = append(want, gap.CIDRs()...)
+		}
+
+		if got := FreeUnder(tr, outer); !reflect.DeepEqual(got, want) {
+			t.Fatalf("FreeUnder(%v), got: %v, want: %v", outer, got, want)
+		}
+	}
+
+	if got := FreeUnder(&tree.Tree{}, inet.Block{}); got != nil {
+		t.Errorf("FreeUnder(invalid), got: %v, want nil", got)
+	}
+}
+
+func TestFreeUnderPartialOverlaps(t *testing.T) {
+	mk := func(ss ...string) []tree.Interface {
+		var items []tree.Interface
+		for _, s := range ss {
+			b, _ := inet.ParseBlock(s)
+			items = append(items, Item{Block: b})
+		}
+		return items
+	}
+
+	// outer overlaps the first and the last /28 partially
+	outer, _ := inet.ParseBlock("10.0.0.8-10.0.0.71")
+	want := []inet.Block{}
+	for _, s := range []string{"10.0.0.16/28", "10.0.0.48/28"} {
+		b, _ := inet.ParseBlock(s)
+		want = append(want, b)
+	}
+
+	for _, items := range [][]tree.Interface{
+		mk("10.0.0.0/28", "10.0.0.32/28", "10.0.0.64/28", "10.0.1.0/24"),
+		mk("10.0.0.0/24", "10.0.0.0/28", "10.0.0.32/28", "10.0.0.33", "10.0.0.64/28", "10.0.0.128/25"),
+	} {
+		tr, _ := tree.New(items)
+		if got := FreeUnder(tr, outer); !reflect.DeepEqual(got, want) {
+			t.Errorf("FreeUnder(%v), got: %v, want: %v", outer, got, want)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package inettree
+
+import (
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+// Histogram counts the items of the tree t per IP version and prefix length, see inet.Histogram.
+// Duplicate items are counted once. Histogram panics if t holds other items than Item.
+func Histogram(t *tree.Tree) inet.Histogram {
+	var h inet.Histogram
+	_ = t.Walk(func(_ int, item, _ tree.Interface, _ []tree.Interface) error {
+		h.Add(item.(Item).Block)
+		return nil
+	})
+	return h
+}
@@ -0,0 +1,37 @@
+package inettree
+
+import (
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+// LookupIP returns the longest-prefix-match for ip in the tree t, built from Items.
+// The match is the item as stored in t, with its text, also for an exact /32 or /128 match.
+// ok is false if ip isn't covered by any item in t.
+func LookupIP(t *tree.Tree, ip inet.IP) (match Item, ok bool) {
+	b, err := inet.BlockFromIP(ip)
+	if err != nil {
+		return
+	}
+	return lookupItem(t, Item{Block: b})
+}
+
+// LookupIPString parses s as IP address and returns the longest-prefix-match in the tree t, built from Items.
+// ok is false if the IP isn't covered by any item in t, err is returned for invalid input.
+func LookupIPString(t *tree.Tree, s string) (match Item, ok bool, err error) {
+	ip, err := inet.ParseIP(s)
+	if err != nil {
+		return
+	}
+	match, ok = LookupIP(t, ip)
+	return
+}
+
+// lookupItem, type assertion for the tree.Interface result
+func lookupItem(t *tree.Tree, item Item) (Item, bool) {
+	m := t.Lookup(item)
+	if m == nil {
+		return Item{}, false
+	}
+	return m.(Item), true
+}
@@ -0,0 +1,36 @@
+package inettree
+
+import (
+	"testing"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+func TestLookupIPExactMatch(t *testing.T) {
+	var items []tree.Interface
+	for _, r := range [][2]string{{"10.0.0.0/8", "corp"}, {"10.0.0.17", "host v4"}, {"2001:db8::1", "host v6"}} {
+		b, _ := inet.ParseBlock(r[0])
+		items = append(items, Item{Block: b, Text: r[1]})
+	}
+	tr, _ := tree.New(items)
+
+	tests := []struct {
+		ip, want string
+	}{
+		{"10.0.0.17", "host v4"},
+		{"2001:db8::1", "host v6"},
+		{"10.0.0.18", "corp"},
+	}
+	for _, tt := range tests {
+		ip, _ := inet.ParseIP(tt.ip)
+		match, ok := LookupIP(tr, ip)
+		if !ok || match.Text != tt.want {
+			t.Errorf("LookupIP(%s), got %q, %v, want %q", tt.ip, match.Text, ok, tt.want)
+		}
+	}
+
+	if _, ok := LookupIP(tr, inet.IP{}); ok {
+		t.Errorf("LookupIP(IP{}), want !ok")
+	}
+}
@@ -0,0 +1,112 @@
+package inettree
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+// Matcher is a compiled, read-only longest-prefix-matcher for IP addresses.
+//
+// The nested items of the tree are flattened into disjunct, sorted ranges,
+// each range maps to the most specific item covering it.
+// Match is a plain binary search over the ranges, without allocations and interface dispatch.
+//
+// The tree stays the editable and printable representation, recompile after changes.
+// The metrics of the tree, see tree.WithMetrics, are notified on Compile and Match.
+type Matcher struct {
+	// sorted, disjunct ranges
+	segs []segment
+
+	// the items, referenced by index from segs
+	items []Item
+
+	// the nesting level of the items, for the metrics
+	depths []int
+
+	// from the compiled tree, maybe nil
+	metrics tree.Metrics
+}
+
+// segment, [base, last] maps to items[item]
+type segment struct {
+	base inet.IP
+	last inet.IP
+	item int
+}
+
+// Compile returns a Matcher for the tree t, built from Items.
+// Compile panics if t holds other items than Item.
+func Compile(t *tree.Tree) *Matcher {
+	start := time.Now()
+	m := &Matcher{metrics: t.Metrics()}
+
+	_ = t.Walk(func(depth int, it, _ tree.Interface, childs []tree.Interface) error {
+		item := it.(Item)
+		m.items = append(m.items, item)
+		m.depths = append(m.depths, depth+1)
+		idx := len(m.items) - 1
+
+		// childs are sorted, the gaps between them belong to item
+		bs := make([]inet.Block, 0, len(childs))
+		for _, c := range childs {
+			bs = append(bs, c.(Item).Block)
+		}
+		for _, g := range inet.Gaps(item.Block, bs) {
+			m.segs = append(m.segs, segment{base: g.Base(), last: g.Last(), item: idx})
+		}
+		return nil
+	})
+
+	// walk is pre-order, parents before childs, sort the segments by address
+	sort.Slice(m.segs, func(i, j int) bool { return m.segs[i].base.Less(m.segs[j].base) })
+
+	if m.metrics != nil {
+		m.metrics.Build(len(m.items), t.Depth(), time.Since(start))
+	}
+	return m
+}
+
+// Match returns the longest-prefix-match for ip.
+// ok is false if ip isn't covered by any item.
+func (m *Matcher) Match(ip inet.IP) (match Item, ok bool) {
+	if m == nil {
+		return
+	}
+	segs := m.segs
+
+	// find first segment with base > ip
+	l, r := 0, len(segs)
+	for l < r {
+		h := int(uint(l+r) >> 1)
+		if ip.Less(segs[h].base) {
+			r = h
+		} else {
+			l = h + 1
+		}
+	}
+
+	// segment before may contain ip
+	if l == 0 || segs[l-1].last.Less(ip) {
+		if m.metrics != nil {
+			m.metrics.Lookup(false, 0)
+		}
+		return
+	}
+
+	i := segs[l-1].item
+	if m.metrics != nil {
+		m.metrics.Lookup(true, m.depths[i])
+	}
+	return m.items[i], true
+}
+
+// Len returns the number of flattened ranges in m.
+func (m *Matcher) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.segs)
+}
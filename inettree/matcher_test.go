@@ -0,0 +1,173 @@
+package inettree
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+// randItems, random v4 and v6 CIDRs, nested but never partially overlapping
+func randItems(prng *rand.Rand, n int) []tree.Interface {
+	items := make([]tree.Interface, 0, n)
+	for i := 0; i < n; i++ {
+		var s string
+		if prng.Intn(2) == 0 {
+			s = fmt.Sprintf("10.%d.%d.0/%d", prng.Intn(4), prng.Intn(256), 8+prng.Intn(17))
+		} else {
+			s = fmt.Sprintf("2001:db8:%x::/%d", prng.Intn(16), 32+prng.Intn(17))
+		}
+		b, err := inet.ParseBlock(s)
+		if err != nil {
+			panic(err)
+		}
+		items = append(items, Item{Block: b.CoveringCIDR(), Text: s})
+	}
+	return items
+}
+
+// randIPs, random v4 and v6 addresses, mostly around the randItems
+func randIPs(prng *rand.Rand, n int) []inet.IP {
+	ips := make([]inet.IP, 0, n)
+	for i := 0; i < n; i++ {
+		var s string
+		if prng.Intn(2) == 0 {
+			s = fmt.Sprintf("%d.%d.%d.%d", 9+prng.Intn(3), prng.Intn(5), prng.Intn(256), prng.Intn(256))
+		} else {
+			s = fmt.Sprintf("2001:db8:%x::%x", prng.Intn(32), prng.Intn(1<<16))
+		}
+		ip, err := inet.ParseIP(s)
+		if err != nil {
+			panic(err)
+		}
+		ips = append(ips, ip)
+	}
+	return ips
+}
+
+func TestMatcher(t *testing.T) {
+	prng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 50; i++ {
+		// dups are expected, just ignored by the tree
+		tr, _ := tree.New(randItems(prng, 1+prng.Intn(200)))
+		m := Compile(tr)
+
+		for _, ip := range randIPs(prng, 500) {
+			want, wantOK := LookupIP(tr, ip)
+			got, gotOK := m.Match(ip)
+			if gotOK != wantOK || got.Block != want.Block {
+				t.Fatalf("Match(%v) = %v, %v, want %v, %v", ip, got, gotOK, want, wantOK)
+			}
+		}
+	}
+}
+
+// countMetrics counts the hits and sums the depths
+type countMetrics struct {
+	hits, misses, depths, builds int
+}
+
+func (m *countMetrics) Lookup(hit bool, depth int) {
+	if hit {
+		m.hits++
+	} else {
+		m.misses++
+	}
+	m.depths += depth
+}
+
+func (m *countMetrics) Build(int, int, time.Duration) {
+	m.builds++
+}
+
+func TestMatcherMetrics(t *testing.T) {
+	prng := rand.New(rand.NewSource(1))
+
+	tm, mm := &countMetrics{}, &countMetrics{}
+	tr, _ := tree.New(randItems(prng, 200), tree.WithMetrics(tm))
+	m := Compile(tr)
+
+	// redirect the metrics of the matcher
+	m.metrics = mm
+
+	for _, ip := range randIPs(prng, 500) {
+		_, _ = LookupIP(tr, ip)
+		_, _ = m.Match(ip)
+	}
+
+	// the tree counted its own build, Compile the matcher build
+	if tm.builds != 2 {
+		t.Errorf("builds, got %d, want 2", tm.builds)
+	}
+	tm.builds, mm.builds = 0, 0
+
+	if *tm != *mm {
+		t.Errorf("Match metrics %+v differ from Lookup metrics %+v", *mm, *tm)
+	}
+	if tm.hits == 0 || tm.misses == 0 {
+		t.Errorf("metrics, want hits and misses, got %+v", *tm)
+	}
+}
+
+func TestMatcherEmpty(t *testing.T) {
+	ip, _ := inet.ParseIP("10.0.0.1")
+
+	var m *Matcher
+	if _, ok := m.Match(ip); ok {
+		t.Errorf("nil Matcher, Match(%v), want !ok", ip)
+	}
+
+	tr, _ := tree.New(nil)
+	if _, ok := Compile(tr).Match(ip); ok {
+		t.Errorf("empty Matcher, Match(%v), want !ok", ip)
+	}
+
+	if _, ok := Compile(tr).Match(inet.IP{}); ok {
+		t.Errorf("empty Matcher, Match(invalid IP), want !ok")
+	}
+}
+
+func BenchmarkMatch(b *testing.B) {
+	for _, n := range []int{1_000, 100_000} {
+		prng := rand.New(rand.NewSource(1))
+		tr, _ := tree.New(randItems(prng, n))
+		m := Compile(tr)
+		ips := randIPs(prng, 1024)
+
+		b.Run(fmt.Sprintf("LookupIP/n=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = LookupIP(tr, ips[i%len(ips)])
+			}
+		})
+
+		b.Run(fmt.Sprintf("Match/n=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = m.Match(ips[i%len(ips)])
+			}
+		})
+	}
+}
+
+func BenchmarkString(b *testing.B) {
+	prng := rand.New(rand.NewSource(1))
+	tr, _ := tree.New(randItems(prng, 100_000))
+
+	// the Items render their blocks, no Text
+	var items []tree.Interface
+	_ = tr.Walk(func(d int, it, p tree.Interface, cs []tree.Interface) error {
+		items = append(items, Item{Block: it.(Item).Block})
+		return nil
+	})
+	tr, _ = tree.New(items)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = tr.String()
+	}
+}
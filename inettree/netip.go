@@ -0,0 +1,90 @@
+//go:build go1.18
+// +build go1.18
+
+package inettree
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+// ItemFromPrefix returns the Item for the masked prefix p with text.
+// IPv4-mapped IPv6 prefixes are unmapped to IPv4.
+// If p is invalid, returns Item{} and error.
+func ItemFromPrefix(p netip.Prefix, text string) (Item, error) {
+	if !p.IsValid() {
+		return Item{}, fmt.Errorf("invalid Block: %v", p)
+	}
+
+	addr, bits := p.Addr(), p.Bits()
+	if addr.Is4In6() {
+		if bits < 96 {
+			return Item{}, fmt.Errorf("invalid Block: %v", p)
+		}
+		addr, bits = addr.Unmap(), bits-96
+	}
+
+	ip, err := inet.FromStdIP(addr.AsSlice())
+	if err != nil {
+		return Item{}, err
+	}
+	b, err := ip.Prefix(bits)
+	if err != nil {
+		return Item{}, err
+	}
+	return Item{Block: b, Text: text}, nil
+}
+
+// compiler check, PrefixItem implements tree.Interface and tree.Payloader
+var _ tree.Interface = PrefixItem{}
+var _ tree.Payloader = PrefixItem{}
+
+// PrefixItem augments netip.Prefix with payload, implementing the tree.Interface,
+// for code standardized on net/netip without conversion to inet.Block.
+//
+// The prefix must be masked, see netip.Prefix.Masked. IPv4 prefixes sort before IPv6 prefixes,
+// IPv4-mapped IPv6 prefixes are IPv6 prefixes, see ItemFromPrefix for unmapping.
+// Prefixes never overlap partially, they are nested or disjunct.
+type PrefixItem struct {
+	Prefix netip.Prefix
+
+	// any payload, printed by String and exported by the tree exporters
+	Value interface{}
+}
+
+// Less implements the tree.Interface for PrefixItem
+func (a PrefixItem) Less(i tree.Interface) bool {
+	b := i.(PrefixItem)
+	if c := a.Prefix.Addr().Compare(b.Prefix.Addr()); c != 0 {
+		return c < 0
+	}
+	return a.Prefix.Bits() < b.Prefix.Bits()
+}
+
+// Equals implements the tree.Interface for PrefixItem
+func (a PrefixItem) Equals(i tree.Interface) bool {
+	b := i.(PrefixItem)
+	return a.Prefix == b.Prefix
+}
+
+// Covers implements the tree.Interface for PrefixItem
+func (a PrefixItem) Covers(i tree.Interface) bool {
+	b := i.(PrefixItem)
+	return a.Prefix.Bits() < b.Prefix.Bits() && a.Prefix.Contains(b.Prefix.Addr())
+}
+
+// Payload implements the tree.Payloader interface for PrefixItem
+func (a PrefixItem) Payload() interface{} {
+	return a.Value
+}
+
+// String implements the tree.Interface for PrefixItem
+func (a PrefixItem) String() string {
+	if a.Value == nil {
+		return a.Prefix.String()
+	}
+	return fmt.Sprintf("%s %v", a.Prefix, a.Value)
+}
@@ -0,0 +1,77 @@
+//go:build go1.18
+// +build go1.18
+
+package inettree
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+func TestItemFromPrefix(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"10.0.0.1/8", "10.0.0.0/8"},
+		{"2001:db8::1/32", "2001:db8::/32"},
+		{"::ffff:10.0.0.1/104", "10.0.0.0/8"},
+		{"::/0", "::/0"},
+	}
+
+	for _, tt := range tests {
+		item, err := ItemFromPrefix(netip.MustParsePrefix(tt.in), "text")
+		if err != nil {
+			t.Errorf("ItemFromPrefix(%s), unexpected error: %v", tt.in, err)
+			continue
+		}
+		if got := item.Block.String(); got != tt.want || item.Text != "text" {
+			t.Errorf("ItemFromPrefix(%s), got %s, %q, want %s", tt.in, got, item.Text, tt.want)
+		}
+	}
+
+	for _, p := range []netip.Prefix{{}, netip.MustParsePrefix("::ffff:0.0.0.0/95")} {
+		if _, err := ItemFromPrefix(p, ""); err == nil {
+			t.Errorf("ItemFromPrefix(%v), want error", p)
+		}
+	}
+}
+
+func TestPrefixItem(t *testing.T) {
+	var items []tree.Interface
+	for _, s := range []string{"2001:db8::/32", "10.0.0.0/8", "10.0.0.0/24", "10.0.1.0/24", "::ffff:10.0.0.0/104", "::/0"} {
+		items = append(items, PrefixItem{Prefix: netip.MustParsePrefix(s)})
+	}
+	items = append(items, PrefixItem{Prefix: netip.MustParsePrefix("10.0.0.0/16"), Value: "site"})
+
+	tr, err := tree.New(items, tree.WithValidation(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `▼
+├─ 10.0.0.0/8
+│  └─ 10.0.0.0/16 site
+│     ├─ 10.0.0.0/24
+│     └─ 10.0.1.0/24
+└─ ::/0
+   ├─ ::ffff:10.0.0.0/104
+   └─ 2001:db8::/32
+`
+	if got := tr.String(); got != want {
+		t.Errorf("tree, got:\n%swant:\n%s", got, want)
+	}
+
+	ip := PrefixItem{Prefix: netip.MustParsePrefix("10.0.0.99/32")}
+	if got := tr.Lookup(ip); got.String() != "10.0.0.0/24" {
+		t.Errorf("Lookup(%v), got %v, want 10.0.0.0/24", ip, got)
+	}
+
+	js, _ := tr.MarshalJSON()
+	if want := `"payload":"site"`; !strings.Contains(string(js), want) {
+		t.Errorf("MarshalJSON, got %s, want %s", js, want)
+	}
+}
@@ -0,0 +1,50 @@
+package inettree
+
+import (
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+// Set wraps the exact Matcher of a tree with a probabilistic pre-filter, see inet.Bloom.
+//
+// Useful for very large sets where most queries miss, e.g. threat-intel feeds,
+// the misses are mostly answered by the filter without touching the ranges.
+type Set struct {
+	bloom   *inet.Bloom
+	matcher *Matcher
+}
+
+// NewSet returns the Set for the tree t, built from Items.
+// fpRate is the false positive rate of the pre-filter, in the open interval (0, 1).
+func NewSet(t *tree.Tree, fpRate float64) (*Set, error) {
+	// the root items cover all others
+	var bs []inet.Block
+	_ = t.Walk(func(depth int, item, _ tree.Interface, _ []tree.Interface) error {
+		if depth == 0 {
+			bs = append(bs, item.(Item).Block)
+		}
+		return nil
+	})
+
+	bloom, err := inet.NewBloom(bs, fpRate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Set{bloom: bloom, matcher: Compile(t)}, nil
+}
+
+// MayContain reports whether ip is probably covered by the set.
+// A false result is always correct.
+func (s *Set) MayContain(ip inet.IP) bool {
+	return s.bloom.MayContain(ip)
+}
+
+// Match returns the longest-prefix-match for ip, the pre-filter is asked first.
+// ok is false if ip isn't covered by any item.
+func (s *Set) Match(ip inet.IP) (match Item, ok bool) {
+	if !s.bloom.MayContain(ip) {
+		return
+	}
+	return s.matcher.Match(ip)
+}
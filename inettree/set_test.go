@@ -0,0 +1,35 @@
+package inettree
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+func TestSet(t *testing.T) {
+	prng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 20; i++ {
+		tr, _ := tree.New(randItems(prng, 1+prng.Intn(200)))
+		s, err := NewSet(tr, 0.01)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, ip := range randIPs(prng, 500) {
+			want, wantOK := LookupIP(tr, ip)
+			if wantOK && !s.MayContain(ip) {
+				t.Fatalf("MayContain(%v) = false, want true", ip)
+			}
+			got, gotOK := s.Match(ip)
+			if gotOK != wantOK || got.Block != want.Block {
+				t.Fatalf("Match(%v) = %v, %v, want %v, %v", ip, got, gotOK, want, wantOK)
+			}
+		}
+	}
+
+	if _, err := NewSet(&tree.Tree{}, 1); err == nil {
+		t.Errorf("NewSet(fpRate=1), want error")
+	}
+}
@@ -0,0 +1,30 @@
+package ipam_test
+
+import (
+	"fmt"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/ipam"
+)
+
+func ExampleAllocator() {
+	pool, _ := inet.ParseBlock("10.0.0.0/24")
+	a, _ := ipam.New(pool, ipam.WithStrategy(ipam.BestFit))
+
+	for _, bits := range []int{26, 28, 27, 28} {
+		b, err := a.Allocate(bits)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println(b)
+	}
+	fmt.Println("free:", a.Free())
+
+	// Output:
+	// 10.0.0.0/26
+	// 10.0.0.64/28
+	// 10.0.0.96/27
+	// 10.0.0.80/28
+	// free: [10.0.0.128/25]
+}
@@ -0,0 +1,384 @@
+// Package ipam allocates CIDRs from a pool, the bookkeeping every IPAM tool reimplements.
+package ipam
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+)
+
+var (
+	// ErrExhausted is returned by Allocate if the pool has no free CIDR of the requested size.
+	ErrExhausted = errors.New("ipam: pool exhausted")
+
+	// ErrNotAllocated is returned by Release for blocks not allocated or reserved.
+	ErrNotAllocated = errors.New("ipam: block not allocated")
+
+	// ErrNotFree is returned by Reserve for blocks overlapping allocated, reserved or quarantined space.
+	ErrNotFree = errors.New("ipam: block not free")
+
+	// ErrConflict is returned if a change still conflicts with concurrent changes after all retries, see WithRetries.
+	ErrConflict = errors.New("ipam: conflict with concurrent change")
+)
+
+// State of a block in the pool.
+type State int
+
+const (
+	// Allocated by Allocate.
+	Allocated State = iota
+
+	// Reserved by Reserve, never handed out by Allocate.
+	Reserved
+
+	// Quarantined after Release, the cool-down before the block is free again, see WithQuarantine.
+	Quarantined
+)
+
+var stateNames = [...]string{
+	Allocated:   "allocated",
+	Reserved:    "reserved",
+	Quarantined: "quarantined",
+}
+
+// String implements the fmt.Stringer interface.
+func (s State) String() string {
+	if s < 0 || int(s) >= len(stateNames) {
+		return "State(?)"
+	}
+	return stateNames[s]
+}
+
+// Entry is a block in the pool, not free.
+type Entry struct {
+	Block inet.Block
+	State State
+
+	// the end of the quarantine, zero for other states
+	Until time.Time
+}
+
+// Event describes a change of the pool, see WithObserver.
+type Event struct {
+	// the operation, "Allocate", "Reserve" or "Release"
+	Op string
+
+	// the changed block
+	Block inet.Block
+
+	// the entry of the block before and after the change, nil if free
+	Before, After *Entry
+}
+
+// Observer is called for every committed change, e.g. for audit trails or metrics.
+type Observer func(Event)
+
+// Option configures New.
+type Option func(*Allocator)
+
+// WithStrategy sets the allocation strategy, default FirstFit.
+func WithStrategy(s Strategy) Option {
+	return func(a *Allocator) { a.strategy = s }
+}
+
+// WithQuarantine sets the cool-down for released blocks, default 0.
+// Quarantined blocks are not handed out again by Allocate until the cool-down has passed.
+func WithQuarantine(d time.Duration) Option {
+	return func(a *Allocator) { a.quarantine = d }
+}
+
+// WithRetries sets the number of retries for changes conflicting with concurrent changes, default 8.
+// After the last retry ErrConflict is returned.
+func WithRetries(n int) Option {
+	return func(a *Allocator) { a.retries = n }
+}
+
+// WithClock sets the clock for the quarantine, default time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(a *Allocator) { a.now = now }
+}
+
+// WithObserver sets the observer, called synchronously after every committed
+// Allocate, Reserve and Release, outside the commit lock.
+func WithObserver(fn Observer) Option {
+	return func(a *Allocator) { a.observer = fn }
+}
+
+// Allocator hands out CIDRs from the pool.
+//
+// The allocator is safe for concurrent use. The changes are optimistic, computed without lock
+// on a versioned state and committed only if no concurrent change was committed in between,
+// otherwise the change is retried.
+type Allocator struct {
+	pool       inet.Block
+	strategy   Strategy
+	quarantine time.Duration
+	retries    int
+	now        func() time.Time
+	store      Store
+	observer   Observer
+
+	// the current *state, replaced on commit
+	state atomic.Value
+
+	// serializes the commits
+	mu sync.Mutex
+}
+
+// state is an immutable version of the taken blocks
+type state struct {
+	version uint64
+	entries entries
+}
+
+// entries, the taken blocks, sorted, never overlapping
+type entries []Entry
+
+// New returns the allocator for the CIDR pool.
+// Returns an error if pool isn't a CIDR.
+func New(pool inet.Block, opts ...Option) (*Allocator, error) {
+	if !pool.IsCIDR() {
+		return nil, fmt.Errorf("ipam: pool must be a CIDR: %v", pool)
+	}
+
+	a := &Allocator{pool: pool, strategy: FirstFit, retries: 8, now: time.Now}
+	for _, opt := range opts {
+		opt(a)
+	}
+	a.state.Store(&state{})
+
+	if a.store != nil {
+		if err := a.restore(); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// Pool returns the pool of the allocator.
+func (a *Allocator) Pool() inet.Block {
+	return a.pool
+}
+
+// Allocate returns a free CIDR with prefix length bits, chosen by the strategy.
+// Reserved and quarantined space is never handed out.
+// Returns ErrExhausted if there is no free CIDR of this size,
+// or an error if bits is shorter than the prefix length of the pool or too long.
+func (a *Allocator) Allocate(bits int) (inet.Block, error) {
+	maxBits := 128
+	if a.pool.Is4() {
+		maxBits = 32
+	}
+	if bits < a.pool.Bits() || bits > maxBits {
+		return inet.Block{}, fmt.Errorf("ipam: invalid prefix length /%d for pool %v", bits, a.pool)
+	}
+
+	var c inet.Block
+	err := a.update(func(es entries) (entries, error) {
+		var ok bool
+		c, ok = a.strategy(es.free(a.pool), bits)
+		if !ok {
+			return nil, fmt.Errorf("%w: no free /%d in %v", ErrExhausted, bits, a.pool)
+		}
+		if !es.isFree(a.pool, c) {
+			return nil, fmt.Errorf("ipam: strategy returned %v, not free in %v", c, a.pool)
+		}
+		return es.with(Entry{Block: c, State: Allocated}), nil
+	})
+	if err != nil {
+		return inet.Block{}, err
+	}
+	a.notify("Allocate", c, nil, &Entry{Block: c, State: Allocated})
+	return c, nil
+}
+
+// Reserve marks the block b in the pool as reserved, b may be any range.
+// Returns ErrNotFree if b overlaps allocated, reserved or quarantined space,
+// or an error if b isn't in the pool.
+func (a *Allocator) Reserve(b inet.Block) error {
+	if !(b == a.pool || a.pool.Covers(b)) {
+		return fmt.Errorf("ipam: %v not in pool %v", b, a.pool)
+	}
+
+	err := a.update(func(es entries) (entries, error) {
+		if !es.isFree(a.pool, b) {
+			return nil, fmt.Errorf("%w: %v", ErrNotFree, b)
+		}
+		return es.with(Entry{Block: b, State: Reserved}), nil
+	})
+	if err != nil {
+		return err
+	}
+	a.notify("Reserve", b, nil, &Entry{Block: b, State: Reserved})
+	return nil
+}
+
+// Release returns the allocated or reserved block b to the pool.
+// Allocated blocks are quarantined first, if a quarantine is configured.
+// Returns ErrNotAllocated if b isn't allocated or reserved.
+func (a *Allocator) Release(b inet.Block) error {
+	// the entry before and after, from the committed try
+	var before, after *Entry
+
+	err := a.update(func(es entries) (entries, error) {
+		i := es.search(b)
+		if i >= len(es) || es[i].Block != b || es[i].State == Quarantined {
+			return nil, fmt.Errorf("%w: %v", ErrNotAllocated, b)
+		}
+		before, after = &Entry{}, nil
+		*before = es[i]
+
+		out := make(entries, 0, len(es))
+		out = append(out, es[:i]...)
+		if es[i].State == Allocated && a.quarantine > 0 {
+			after = &Entry{Block: b, State: Quarantined, Until: a.now().Add(a.quarantine)}
+			out = append(out, *after)
+		}
+		return append(out, es[i+1:]...), nil
+	})
+	if err != nil {
+		return err
+	}
+	a.notify("Release", b, before, after)
+	return nil
+}
+
+// Allocated returns the allocated CIDRs, sorted.
+func (a *Allocator) Allocated() []inet.Block {
+	var out []inet.Block
+	for _, e := range a.current() {
+		if e.State == Allocated {
+			out = append(out, e.Block)
+		}
+	}
+	return out
+}
+
+// Entries returns the allocated, reserved and still quarantined blocks, sorted.
+func (a *Allocator) Entries() []Entry {
+	es := a.current()
+
+	out := make([]Entry, len(es))
+	copy(out, es)
+	return out
+}
+
+// Free returns the free space of the pool as CIDRs, sorted.
+func (a *Allocator) Free() []inet.Block {
+	var out []inet.Block
+	for _, r := range a.current().free(a.pool) {
+		out = append(out, r.CIDRs()...)
+	}
+	return out
+}
+
+// notify the observer, if any
+func (a *Allocator) notify(op string, b inet.Block, before, after *Entry) {
+	if a.observer != nil {
+		a.observer(Event{Op: op, Block: b, Before: before, After: after})
+	}
+}
+
+// load the current state
+func (a *Allocator) load() *state {
+	return a.state.Load().(*state)
+}
+
+// current returns the entries of the current state, the passed quarantines expired
+func (a *Allocator) current() entries {
+	return a.load().entries.expired(a.now())
+}
+
+// update computes the new entries by fn from the current entries and commits them,
+// retried if a concurrent change was committed in between.
+func (a *Allocator) update(fn func(entries) (entries, error)) error {
+	for try := 0; try <= a.retries; try++ {
+		s := a.load()
+
+		es, err := fn(s.entries.expired(a.now()))
+		if err != nil {
+			return err
+		}
+
+		err = a.commit(s.version, es)
+		if !errors.Is(err, ErrConflict) {
+			return err
+		}
+	}
+	return ErrConflict
+}
+
+// commit saves the new entries to the store and replaces the state,
+// returns ErrConflict if the current state isn't the version the entries are based on.
+func (a *Allocator) commit(version uint64, es entries) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.load().version != version {
+		return ErrConflict
+	}
+
+	if a.store != nil {
+		if err := a.store.Save(Snapshot{Pool: a.pool, Entries: es}); err != nil {
+			return fmt.Errorf("ipam: save: %w", err)
+		}
+	}
+
+	a.state.Store(&state{version: version + 1, entries: es})
+	return nil
+}
+
+// expired returns the entries without the quarantines passed at now
+func (es entries) expired(now time.Time) entries {
+	var out entries
+	for _, e := range es {
+		if e.State == Quarantined && !now.Before(e.Until) {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// free returns the free ranges of the pool, sorted
+func (es entries) free(pool inet.Block) []inet.Block {
+	taken := make([]inet.Block, len(es))
+	for i, e := range es {
+		taken[i] = e.Block
+	}
+	return inet.Gaps(pool, taken)
+}
+
+// isFree reports whether c is in the pool and doesn't overlap any entry
+func (es entries) isFree(pool, c inet.Block) bool {
+	if !(c == pool || pool.Covers(c)) {
+		return false
+	}
+	i := es.search(c)
+	if i < len(es) && !es[i].Block.IsDisjunct(c) {
+		return false
+	}
+	if i > 0 && !es[i-1].Block.IsDisjunct(c) {
+		return false
+	}
+	return true
+}
+
+// search returns the position of b in the sorted entries
+func (es entries) search(b inet.Block) int {
+	return sort.Search(len(es), func(i int) bool { return !es[i].Block.Less(b) })
+}
+
+// with returns a copy of the sorted entries with e inserted
+func (es entries) with(e Entry) entries {
+	i := es.search(e.Block)
+	out := make(entries, 0, len(es)+1)
+	out = append(out, es[:i]...)
+	out = append(out, e)
+	return append(out, es[i:]...)
+}
@@ -0,0 +1,272 @@
+package ipam
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+)
+
+func mustBlock(s string) inet.Block {
+	b, err := inet.ParseBlock(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// mkAllocator with the pool and the blocks already allocated
+func mkAllocator(pool string, strategy Strategy, allocated ...string) *Allocator {
+	a, err := New(mustBlock(pool), WithStrategy(strategy))
+	if err != nil {
+		panic(err)
+	}
+	var es entries
+	for _, s := range allocated {
+		es = es.with(Entry{Block: mustBlock(s), State: Allocated})
+	}
+	a.state.Store(&state{entries: es})
+	return a
+}
+
+func TestStrategies(t *testing.T) {
+	// free: 10.0.0.0-10.0.0.127, 10.0.0.160-10.0.0.207
+	allocated := []string{"10.0.0.128/27", "10.0.0.208/28", "10.0.0.224/27"}
+
+	tests := []struct {
+		name     string
+		strategy Strategy
+		want     string
+	}{
+		{"FirstFit", FirstFit, "10.0.0.0/28"},
+		{"BestFit", BestFit, "10.0.0.160/28"},
+		{"BuddyFit", BuddyFit, "10.0.0.192/28"},
+	}
+
+	for _, tt := range tests {
+		a := mkAllocator("10.0.0.0/24", tt.strategy, allocated...)
+		got, err := a.Allocate(28)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got != mustBlock(tt.want) {
+			t.Errorf("%s: Allocate(28), got %v, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAllocateRelease(t *testing.T) {
+	for _, strategy := range []Strategy{FirstFit, BestFit, BuddyFit} {
+		a := mkAllocator("2001:db8::/62", strategy)
+
+		// exhaust the pool
+		var got []inet.Block
+		for i := 0; i < 4; i++ {
+			b, err := a.Allocate(64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, b)
+		}
+		if _, err := a.Allocate(64); !errors.Is(err, ErrExhausted) {
+			t.Errorf("Allocate from exhausted pool, got %v, want ErrExhausted", err)
+		}
+		if len(a.Free()) != 0 || len(a.Allocated()) != 4 {
+			t.Errorf("exhausted pool, Free: %v, Allocated: %v", a.Free(), a.Allocated())
+		}
+
+		if err := a.Release(got[2]); err != nil {
+			t.Fatal(err)
+		}
+		if err := a.Release(got[2]); !errors.Is(err, ErrNotAllocated) {
+			t.Errorf("Release twice, got %v, want ErrNotAllocated", err)
+		}
+		if free := a.Free(); fmt.Sprint(free) != fmt.Sprintf("[%v]", got[2]) {
+			t.Errorf("Free, got %v, want [%v]", free, got[2])
+		}
+	}
+}
+
+func TestAllocateErrors(t *testing.T) {
+	if _, err := New(mustBlock("10.0.0.1-10.0.0.5")); err == nil {
+		t.Errorf("New with range, want error")
+	}
+
+	a := mkAllocator("10.0.0.0/24", FirstFit)
+	for _, bits := range []int{23, 33, -1} {
+		if _, err := a.Allocate(bits); err == nil {
+			t.Errorf("Allocate(%d), want error", bits)
+		}
+	}
+
+	// broken custom strategy
+	a = mkAllocator("10.0.0.0/24", func([]inet.Block, int) (inet.Block, bool) {
+		return mustBlock("10.0.1.0/25"), true
+	})
+	if _, err := a.Allocate(25); err == nil {
+		t.Errorf("Allocate outside of pool, want error")
+	}
+}
+
+func TestReserveQuarantine(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	a, _ := New(mustBlock("10.0.0.0/24"), WithQuarantine(time.Hour), WithClock(clock))
+
+	// reserve the first range, not a CIDR
+	if err := a.Reserve(mustBlock("10.0.0.0-10.0.0.9")); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Reserve(mustBlock("10.0.0.8/29")); !errors.Is(err, ErrNotFree) {
+		t.Errorf("Reserve overlapping, got %v, want ErrNotFree", err)
+	}
+	if err := a.Reserve(mustBlock("10.0.1.0/29")); err == nil {
+		t.Errorf("Reserve outside of pool, want error")
+	}
+
+	b, _ := a.Allocate(26)
+	if b != mustBlock("10.0.0.64/26") {
+		t.Errorf("Allocate(26), got %v, want 10.0.0.64/26", b)
+	}
+
+	if err := a.Release(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Release(b); !errors.Is(err, ErrNotAllocated) {
+		t.Errorf("Release quarantined, got %v, want ErrNotAllocated", err)
+	}
+
+	want := []Entry{
+		{Block: mustBlock("10.0.0.0-10.0.0.9"), State: Reserved},
+		{Block: b, State: Quarantined, Until: now.Add(time.Hour)},
+	}
+	if got := a.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Entries, got %v, want %v", got, want)
+	}
+
+	// quarantined, not reused
+	if c, _ := a.Allocate(26); c == b {
+		t.Errorf("Allocate(26), quarantined %v reused", c)
+	}
+
+	// cool-down passed
+	now = now.Add(time.Hour)
+	if c, _ := a.Allocate(26); c != b {
+		t.Errorf("Allocate(26) after quarantine, got %v, want %v", c, b)
+	}
+
+	// reserved blocks are released without quarantine
+	if err := a.Release(mustBlock("10.0.0.0-10.0.0.9")); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(a.Entries()); got != 2 {
+		t.Errorf("Entries after release of reservation, got %d, want 2", got)
+	}
+}
+
+func TestObserver(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	var got []string
+	observer := func(e Event) {
+		got = append(got, fmt.Sprintf("%s %v %v -> %v", e.Op, e.Block, e.Before, e.After))
+	}
+
+	a, _ := New(mustBlock("10.0.0.0/24"), WithQuarantine(time.Hour), WithClock(clock), WithObserver(observer))
+
+	r := mustBlock("10.0.0.0/26")
+	_ = a.Reserve(r)
+	b, _ := a.Allocate(26)
+	_ = a.Release(b)
+	_ = a.Release(r)
+
+	// errors are not observed
+	_ = a.Release(b)
+	_, _ = a.Allocate(8)
+
+	want := []string{
+		"Reserve 10.0.0.0/26 <nil> -> &{10.0.0.0/26 reserved 0001-01-01 00:00:00 +0000 UTC}",
+		"Allocate 10.0.0.64/26 <nil> -> &{10.0.0.64/26 allocated 0001-01-01 00:00:00 +0000 UTC}",
+		"Release 10.0.0.64/26 &{10.0.0.64/26 allocated 0001-01-01 00:00:00 +0000 UTC} -> &{10.0.0.64/26 quarantined 2024-01-01 01:00:00 +0000 UTC}",
+		"Release 10.0.0.0/26 &{10.0.0.0/26 reserved 0001-01-01 00:00:00 +0000 UTC} -> <nil>",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events, got:\n%v\nwant:\n%v", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestConcurrentAllocate(t *testing.T) {
+	a, _ := New(mustBlock("10.0.0.0/24"), WithRetries(1_000))
+
+	var mu sync.Mutex
+	seen := make(map[inet.Block]bool)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 32; i++ {
+				b, err := a.Allocate(32)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				if seen[b] {
+					t.Errorf("Allocate, %v handed out twice", b)
+				}
+				seen[b] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != 256 || len(a.Free()) != 0 {
+		t.Errorf("concurrent Allocate, got %d blocks, free %v", len(seen), a.Free())
+	}
+}
+
+func TestConflict(t *testing.T) {
+	var a *Allocator
+
+	// the strategy races with a concurrent Allocate, every time
+	racing := func(free []inet.Block, bits int) (inet.Block, bool) {
+		c, ok := FirstFit(free, bits)
+		if _, err := a.Allocate(30); err != nil {
+			t.Fatal(err)
+		}
+		return c, ok
+	}
+
+	a, _ = New(mustBlock("10.0.0.0/24"), WithRetries(2))
+
+	// the nested Allocate uses the racing strategy as well, stop the recursion
+	depth := 0
+	a.strategy = func(free []inet.Block, bits int) (inet.Block, bool) {
+		depth++
+		defer func() { depth-- }()
+		if depth > 1 {
+			return FirstFit(free, bits)
+		}
+		return racing(free, bits)
+	}
+
+	if _, err := a.Allocate(26); !errors.Is(err, ErrConflict) {
+		t.Errorf("Allocate, got %v, want ErrConflict", err)
+	}
+
+	// 3 tries, 3 concurrent allocations committed
+	if got := len(a.Allocated()); got != 3 {
+		t.Errorf("Allocated, got %d, want 3", got)
+	}
+}
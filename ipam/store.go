@@ -0,0 +1,164 @@
+package ipam
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+)
+
+// Snapshot is the state of an Allocator, see Store.
+type Snapshot struct {
+	Pool    inet.Block
+	Entries []Entry
+}
+
+// Store persists the allocator state, see WithStore.
+type Store interface {
+	// Load returns the last saved snapshot.
+	// Returns an error satisfying os.IsNotExist if nothing was saved yet.
+	Load() (Snapshot, error)
+
+	// Save replaces the saved snapshot.
+	Save(Snapshot) error
+}
+
+// WithStore sets the store, New restores the state from the store and
+// every change of the allocator is saved to the store before it becomes visible.
+func WithStore(s Store) Option {
+	return func(a *Allocator) { a.store = s }
+}
+
+// Snapshot returns the current state of the allocator.
+func (a *Allocator) Snapshot() Snapshot {
+	return Snapshot{Pool: a.pool, Entries: a.Entries()}
+}
+
+// restore the state from the store, the pool must match
+func (a *Allocator) restore() error {
+	s, err := a.store.Load()
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if s.Pool != a.pool {
+		return fmt.Errorf("ipam: stored pool %v differs from %v", s.Pool, a.pool)
+	}
+
+	var es entries
+	for _, e := range s.Entries {
+		if !es.isFree(a.pool, e.Block) {
+			return fmt.Errorf("ipam: stored entry %v overlaps or not in pool %v", e.Block, a.pool)
+		}
+		es = es.with(e)
+	}
+	a.state.Store(&state{entries: es})
+	return nil
+}
+
+// FileStore is the reference Store, the snapshot is saved as JSON file at Path.
+// The file is replaced atomically, a crash never leaves a partially written snapshot.
+type FileStore struct {
+	Path string
+}
+
+// the JSON form of Entry
+type jsonEntry struct {
+	Block string    `json:"block"`
+	State string    `json:"state"`
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// the JSON form of Snapshot
+type jsonSnapshot struct {
+	Pool    string      `json:"pool"`
+	Entries []jsonEntry `json:"entries"`
+}
+
+// Load implements the Store interface.
+func (f FileStore) Load() (Snapshot, error) {
+	data, err := ioutil.ReadFile(f.Path)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	var js jsonSnapshot
+	if err := json.Unmarshal(data, &js); err != nil {
+		return Snapshot{}, fmt.Errorf("ipam: %s: %w", f.Path, err)
+	}
+
+	var s Snapshot
+	if s.Pool, err = inet.ParseBlock(js.Pool); err != nil {
+		return Snapshot{}, fmt.Errorf("ipam: %s: %w", f.Path, err)
+	}
+	for _, je := range js.Entries {
+		var e Entry
+		if je.Until != nil {
+			e.Until = *je.Until
+		}
+		if e.Block, err = inet.ParseBlock(je.Block); err != nil {
+			return Snapshot{}, fmt.Errorf("ipam: %s: %w", f.Path, err)
+		}
+		if e.State, err = parseState(je.State); err != nil {
+			return Snapshot{}, fmt.Errorf("ipam: %s: %w", f.Path, err)
+		}
+		s.Entries = append(s.Entries, e)
+	}
+	return s, nil
+}
+
+// Save implements the Store interface.
+func (f FileStore) Save(s Snapshot) error {
+	js := jsonSnapshot{Pool: s.Pool.String(), Entries: []jsonEntry{}}
+	for _, e := range s.Entries {
+		je := jsonEntry{Block: e.Block.String(), State: e.State.String()}
+
+		// omitempty has no effect on structs, omit the zero time by nil
+		if !e.Until.IsZero() {
+			until := e.Until
+			je.Until = &until
+		}
+		js.Entries = append(js.Entries, je)
+	}
+
+	data, err := json.MarshalIndent(js, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write temp file and rename, atomic on POSIX filesystems
+	tmp, err := ioutil.TempFile(filepath.Dir(f.Path), filepath.Base(f.Path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.Path)
+}
+
+// parseState, the inverse of State.String
+func parseState(s string) (State, error) {
+	for i, name := range stateNames {
+		if s == name {
+			return State(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid state %q", s)
+}
@@ -0,0 +1,98 @@
+package ipam
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failStore fails on Save
+type failStore struct{}
+
+func (failStore) Load() (Snapshot, error) { return FileStore{"/does/not/exist"}.Load() }
+func (failStore) Save(Snapshot) error     { return errors.New("disk full") }
+
+func TestFileStore(t *testing.T) {
+	store := FileStore{Path: filepath.Join(t.TempDir(), "pool.json")}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	a, err := New(mustBlock("10.0.0.0/24"), WithStore(store), WithQuarantine(time.Hour), WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := a.Allocate(26)
+	_, _ = a.Allocate(28)
+	_ = a.Reserve(mustBlock("10.0.0.200-10.0.0.255"))
+	_ = a.Release(b)
+
+	// restart
+	a2, err := New(mustBlock("10.0.0.0/24"), WithStore(store), WithQuarantine(time.Hour), WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := a2.Snapshot(), a.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("restored, got %v, want %v", got, want)
+	}
+
+	// quarantine expired on restore
+	now = now.Add(time.Hour)
+	a3, _ := New(mustBlock("10.0.0.0/24"), WithStore(store), WithClock(clock))
+	if got := len(a3.Entries()); got != 2 {
+		t.Errorf("restored after quarantine, got %d entries, want 2", got)
+	}
+
+	// pool mismatch
+	if _, err := New(mustBlock("10.0.0.0/16"), WithStore(store)); err == nil {
+		t.Errorf("restore with other pool, want error")
+	}
+}
+
+func TestFileStoreUntil(t *testing.T) {
+	store := FileStore{Path: filepath.Join(t.TempDir(), "pool.json")}
+	until := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	want := Snapshot{Pool: mustBlock("10.0.0.0/24"), Entries: []Entry{
+		{Block: mustBlock("10.0.0.0/26"), State: Allocated},
+		{Block: mustBlock("10.0.0.64/26"), State: Quarantined, Until: until},
+	}}
+	if err := store.Save(want); err != nil {
+		t.Fatal(err)
+	}
+
+	// the zero time is omitted, not saved as 0001-01-01T00:00:00Z
+	data, err := ioutil.ReadFile(store.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(data), `"until"`); got != 1 {
+		t.Errorf("saved %d until keys, want 1:\n%s", got, data)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip, got %v, want %v", got, want)
+	}
+}
+
+func TestStoreFailure(t *testing.T) {
+	a, err := New(mustBlock("10.0.0.0/24"), WithStore(failStore{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Allocate(26); err == nil {
+		t.Errorf("Allocate with failing store, want error")
+	}
+	if got := a.Entries(); len(got) != 0 {
+		t.Errorf("failed Allocate is visible, got %v", got)
+	}
+}
@@ -0,0 +1,90 @@
+package ipam
+
+import (
+	"github.com/gaissmai/go-inet/v2/inet"
+)
+
+// Strategy chooses the CIDR with prefix length bits for an allocation from the free ranges,
+// sorted by address. ok is false if no free range can hold an aligned CIDR of that size.
+//
+// Predefined strategies are FirstFit, BestFit and BuddyFit, custom policies may be plugged in.
+type Strategy func(free []inet.Block, bits int) (cidr inet.Block, ok bool)
+
+var (
+	// FirstFit allocates the CIDR at the lowest free address.
+	FirstFit Strategy = firstFit
+
+	// BestFit allocates the CIDR in the smallest free range that fits,
+	// keeping the big ranges in one piece. Ties are broken by the lowest address.
+	BestFit Strategy = bestFit
+
+	// BuddyFit allocates like a buddy allocator, the free space is decomposed into
+	// aligned power-of-two blocks of the parent, the smallest block that fits is
+	// split until the requested size is reached.
+	BuddyFit Strategy = buddyFit
+)
+
+// fitIn returns the first aligned CIDR with prefix length bits in the range r
+func fitIn(r inet.Block, bits int) (inet.Block, bool) {
+	c, err := r.Base().Prefix(bits)
+	if err != nil {
+		return inet.Block{}, false
+	}
+
+	// not aligned, the next one
+	if c.Base().Less(r.Base()) {
+		var ok bool
+		if c, ok = c.Next(); !ok {
+			return inet.Block{}, false
+		}
+	}
+
+	if r.Last().Less(c.Last()) {
+		return inet.Block{}, false
+	}
+	return c, true
+}
+
+func firstFit(free []inet.Block, bits int) (inet.Block, bool) {
+	for _, r := range free {
+		if c, ok := fitIn(r, bits); ok {
+			return c, true
+		}
+	}
+	return inet.Block{}, false
+}
+
+func bestFit(free []inet.Block, bits int) (best inet.Block, ok bool) {
+	var bestRange inet.Block
+	for _, r := range free {
+		c, fits := fitIn(r, bits)
+		if !fits {
+			continue
+		}
+		if !ok || r.Size().Cmp(bestRange.Size()) < 0 {
+			best, bestRange, ok = c, r, true
+		}
+	}
+	return
+}
+
+func buddyFit(free []inet.Block, bits int) (inet.Block, bool) {
+	var buddy inet.Block
+	ok := false
+	for _, r := range free {
+		for _, c := range r.CIDRs() {
+			// too small or not smaller than the best so far
+			if c.Bits() > bits || ok && c.Bits() <= buddy.Bits() {
+				continue
+			}
+			buddy, ok = c, true
+		}
+	}
+	if !ok {
+		return inet.Block{}, false
+	}
+
+	// split the buddy down to the requested size, the first half every time
+	c, err := buddy.Base().Prefix(bits)
+	return c, err == nil
+}
@@ -0,0 +1,67 @@
+package plan_test
+
+import (
+	"fmt"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/plan"
+)
+
+func ExampleLayout() {
+	outer, _ := inet.ParseBlock("192.168.10.0/24")
+
+	subnets, err := plan.Layout(outer, []plan.Spec{
+		{Label: "office", Hosts: 50},
+		{Label: "servers", Hosts: 20},
+		{Label: "wlan", Hosts: 100},
+		{Label: "uplink", Bits: 30},
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, s := range subnets {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// 192.168.10.0/25 wlan
+	// 192.168.10.128/26 office
+	// 192.168.10.192/27 servers
+	// 192.168.10.224/30 uplink
+}
+
+func ExampleTemplate() {
+	site := plan.Template{
+		Name: "site",
+		Nodes: []plan.Node{
+			{Spec: plan.Spec{Label: "pop"}, Count: 2, Childs: []plan.Node{
+				{Spec: plan.Spec{Label: "rack", Hosts: 50}, Count: 2},
+				{Spec: plan.Spec{Label: "mgmt", Bits: 28}},
+			}},
+			{Spec: plan.Spec{Label: "uplink", Bits: 30}},
+		},
+	}
+
+	outer, _ := inet.ParseBlock("10.1.0.0/22")
+	t, err := site.Tree(outer)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(t)
+
+	// Output:
+	// ▼
+	// └─ site
+	//    ├─ pop-1
+	//    │  ├─ pop-1/rack-1
+	//    │  ├─ pop-1/rack-2
+	//    │  └─ pop-1/mgmt
+	//    ├─ pop-2
+	//    │  ├─ pop-2/rack-1
+	//    │  ├─ pop-2/rack-2
+	//    │  └─ pop-2/mgmt
+	//    └─ uplink
+}
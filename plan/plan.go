@@ -0,0 +1,134 @@
+// Package plan computes VLSM subnet layouts, the classic whiteboard exercise:
+// carve labeled subnets of required sizes out of an outer CIDR without overlaps.
+// Templates describe reusable hierarchies of such layouts, e.g. for standardized site addressing.
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+)
+
+// Spec describes a required subnet, either by prefix length or by number of usable hosts.
+// If Bits is > 0 it takes precedence over Hosts.
+type Spec struct {
+	Label string
+	Hosts uint64
+	Bits  int
+}
+
+// Subnet is a labeled block of the computed layout.
+type Subnet struct {
+	Label string
+	inet.Block
+}
+
+// String returns the block and label.
+func (s Subnet) String() string {
+	return fmt.Sprintf("%s %s", s.Block, s.Label)
+}
+
+// Layout computes a non-overlapping layout for specs inside outer.
+//
+// The subnets are allocated biggest first, from the base address of outer onwards,
+// this keeps all subnets aligned and the remaining free space in one piece at the end.
+// Specs with the same size keep their input order.
+//
+// The returned subnets are sorted by address.
+// Returns an error if outer isn't a CIDR, a spec is invalid or the specs don't fit into outer.
+func Layout(outer inet.Block, specs []Spec) ([]Subnet, error) {
+	if !outer.IsCIDR() {
+		return nil, fmt.Errorf("plan: outer block must be a CIDR: %v", outer)
+	}
+
+	maxBits := 128
+	if outer.Is4() {
+		maxBits = 32
+	}
+
+	// resolve host counts to prefix lengths
+	out := make([]Subnet, len(specs))
+	lens := make([]int, len(specs))
+	for i, s := range specs {
+		bits, err := prefixLen(s, maxBits)
+		if err != nil {
+			return nil, err
+		}
+		out[i].Label = s.Label
+		lens[i] = bits
+	}
+
+	// biggest first, stable for equal sizes
+	idx := make([]int, len(specs))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return lens[idx[i]] < lens[idx[j]] })
+
+	free := outer
+	for _, i := range idx {
+		if !free.IsValid() {
+			return nil, fmt.Errorf("plan: %q /%d does not fit, %v is exhausted", out[i].Label, lens[i], outer)
+		}
+
+		// the cursor is always aligned, all prior subnets are bigger or equal
+		b, err := free.Base().Prefix(lens[i])
+		if err != nil {
+			return nil, err
+		}
+		if !(outer == b || outer.Covers(b)) {
+			return nil, fmt.Errorf("plan: %q /%d does not fit into %v", out[i].Label, lens[i], outer)
+		}
+		out[i].Block = b
+
+		// cut b from the front of the free space
+		rest := free.Diff([]inet.Block{b})
+		free = inet.Block{}
+		if len(rest) > 0 {
+			free = rest[0]
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Less(out[j].Block) })
+	return out, nil
+}
+
+var errNoSize = errors.New("plan: spec without size")
+
+// prefixLen returns the prefix length needed for spec.
+func prefixLen(s Spec, maxBits int) (int, error) {
+	if s.Bits < 0 || s.Bits > maxBits {
+		return 0, fmt.Errorf("plan: %q, invalid prefix length /%d", s.Label, s.Bits)
+	}
+	if s.Bits > 0 {
+		return s.Bits, nil
+	}
+	if s.Hosts == 0 {
+		return 0, fmt.Errorf("%w: %q", errNoSize, s.Label)
+	}
+
+	for bits := maxBits; bits >= 0; bits-- {
+		if usableHosts(bits, maxBits) >= s.Hosts {
+			return bits, nil
+		}
+	}
+	return 0, fmt.Errorf("plan: %q, too many hosts: %d", s.Label, s.Hosts)
+}
+
+// usableHosts with the same semantics as inet.Block.UsableHosts, saturated at MaxUint64.
+func usableHosts(bits, maxBits int) uint64 {
+	hostBits := maxBits - bits
+	if hostBits >= 64 {
+		return math.MaxUint64
+	}
+	n := uint64(1) << hostBits
+
+	// IPv4 network and broadcast address, but not for /31 and /32
+	if maxBits == 32 && hostBits > 1 {
+		n -= 2
+	}
+	return n
+}
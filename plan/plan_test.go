@@ -0,0 +1,137 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+)
+
+func mustBlock(s string) inet.Block {
+	b, err := inet.ParseBlock(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestPrefixLen(t *testing.T) {
+	tests := []struct {
+		spec    Spec
+		maxBits int
+		want    int
+	}{
+		{Spec{Hosts: 1}, 32, 32},
+		{Spec{Hosts: 2}, 32, 31},
+		{Spec{Hosts: 3}, 32, 29},
+		{Spec{Hosts: 254}, 32, 24},
+		{Spec{Hosts: 255}, 32, 23},
+		{Spec{Hosts: 256}, 128, 120},
+		{Spec{Hosts: 257}, 128, 119},
+		{Spec{Bits: 64}, 128, 64},
+		{Spec{Bits: 27, Hosts: 1000}, 32, 27},
+	}
+
+	for _, tt := range tests {
+		got, err := prefixLen(tt.spec, tt.maxBits)
+		if err != nil {
+			t.Errorf("prefixLen(%+v), unexpected error: %v", tt.spec, err)
+		}
+		if got != tt.want {
+			t.Errorf("prefixLen(%+v), got /%d, want /%d", tt.spec, got, tt.want)
+		}
+	}
+
+	for _, spec := range []Spec{{}, {Bits: 33}, {Bits: -1}, {Hosts: 1 << 33}} {
+		if _, err := prefixLen(spec, 32); err == nil {
+			t.Errorf("prefixLen(%+v), expected error", spec)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	outer := mustBlock("2001:db8::/62")
+	got, err := Layout(outer, []Spec{
+		{Label: "a", Bits: 64},
+		{Label: "b", Bits: 63},
+		{Label: "c", Bits: 64},
+	})
+	if err != nil {
+		t.Fatalf("Layout(), unexpected error: %v", err)
+	}
+
+	want := []Subnet{
+		{"b", mustBlock("2001:db8::/63")},
+		{"a", mustBlock("2001:db8:0:2::/64")},
+		{"c", mustBlock("2001:db8:0:3::/64")},
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Layout(), got %v, want %v", got, want)
+			break
+		}
+	}
+
+	// doesn't fit, exhausted
+	if _, err := Layout(outer, []Spec{{Bits: 63}, {Bits: 63}, {Bits: 64}}); err == nil {
+		t.Errorf("Layout(), expected error for exhausted outer block")
+	}
+
+	// doesn't fit, too big
+	if _, err := Layout(outer, []Spec{{Bits: 60}}); err == nil {
+		t.Errorf("Layout(), expected error for too big spec")
+	}
+
+	// outer no CIDR
+	if _, err := Layout(mustBlock("10.0.0.1-10.0.0.7"), []Spec{{Bits: 31}}); err == nil {
+		t.Errorf("Layout(), expected error for non-CIDR outer block")
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	tmpl := Template{
+		Name: "site",
+		Nodes: []Node{
+			{Spec: Spec{Label: "pop"}, Count: 2, Childs: []Node{
+				{Spec: Spec{Label: "rack", Hosts: 50}, Count: 2},
+				{Spec: Spec{Label: "mgmt", Bits: 28}},
+			}},
+			{Spec: Spec{Label: "uplink", Bits: 30}},
+		},
+	}
+
+	subnets, err := tmpl.Instantiate(mustBlock("10.1.0.0/22"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"10.1.0.0/24 pop-1",
+		"10.1.0.0/26 pop-1/rack-1",
+		"10.1.0.64/26 pop-1/rack-2",
+		"10.1.0.128/28 pop-1/mgmt",
+		"10.1.1.0/24 pop-2",
+		"10.1.1.0/26 pop-2/rack-1",
+		"10.1.1.64/26 pop-2/rack-2",
+		"10.1.1.128/28 pop-2/mgmt",
+		"10.1.2.0/30 uplink",
+	}
+	if len(subnets) != len(want) {
+		t.Fatalf("Instantiate, got %v, want %v", subnets, want)
+	}
+	for i, s := range subnets {
+		if s.String() != want[i] {
+			t.Errorf("Instantiate, got %v, want %v", s, want[i])
+		}
+	}
+
+	// too small
+	if _, err := tmpl.Instantiate(mustBlock("10.1.0.0/24")); err == nil {
+		t.Errorf("Instantiate into /24, want error")
+	}
+
+	// duplicate labels
+	dup := Template{Nodes: []Node{{Spec: Spec{Label: "a", Bits: 26}}, {Spec: Spec{Label: "a", Bits: 26}}}}
+	if _, err := dup.Instantiate(mustBlock("10.1.0.0/24")); err == nil {
+		t.Errorf("Instantiate with duplicate labels, want error")
+	}
+}
@@ -0,0 +1,136 @@
+package plan
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/inettree"
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+// Node is a level of a Template, Count subnets of the size given by Spec, each holding the Childs.
+// Without size in Spec, the subnets are just big enough for the Childs.
+type Node struct {
+	Spec
+
+	// number of subnets, 0 is treated as 1.
+	// With Count > 1, the index is appended to the label, e.g. "rack-1", "rack-2", ...
+	Count int
+
+	// the nested levels, laid out inside every subnet of this node
+	Childs []Node
+}
+
+// Template describes a reusable hierarchy of labeled subnets, e.g. region → pop → rack,
+// instantiated onto any start CIDR for standardized site addressing.
+type Template struct {
+	Name  string
+	Nodes []Node
+}
+
+// Instantiate lays out the template inside outer, level by level, see Layout.
+// The labels of the subnets are the paths of the node labels, joined by '/', e.g. "pop-1/rack-2".
+//
+// The returned subnets are sorted by address, parents before their childs.
+// Returns an error if the template doesn't fit into outer.
+func (t Template) Instantiate(outer inet.Block) ([]Subnet, error) {
+	if !outer.IsCIDR() {
+		return nil, fmt.Errorf("plan: outer block must be a CIDR: %v", outer)
+	}
+
+	maxBits := 128
+	if outer.Is4() {
+		maxBits = 32
+	}
+	return instantiate(outer, t.Nodes, "", maxBits)
+}
+
+// Tree returns the instantiated template as tree of inettree.Items, the label as text.
+func (t Template) Tree(outer inet.Block) (*tree.Tree, error) {
+	subnets, err := t.Instantiate(outer)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]tree.Interface, 0, len(subnets)+1)
+	items = append(items, inettree.Item{Block: outer, Text: t.Name})
+	for _, s := range subnets {
+		items = append(items, inettree.Item{Block: s.Block, Text: s.Label})
+	}
+	return tree.New(items)
+}
+
+// instantiate the nodes inside outer, rec-descent
+func instantiate(outer inet.Block, nodes []Node, path string, maxBits int) ([]Subnet, error) {
+	var specs []Spec
+	byLabel := make(map[string]Node)
+
+	for _, n := range nodes {
+		bits, err := nodeLen(n, maxBits)
+		if err != nil {
+			return nil, err
+		}
+
+		count := n.Count
+		if count < 1 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			label := path + n.Label
+			if count > 1 {
+				label = fmt.Sprintf("%s-%d", label, i+1)
+			}
+			if _, ok := byLabel[label]; ok {
+				return nil, fmt.Errorf("plan: duplicate label %q", label)
+			}
+			byLabel[label] = n
+			specs = append(specs, Spec{Label: label, Bits: bits})
+		}
+	}
+
+	subnets, err := Layout(outer, specs)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Subnet
+	for _, s := range subnets {
+		out = append(out, s)
+
+		childs, err := instantiate(s.Block, byLabel[s.Label].Childs, s.Label+"/", maxBits)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, childs...)
+	}
+	return out, nil
+}
+
+// nodeLen returns the prefix length of the node, without size given, just big enough for the childs
+func nodeLen(n Node, maxBits int) (int, error) {
+	if n.Bits > 0 || n.Hosts > 0 || len(n.Childs) == 0 {
+		return prefixLen(n.Spec, maxBits)
+	}
+
+	// the childs are laid out aligned, biggest first, they fit exactly into the sum of their sizes
+	sum := new(big.Int)
+	for _, c := range n.Childs {
+		bits, err := nodeLen(c, maxBits)
+		if err != nil {
+			return 0, err
+		}
+		count := c.Count
+		if count < 1 {
+			count = 1
+		}
+		size := new(big.Int).Lsh(big.NewInt(int64(count)), uint(maxBits-bits))
+		sum.Add(sum, size)
+	}
+
+	bits := maxBits - sum.Sub(sum, big.NewInt(1)).BitLen()
+	if bits < 0 {
+		return 0, fmt.Errorf("plan: %q, childs too big", n.Label)
+	}
+	return bits, nil
+}
@@ -0,0 +1,58 @@
+package pool
+
+import "math/bits"
+
+// bitmap, one bit per address, indexed by the offset from the base of the pool
+type bitmap []uint64
+
+func newBitmap(n uint64) bitmap {
+	return make(bitmap, (n+63)/64)
+}
+
+func (bm bitmap) get(i uint64) bool {
+	return bm[i/64]&(1<<(i%64)) != 0
+}
+
+func (bm bitmap) set(i uint64) {
+	bm[i/64] |= 1 << (i % 64)
+}
+
+func (bm bitmap) clear(i uint64) {
+	bm[i/64] &^= 1 << (i % 64)
+}
+
+// nextSet returns the first set bit in [i, n), n if there is none
+func (bm bitmap) nextSet(i, n uint64) uint64 {
+	for w := i / 64; w < uint64(len(bm)); w++ {
+		word := bm[w]
+		if w == i/64 {
+			// mask the bits below i
+			word &^= 1<<(i%64) - 1
+		}
+		if word != 0 {
+			if j := w*64 + uint64(bits.TrailingZeros64(word)); j < n {
+				return j
+			}
+			break
+		}
+	}
+	return n
+}
+
+// nextClear returns the first bit in [i, n) clear in a and b, n if there is none
+func nextClear(a, b bitmap, i, n uint64) uint64 {
+	for w := i / 64; w < uint64(len(a)); w++ {
+		word := a[w] | b[w]
+		if w == i/64 {
+			// mask the bits below i
+			word |= 1<<(i%64) - 1
+		}
+		if word != ^uint64(0) {
+			if j := w*64 + uint64(bits.TrailingZeros64(^word)); j < n {
+				return j
+			}
+			break
+		}
+	}
+	return n
+}
@@ -0,0 +1,40 @@
+package pool_test
+
+import (
+	"fmt"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+	"github.com/gaissmai/go-inet/v2/pool"
+)
+
+func ExamplePool() {
+	b, _ := inet.ParseBlock("192.168.1.0/29")
+	p, _ := pool.New(b)
+
+	// network, gateway and broadcast address
+	gw, _ := inet.ParseBlock("192.168.1.0-192.168.1.1")
+	bc, _ := inet.ParseBlock("192.168.1.7")
+	_ = p.Exclude(gw)
+	_ = p.Exclude(bc)
+
+	for i := 0; i < 3; i++ {
+		ip, _ := p.Lease()
+		fmt.Println(ip)
+	}
+
+	ip, _ := inet.ParseIP("192.168.1.3")
+	_ = p.Release(ip)
+
+	p.FreeIPs()(func(ip inet.IP) bool {
+		fmt.Println("free:", ip)
+		return true
+	})
+
+	// Output:
+	// 192.168.1.2
+	// 192.168.1.3
+	// 192.168.1.4
+	// free: 192.168.1.3
+	// free: 192.168.1.5
+	// free: 192.168.1.6
+}
@@ -0,0 +1,249 @@
+// Package pool leases single addresses out of a Block, DHCP-style.
+//
+// For the allocation of subnets see package ipam, a pool hands out addresses,
+// tracked in bitmaps, two bits per address of the pool.
+package pool
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"sync"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+)
+
+// MaxSize is the maximum number of addresses of a pool, e.g. an IPv4 /8 or an IPv6 /104.
+const MaxSize = 1 << 24
+
+var (
+	// ErrExhausted is returned by Lease if the pool has no free address.
+	ErrExhausted = errors.New("pool: exhausted")
+
+	// ErrNotFree is returned by LeaseIP for leased or excluded addresses
+	// and by Exclude for ranges with leased addresses.
+	ErrNotFree = errors.New("pool: address not free")
+
+	// ErrNotLeased is returned by Release for addresses not leased.
+	ErrNotLeased = errors.New("pool: address not leased")
+)
+
+// Pool leases the addresses of a block, the lowest free address first.
+// Excluded addresses, e.g. the gateway or the static range, are never leased.
+//
+// The pool is safe for concurrent use.
+type Pool struct {
+	block inet.Block
+
+	// the base of the block, the offsets are relative to it
+	version uint8
+	hi, lo  uint64
+	size    uint64
+
+	mu sync.Mutex
+
+	leased, excluded   bitmap
+	nLeased, nExcluded uint64
+
+	// all addresses below hint are leased or excluded
+	hint uint64
+}
+
+// New returns the pool for the block b, any range, not only CIDRs.
+// Returns an error wrapping inet.ErrLimit if b has more than MaxSize addresses.
+func New(b inet.Block) (*Pool, error) {
+	if !b.IsValid() {
+		return nil, fmt.Errorf("pool: invalid block: %v", b)
+	}
+
+	version, hi, lo := b.Base().Raw()
+	_, lastHi, lastLo := b.Last().Raw()
+
+	// size-1 must fit in 24 bits
+	d, borrow := bits.Sub64(lastLo, lo, 0)
+	if lastHi-hi-borrow != 0 || d >= MaxSize {
+		return nil, fmt.Errorf("pool: %v exceeds %d addresses: %w", b, MaxSize, inet.ErrLimit)
+	}
+	size := d + 1
+
+	return &Pool{
+		block:    b,
+		version:  version,
+		hi:       hi,
+		lo:       lo,
+		size:     size,
+		leased:   newBitmap(size),
+		excluded: newBitmap(size),
+	}, nil
+}
+
+// Block returns the block of the pool.
+func (p *Pool) Block() inet.Block {
+	return p.block
+}
+
+// Size returns the number of addresses of the pool.
+func (p *Pool) Size() int {
+	return int(p.size)
+}
+
+// Leased returns the number of leased addresses.
+func (p *Pool) Leased() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return int(p.nLeased)
+}
+
+// Free returns the number of addresses neither leased nor excluded.
+func (p *Pool) Free() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return int(p.size - p.nLeased - p.nExcluded)
+}
+
+// Lease returns the lowest free address, leased until Release.
+// Returns ErrExhausted if there is no free address.
+func (p *Pool) Lease() (inet.IP, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	i := nextClear(p.leased, p.excluded, p.hint, p.size)
+	p.hint = i
+	if i == p.size {
+		return inet.IP{}, fmt.Errorf("%w: %v", ErrExhausted, p.block)
+	}
+
+	p.leased.set(i)
+	p.nLeased++
+	return p.ip(i), nil
+}
+
+// LeaseIP leases the address ip, e.g. the address requested by a client.
+// Returns ErrNotFree if ip is leased or excluded, or an error if ip isn't in the pool.
+func (p *Pool) LeaseIP(ip inet.IP) error {
+	i, ok := p.offset(ip)
+	if !ok {
+		return fmt.Errorf("pool: %v not in %v", ip, p.block)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.leased.get(i) || p.excluded.get(i) {
+		return fmt.Errorf("%w: %v", ErrNotFree, ip)
+	}
+	p.leased.set(i)
+	p.nLeased++
+	return nil
+}
+
+// Release returns the leased address ip to the pool.
+// Returns ErrNotLeased if ip isn't leased.
+func (p *Pool) Release(ip inet.IP) error {
+	i, ok := p.offset(ip)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !ok || !p.leased.get(i) {
+		return fmt.Errorf("%w: %v", ErrNotLeased, ip)
+	}
+	p.leased.clear(i)
+	p.nLeased--
+	if i < p.hint {
+		p.hint = i
+	}
+	return nil
+}
+
+// IsLeased reports whether ip is leased.
+func (p *Pool) IsLeased(ip inet.IP) bool {
+	i, ok := p.offset(ip)
+	if !ok {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.leased.get(i)
+}
+
+// Exclude excludes the addresses of r from leasing, r may be any range in the pool.
+// Already excluded addresses are no error.
+// Returns ErrNotFree if r contains leased addresses, or an error if r isn't in the pool.
+func (p *Pool) Exclude(r inet.Block) error {
+	first, ok1 := p.offset(r.Base())
+	last, ok2 := p.offset(r.Last())
+	if !ok1 || !ok2 {
+		return fmt.Errorf("pool: %v not in %v", r, p.block)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if i := p.leased.nextSet(first, last+1); i <= last {
+		return fmt.Errorf("%w: %v leased in %v", ErrNotFree, p.ip(i), r)
+	}
+
+	for i := first; i <= last; i++ {
+		if !p.excluded.get(i) {
+			p.excluded.set(i)
+			p.nExcluded++
+		}
+	}
+	return nil
+}
+
+// FreeIPs returns an iterator over the free addresses in ascending order.
+// The iteration stops when yield returns false.
+//
+// The pool isn't locked during yield, concurrent changes may or may not be seen.
+// The signature is compatible with iter.Seq[inet.IP], with go1.23 and later.
+func (p *Pool) FreeIPs() func(yield func(inet.IP) bool) {
+	return func(yield func(inet.IP) bool) {
+		for i := uint64(0); ; i++ {
+			p.mu.Lock()
+			i = nextClear(p.leased, p.excluded, i, p.size)
+			p.mu.Unlock()
+
+			if i == p.size || !yield(p.ip(i)) {
+				return
+			}
+		}
+	}
+}
+
+// LeasedIPs returns an iterator over the leased addresses in ascending order, see FreeIPs.
+func (p *Pool) LeasedIPs() func(yield func(inet.IP) bool) {
+	return func(yield func(inet.IP) bool) {
+		for i := uint64(0); ; i++ {
+			p.mu.Lock()
+			i = p.leased.nextSet(i, p.size)
+			p.mu.Unlock()
+
+			if i == p.size || !yield(p.ip(i)) {
+				return
+			}
+		}
+	}
+}
+
+// offset returns the offset of ip from the base, ok is false if ip isn't in the pool
+func (p *Pool) offset(ip inet.IP) (uint64, bool) {
+	version, hi, lo := ip.Raw()
+	if version != p.version {
+		return 0, false
+	}
+
+	d, borrow := bits.Sub64(lo, p.lo, 0)
+	if hi-p.hi-borrow != 0 || d >= p.size {
+		return 0, false
+	}
+	return d, true
+}
+
+// ip returns the address at offset i from the base
+func (p *Pool) ip(i uint64) inet.IP {
+	lo, carry := bits.Add64(p.lo, i, 0)
+	return inet.UnsafeIPFromParts(p.version, p.hi+carry, lo)
+}
@@ -0,0 +1,215 @@
+package pool
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+)
+
+func mustBlock(s string) inet.Block {
+	b, err := inet.ParseBlock(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func mustIP(s string) inet.IP {
+	ip, err := inet.ParseIP(s)
+	if err != nil {
+		panic(err)
+	}
+	return ip
+}
+
+// collect the addresses of the iterator as strings
+func collect(seq func(yield func(inet.IP) bool)) []string {
+	var out []string
+	seq(func(ip inet.IP) bool {
+		out = append(out, ip.String())
+		return true
+	})
+	return out
+}
+
+func TestNew(t *testing.T) {
+	for _, s := range []string{"10.0.0.0/8", "2001:db8::/104", "0.0.0.0", "0:0:0:1:ffff:ffff:ffff:ff00-0:0:0:2::ff"} {
+		if _, err := New(mustBlock(s)); err != nil {
+			t.Errorf("New(%s), unexpected error: %v", s, err)
+		}
+	}
+
+	for _, s := range []string{"10.0.0.0/7", "2001:db8::/64", "0:0:0:1:ffff:ffff:ff00:0-0:0:0:2::ffff"} {
+		if _, err := New(mustBlock(s)); !errors.Is(err, inet.ErrLimit) {
+			t.Errorf("New(%s), got %v, want ErrLimit", s, err)
+		}
+	}
+
+	if _, err := New(inet.Block{}); err == nil {
+		t.Errorf("New(Block{}), want error")
+	}
+}
+
+func TestLease(t *testing.T) {
+	p, _ := New(mustBlock("192.168.1.0/29"))
+	if err := p.Exclude(mustBlock("192.168.1.0-192.168.1.1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.LeaseIP(mustIP("192.168.1.3")); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for {
+		ip, err := p.Lease()
+		if errors.Is(err, ErrExhausted) {
+			break
+		}
+		got = append(got, ip.String())
+	}
+	want := []string{"192.168.1.2", "192.168.1.4", "192.168.1.5", "192.168.1.6", "192.168.1.7"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lease, got %v, want %v", got, want)
+	}
+	if p.Leased() != 6 || p.Free() != 0 || p.Size() != 8 {
+		t.Errorf("Leased, Free, Size, got %d, %d, %d, want 6, 0, 8", p.Leased(), p.Free(), p.Size())
+	}
+
+	// the lowest released address first
+	for _, s := range []string{"192.168.1.6", "192.168.1.4"} {
+		if err := p.Release(mustIP(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ip, _ := p.Lease(); ip != mustIP("192.168.1.4") {
+		t.Errorf("Lease after Release, got %v, want 192.168.1.4", ip)
+	}
+	if got := collect(p.FreeIPs()); !reflect.DeepEqual(got, []string{"192.168.1.6"}) {
+		t.Errorf("FreeIPs, got %v", got)
+	}
+	if got := collect(p.LeasedIPs()); len(got) != 5 || got[0] != "192.168.1.2" {
+		t.Errorf("LeasedIPs, got %v", got)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	p, _ := New(mustBlock("2001:db8::/120"))
+	_ = p.LeaseIP(mustIP("2001:db8::10"))
+
+	if err := p.LeaseIP(mustIP("2001:db8::10")); !errors.Is(err, ErrNotFree) {
+		t.Errorf("LeaseIP leased, got %v, want ErrNotFree", err)
+	}
+	if err := p.LeaseIP(mustIP("2001:db8::100")); err == nil {
+		t.Errorf("LeaseIP not in pool, want error")
+	}
+	if err := p.LeaseIP(mustIP("10.0.0.1")); err == nil {
+		t.Errorf("LeaseIP version mismatch, want error")
+	}
+	if err := p.Release(mustIP("2001:db8::11")); !errors.Is(err, ErrNotLeased) {
+		t.Errorf("Release free, got %v, want ErrNotLeased", err)
+	}
+	if err := p.Release(mustIP("2001:db7::10")); !errors.Is(err, ErrNotLeased) {
+		t.Errorf("Release not in pool, got %v, want ErrNotLeased", err)
+	}
+	if err := p.Exclude(mustBlock("2001:db8::/124")); err != nil {
+		t.Errorf("Exclude, unexpected error: %v", err)
+	}
+	if err := p.Exclude(mustBlock("2001:db8::8-2001:db8::1f")); !errors.Is(err, ErrNotFree) {
+		t.Errorf("Exclude leased, got %v, want ErrNotFree", err)
+	}
+	if err := p.Exclude(mustBlock("2001:db8::/119")); err == nil {
+		t.Errorf("Exclude not in pool, want error")
+	}
+	if err := p.LeaseIP(mustIP("2001:db8::1")); !errors.Is(err, ErrNotFree) {
+		t.Errorf("LeaseIP excluded, got %v, want ErrNotFree", err)
+	}
+	if p.Free() != 256-16-1 {
+		t.Errorf("Free, got %d, want %d", p.Free(), 256-16-1)
+	}
+	if !p.IsLeased(mustIP("2001:db8::10")) || p.IsLeased(mustIP("2001:db8::1")) {
+		t.Errorf("IsLeased, wrong")
+	}
+}
+
+func TestCarry(t *testing.T) {
+	// the pool crosses a 64 bit boundary
+	p, _ := New(mustBlock("0:0:0:1:ffff:ffff:ffff:fffe-0:0:0:2::1"))
+	if err := p.Exclude(mustBlock("0:0:0:1:ffff:ffff:ffff:fffe")); err != nil {
+		t.Fatal(err)
+	}
+
+	var want []string
+	for _, s := range []string{"0:0:0:1:ffff:ffff:ffff:ffff", "0:0:0:2::", "0:0:0:2::1"} {
+		want = append(want, mustIP(s).String())
+	}
+	if got := collect(p.FreeIPs()); !reflect.DeepEqual(got, want) {
+		t.Errorf("FreeIPs, got %v, want %v", got, want)
+	}
+	_ = p.LeaseIP(mustIP("0:0:0:2::"))
+	if got := collect(p.LeasedIPs()); !reflect.DeepEqual(got, want[1:2]) {
+		t.Errorf("LeasedIPs, got %v", got)
+	}
+}
+
+func TestBitmap(t *testing.T) {
+	const n = 200
+	a, b := newBitmap(n), newBitmap(n)
+	for i := uint64(0); i < 130; i++ {
+		a.set(i)
+	}
+	b.set(130)
+	b.set(199)
+
+	if got := nextClear(a, b, 0, n); got != 131 {
+		t.Errorf("nextClear, got %d, want 131", got)
+	}
+	if got := nextClear(a, b, 199, n); got != n {
+		t.Errorf("nextClear, got %d, want %d", got, n)
+	}
+	if got := b.nextSet(131, n); got != 199 {
+		t.Errorf("nextSet, got %d, want 199", got)
+	}
+	if got := b.nextSet(131, 199); got != 199 {
+		t.Errorf("nextSet, bounded, got %d, want 199", got)
+	}
+	a.clear(64)
+	if a.get(64) || !a.get(65) {
+		t.Errorf("clear, wrong bits")
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	p, _ := New(mustBlock("10.0.0.0/20"))
+
+	var mu sync.Mutex
+	seen := make(map[inet.IP]bool)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 512; i++ {
+				ip, err := p.Lease()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				if seen[ip] {
+					t.Errorf("%v leased twice", ip)
+				}
+				seen[ip] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if p.Free() != 0 || len(seen) != 4096 {
+		t.Errorf("Free, got %d, leased %d, want 0, 4096", p.Free(), len(seen))
+	}
+}
@@ -0,0 +1,97 @@
+// Package privacy pseudonymizes IP addresses, e.g. in pcap files and logs.
+//
+// The Anonymizer is prefix-preserving in the style of Crypto-PAn (Xu, Fan, Ammar, Moon):
+// two addresses sharing a k-bit prefix are mapped to addresses sharing a k-bit prefix,
+// the nesting of networks, as used by the tree, is retained.
+// The mapping is a permutation per IP version, deterministic for a given key.
+//
+// For simple truncation, see inet.IP.Truncate.
+package privacy
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"net"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+)
+
+// KeySize of the secret, 16 bytes for the AES-128 key and 16 bytes for the pad.
+const KeySize = 32
+
+// Anonymizer maps IP addresses prefix-preserving, safe for concurrent use.
+type Anonymizer struct {
+	block cipher.Block
+	pad   [16]byte
+}
+
+// New returns an Anonymizer for the secret key of KeySize bytes.
+func New(key []byte) (*Anonymizer, error) {
+	if len(key) != KeySize {
+		return nil, errors.New("privacy: key must be 32 bytes")
+	}
+
+	block, err := aes.NewCipher(key[:16])
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Anonymizer{block: block}
+	block.Encrypt(a.pad[:], key[16:])
+	return a, nil
+}
+
+// Anonymize returns the pseudonym of ip, the zero value for invalid input.
+//
+// IPv6 pseudonyms never land in ::ffff:0:0/96, they would turn into IPv4 addresses.
+// Such a pseudonym is encrypted again until it leaves the /96 (cycle-walking),
+// the prefix of these rare pseudonyms isn't preserved.
+func (a *Anonymizer) Anonymize(ip inet.IP) inet.IP {
+	orig := ip.ToStdIP()
+	if orig == nil {
+		return inet.IP{}
+	}
+	if v4 := orig.To4(); v4 != nil {
+		orig = v4
+	}
+
+	anon, _ := inet.FromStdIP(cycleWalk(orig, a.permute))
+	return anon
+}
+
+// cycleWalk applies the permutation to b until the result isn't IPv4-mapped,
+// a permutation of the IPv6 addresses outside of ::ffff:0:0/96.
+// Terminates, b itself isn't IPv4-mapped and on the cycle of the permutation.
+func cycleWalk(b net.IP, permute func(net.IP) net.IP) net.IP {
+	b = permute(b)
+	for len(b) == net.IPv6len && b.To4() != nil {
+		b = permute(b)
+	}
+	return b
+}
+
+// permute returns the prefix-preserving pseudonym of the 4 or 16 bytes of orig
+func (a *Anonymizer) permute(orig net.IP) net.IP {
+	// the otp, bit i is the msb of AES(orig[:i] || pad[i:])
+	n := len(orig) * 8
+	otp := make(net.IP, len(orig))
+
+	var in, out [16]byte
+	for i := 0; i < n; i++ {
+		in = a.pad
+		copy(in[:i/8], orig[:i/8])
+		if r := uint(i % 8); r > 0 {
+			mask := byte(0xff) << (8 - r)
+			in[i/8] = orig[i/8]&mask | a.pad[i/8]&^mask
+		}
+
+		a.block.Encrypt(out[:], in[:])
+		otp[i/8] |= (out[0] >> 7) << (7 - uint(i%8))
+	}
+
+	for i := range otp {
+		otp[i] ^= orig[i]
+	}
+	return otp
+}
@@ -0,0 +1,124 @@
+package privacy
+
+import (
+	"math/bits"
+	"math/rand"
+	"net"
+	"testing"
+
+	"github.com/gaissmai/go-inet/v2/inet"
+)
+
+// the key of the Crypto-PAn sample trace
+var key = []byte{
+	21, 34, 23, 141, 51, 164, 207, 128, 19, 10, 91, 22, 73, 144, 125, 16,
+	216, 152, 143, 131, 121, 121, 101, 39, 98, 87, 76, 45, 42, 132, 34, 2,
+}
+
+func mustIP(s string) inet.IP {
+	ip, err := inet.ParseIP(s)
+	if err != nil {
+		panic(err)
+	}
+	return ip
+}
+
+func TestAnonymizeVectors(t *testing.T) {
+	a, err := New(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct{ in, want string }{
+		{"128.11.68.132", "135.242.180.132"},
+		{"129.118.74.4", "134.136.186.123"},
+		{"130.132.252.244", "133.68.164.234"},
+	}
+	for _, tt := range tests {
+		if got := a.Anonymize(mustIP(tt.in)); got != mustIP(tt.want) {
+			t.Errorf("Anonymize(%s), got %v, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+// commonPrefixLen of two addresses of the same version
+func commonPrefixLen(a, b inet.IP) int {
+	x, y := a.ToStdIP(), b.ToStdIP()
+	if a.Is4() {
+		x, y = x.To4(), y.To4()
+	}
+	n := 0
+	for i := range x {
+		if d := x[i] ^ y[i]; d != 0 {
+			return n + bits.LeadingZeros8(d)
+		}
+		n += 8
+	}
+	return n
+}
+
+func TestAnonymizePrefixPreserving(t *testing.T) {
+	a, _ := New(key)
+	prng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 1000; i++ {
+		var x, y inet.IP
+		if i%2 == 0 {
+			bx, by := make(net.IP, 4), make(net.IP, 4)
+			prng.Read(bx)
+			prng.Read(by)
+			copy(by, bx[:prng.Intn(4)])
+			x, _ = inet.FromStdIP(bx)
+			y, _ = inet.FromStdIP(by)
+		} else {
+			bx, by := make(net.IP, 16), make(net.IP, 16)
+			prng.Read(bx)
+			prng.Read(by)
+			bx[0], by[0] = 0x20, 0x20
+			copy(by, bx[:prng.Intn(16)])
+			x, _ = inet.FromStdIP(bx)
+			y, _ = inet.FromStdIP(by)
+		}
+
+		ax, ay := a.Anonymize(x), a.Anonymize(y)
+		if ax.Is4() != x.Is4() {
+			t.Fatalf("Anonymize(%v) = %v, version changed", x, ax)
+		}
+		if got, want := commonPrefixLen(ax, ay), commonPrefixLen(x, y); got != want {
+			t.Fatalf("%v, %v: common prefix %d, anonymized %v, %v: %d", x, y, want, ax, ay, got)
+		}
+	}
+}
+
+func TestAnonymizeFault(t *testing.T) {
+	if _, err := New(key[:16]); err == nil {
+		t.Errorf("New(16 bytes), want error")
+	}
+	a, _ := New(key)
+	if got := a.Anonymize(inet.IP{}); got.IsValid() {
+		t.Errorf("Anonymize(zero value), got %v", got)
+	}
+}
+
+func TestCycleWalk(t *testing.T) {
+	// a permutation with a cycle through two IPv4-mapped addresses
+	cycle := []string{"2001:db8::1", "::ffff:1.2.3.4", "::ffff:5.6.7.8", "2001:db8::2", "2001:db8::1"}
+	permute := func(b net.IP) net.IP {
+		for i, s := range cycle[:len(cycle)-1] {
+			if b.Equal(net.ParseIP(s)) {
+				return net.ParseIP(cycle[i+1])
+			}
+		}
+		return b
+	}
+
+	if got := cycleWalk(net.ParseIP("2001:db8::1"), permute); !got.Equal(net.ParseIP("2001:db8::2")) {
+		t.Errorf("cycleWalk(2001:db8::1), got %v, want 2001:db8::2", got)
+	}
+
+	// IPv4 isn't walked
+	v4 := net.ParseIP("1.2.3.4").To4()
+	if got := cycleWalk(v4, func(b net.IP) net.IP { return b }); !got.Equal(v4) {
+		t.Errorf("cycleWalk(1.2.3.4), got %v", got)
+	}
+}
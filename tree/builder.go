@@ -0,0 +1,116 @@
+package tree
+
+// Builder builds trees with reusable storage, for huge trees rebuilt again and again,
+// e.g. from periodic imports of 10M+ items.
+//
+// A tree of n items is stored in three slices: the n sorted items (2 words each) and
+// the index tree in n+2 offsets and up to n child indexes (1 word each), there are no
+// per-item allocations. The build needs 2n+1 words of scratch, reused by every Build.
+//
+// After Reset the storage of the last built tree is reused by the next Build,
+// the GC pressure of a rebuild is reduced to the items themselves.
+// A Builder is not safe for concurrent use.
+type Builder struct {
+	opts  []Option
+	arena arena
+
+	// the storage is referenced by the last built tree
+	inUse bool
+}
+
+// NewBuilder returns a Builder, the options are applied to every Build, see New.
+func NewBuilder(opts ...Option) *Builder {
+	return &Builder{opts: opts}
+}
+
+// Build returns the tree for the items, same as New with the options of the builder.
+// Without Reset before, the tree of the last Build stays valid and new storage is allocated.
+func (b *Builder) Build(items []Interface) (*Tree, error) {
+	if b.inUse {
+		// still referenced, don't touch, the scratch is reused anyway
+		b.arena.items, b.arena.offs, b.arena.kids = nil, nil, nil
+	}
+	b.inUse = true
+
+	opts := append(b.opts[:len(b.opts):len(b.opts)], withArena(&b.arena))
+	return New(items, opts...)
+}
+
+// Reset releases the storage of the last built tree for reuse by the next Build.
+//
+// Beware, the tree returned by the last Build and all trees sharing its storage,
+// e.g. by Replace, must not be used anymore after Reset.
+func (b *Builder) Reset() {
+	b.inUse = false
+}
+
+// withArena, the storage of the build is taken from a
+func withArena(a *arena) Option {
+	return func(c *config) { c.arena = a }
+}
+
+// arena, the reusable storage of builds, see Builder. All methods allocate for a nil *arena.
+type arena struct {
+	// the scratch of build
+	parent, last []int
+
+	// the storage of the tree
+	items []Interface
+	offs  []int
+	kids  []int
+}
+
+// scratch returns the parent and last child slices of build, n items
+func (a *arena) scratch(n int) (parent, last []int) {
+	if a == nil {
+		return make([]int, n), make([]int, n+1)
+	}
+	a.parent, a.last = grow(a.parent, n), grow(a.last, n+1)
+	return a.parent, a.last
+}
+
+// offsets returns the offsets slice of the index tree, len n
+func (a *arena) offsets(n int) []int {
+	if a == nil {
+		return make([]int, n)
+	}
+	a.offs = grow(a.offs, n)
+	return a.offs
+}
+
+// childs returns the child index slice of the index tree, len n
+func (a *arena) childs(n int) []int {
+	if a == nil {
+		return make([]int, n)
+	}
+	a.kids = grow(a.kids, n)
+	return a.kids
+}
+
+// sortedCopy returns the items cloned and sorted, see sortedCopyWith.
+func (a *arena) sortedCopy(items []Interface, c config) ([]Interface, error) {
+	if a == nil {
+		return sortedCopyWith(items, c)
+	}
+
+	if cap(a.items) < len(items) {
+		a.items = make([]Interface, len(items))
+	}
+	sorted := a.items[:len(items)]
+	copy(sorted, items)
+
+	// the scratch of build is free until build
+	a.parent = grow(a.parent, len(items))
+	if err := sortItems(sorted, a.parent, c); err != nil {
+		return nil, err
+	}
+	return sorted, nil
+}
+
+// grow returns buf with length n, reallocated if too small
+func grow(buf []int, n int) []int {
+	if cap(buf) < n {
+		return make([]int, n)
+	}
+	return buf[:n]
+}
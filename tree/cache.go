@@ -0,0 +1,89 @@
+package tree
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LookupCache is a size-bounded LRU cache for Lookup results, safe for concurrent use.
+//
+// For workloads where the same few items are looked up over and over again.
+// The query items are used as map keys, they must be comparable.
+type LookupCache struct {
+	mu sync.Mutex
+	t  *Tree
+
+	size  int
+	lru   *list.List // of *entry, most recently used at front
+	cache map[Interface]*list.Element
+
+	hits   uint64
+	misses uint64
+}
+
+// entry in LRU list
+type entry struct {
+	query  Interface
+	result Interface
+}
+
+// NewLookupCache returns a cache for Lookup results of t, holding at most size entries.
+func NewLookupCache(t *Tree, size int) *LookupCache {
+	if size < 1 {
+		size = 1
+	}
+	return &LookupCache{
+		t:     t,
+		size:  size,
+		lru:   list.New(),
+		cache: make(map[Interface]*list.Element, size),
+	}
+}
+
+// Lookup returns the cached result of Tree.Lookup for item.
+func (c *LookupCache) Lookup(item Interface) Interface {
+	if item == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.cache[item]; ok {
+		c.hits++
+		c.lru.MoveToFront(e)
+		return e.Value.(*entry).result
+	}
+	c.misses++
+
+	result := c.t.Lookup(item)
+	c.cache[item] = c.lru.PushFront(&entry{item, result})
+
+	// evict least recently used
+	if c.lru.Len() > c.size {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.cache, e.Value.(*entry).query)
+	}
+
+	return result
+}
+
+// Reset invalidates the cache and sets the new tree, e.g. after a rebuild.
+// The hit and miss counters are not reset.
+func (c *LookupCache) Reset(t *Tree) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.t = t
+	c.lru.Init()
+	c.cache = make(map[Interface]*list.Element, c.size)
+}
+
+// Stats returns the hit and miss counters and the current number of cached entries.
+func (c *LookupCache) Stats() (hits, misses uint64, entries int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.hits, c.misses, c.lru.Len()
+}
@@ -4,6 +4,13 @@ Package tree is a minimal interval tree implementation.
 All interval types implementing the tree.Interface can use this library for fast lookups
 and a stringified tree representation.
 
+The intervals may be nested or disjunct, but must not overlap partially,
+see the Overlapper interface for the enforcement of this policy.
+Equal items are duplicates, NewMulti builds multi-value trees grouping them instead.
+
+Ready-made implementations of the tree.Interface: package inettree for inet.Block,
+package interval for int64 and time.Time intervals.
+
 Application example:
 The author uses it mainly for fast O(log n) lookups in IP ranges
 where patricia-tries with O(1) are not feasible.
@@ -0,0 +1,150 @@
+package tree
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Payloader is an optional interface for items with structured payload,
+// used by the exporters MarshalJSON, WriteDOT and WriteHTML.
+type Payloader interface {
+	// Payload returns the payload of the item, it must be marshalable by encoding/json.
+	Payload() interface{}
+}
+
+// payloadJSON returns the marshaled payload, empty for items without payload
+func payloadJSON(item Interface) (string, error) {
+	pl, ok := item.(Payloader)
+	if !ok || pl.Payload() == nil {
+		return "", nil
+	}
+	js, err := json.Marshal(pl.Payload())
+	return string(js), err
+}
+
+// jsonNode is the exported form of an item with its childs
+type jsonNode struct {
+	Item    string      `json:"item"`
+	Payload interface{} `json:"payload,omitempty"`
+	Childs  []jsonNode  `json:"childs,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// The tree is exported as nested array of nodes with the stringified item,
+// the payload if the item implements the Payloader interface and the childs.
+func (t *Tree) MarshalJSON() ([]byte, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t.jsonNodes(root))
+}
+
+// jsonNodes rec-descent
+func (t *Tree) jsonNodes(p int) []jsonNode {
+	cs := t.childs(p)
+	out := make([]jsonNode, 0, len(cs))
+	for _, c := range cs {
+		n := jsonNode{Item: t.items[c].String(), Childs: t.jsonNodes(c)}
+		if pl, ok := t.items[c].(Payloader); ok {
+			n.Payload = pl.Payload()
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+// WriteDOT writes the tree in the graphviz DOT language to w, an edge from every parent to its childs.
+// The payload of items implementing the Payloader interface is added as JSON tooltip.
+func (t *Tree) WriteDOT(w io.Writer) error {
+	buf := new(strings.Builder)
+	buf.WriteString("digraph tree {\n")
+	buf.WriteString("  node [shape=box];\n")
+
+	if t != nil {
+		if err := t.writeDOT(buf, root); err != nil {
+			return err
+		}
+	}
+
+	buf.WriteString("}\n")
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// writeDOT rec-descent, the childs of p with the edges from p
+func (t *Tree) writeDOT(buf *strings.Builder, p int) error {
+	for _, c := range t.childs(p) {
+		item := t.items[c]
+
+		attrs := "label=" + strconv.Quote(item.String())
+		js, err := payloadJSON(item)
+		if err != nil {
+			return err
+		}
+		if js != "" {
+			attrs += ", tooltip=" + strconv.Quote(js)
+		}
+		fmt.Fprintf(buf, "  n%d [%s];\n", c, attrs)
+
+		if p != root {
+			fmt.Fprintf(buf, "  n%d -> n%d;\n", p, c)
+		}
+
+		if err := t.writeDOT(buf, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteHTML writes the tree as nested HTML list to w.
+// The payload of items implementing the Payloader interface is added as JSON data-payload attribute.
+func (t *Tree) WriteHTML(w io.Writer) error {
+	buf := new(strings.Builder)
+	if t != nil {
+		if err := t.writeHTML(buf, root, ""); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// writeHTML rec-descent
+func (t *Tree) writeHTML(buf *strings.Builder, p int, pad string) error {
+	cs := t.childs(p)
+	if len(cs) == 0 {
+		return nil
+	}
+
+	buf.WriteString(pad + "<ul>\n")
+	for _, c := range cs {
+		item := t.items[c]
+
+		buf.WriteString(pad + "  <li")
+		js, err := payloadJSON(item)
+		if err != nil {
+			return err
+		}
+		if js != "" {
+			buf.WriteString(` data-payload="` + html.EscapeString(js) + `"`)
+		}
+		buf.WriteString(">" + html.EscapeString(item.String()))
+
+		if len(t.childs(c)) == 0 {
+			buf.WriteString("</li>\n")
+			continue
+		}
+		buf.WriteString("\n")
+		if err := t.writeHTML(buf, c, pad+"    "); err != nil {
+			return err
+		}
+		buf.WriteString(pad + "  </li>\n")
+	}
+	buf.WriteString(pad + "</ul>\n")
+	return nil
+}
@@ -0,0 +1,239 @@
+package tree
+
+import (
+	"bufio"
+	"container/heap"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrUnsorted is wrapped by the error of NewFromSorted for unsorted input.
+var ErrUnsorted = errors.New("tree: items not sorted")
+
+// Iterator returns the next item, io.EOF after the last one.
+type Iterator func() (Interface, error)
+
+// SliceIterator returns an Iterator over the items.
+func SliceIterator(items []Interface) Iterator {
+	i := 0
+	return func() (Interface, error) {
+		if i == len(items) {
+			return nil, io.EOF
+		}
+		i++
+		return items[i-1], nil
+	}
+}
+
+// NewFromSorted builds the tree from the items of next, already sorted by Less,
+// e.g. from a presorted file or from ExternalSort. The items are neither copied
+// nor sorted again, equal items keep the order of next, see New.
+//
+// Returns an error wrapping ErrUnsorted if the items aren't sorted and the error of next, if any.
+// The options are the same as for New.
+func NewFromSorted(next Iterator, opts ...Option) (*Tree, error) {
+	c := config{}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	var items []Interface
+	for {
+		item, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return &Tree{}, err
+		}
+		if n := len(items); n > 0 && item.Less(items[n-1]) {
+			return &Tree{}, fmt.Errorf("%w: item %d: %v before %v", ErrUnsorted, n, items[n-1], item)
+		}
+		if c.maxItems > 0 && len(items) == c.maxItems {
+			return &Tree{}, &LimitError{Limit: "max items", Max: c.maxItems}
+		}
+		items = append(items, item)
+	}
+
+	if items == nil {
+		return &Tree{observer: c.observer, metrics: c.metrics}, nil
+	}
+	if err := c.validateItems(items); err != nil {
+		return &Tree{}, err
+	}
+
+	t, err := measuredBuild(items, c)
+	t.observer = c.observer
+	return t, err
+}
+
+// Codec serializes the items for ExternalSort.
+type Codec interface {
+	// Encode writes the item to w.
+	Encode(w io.Writer, item Interface) error
+
+	// Decode reads the next item from r, io.EOF if there are no more items.
+	Decode(r io.Reader) (Interface, error)
+}
+
+// ExternalSort sorts the items of next like New does, for inputs larger than RAM:
+// runs of runSize items are sorted in memory and spilled to temporary files in dir,
+// os.TempDir if empty, and merged by the returned iterator. Equal items keep the input order.
+//
+// Build the tree with NewFromSorted. The close function removes the temporary files,
+// it must be called when the iterator isn't needed anymore.
+func ExternalSort(next Iterator, c Codec, runSize int, dir string) (sorted Iterator, close func() error, err error) {
+	if runSize < 1 {
+		return nil, nil, fmt.Errorf("tree: invalid run size %d", runSize)
+	}
+
+	var runs []*os.File
+	close = func() error {
+		var first error
+		for _, f := range runs {
+			if err := f.Close(); err != nil && first == nil {
+				first = err
+			}
+			if err := os.Remove(f.Name()); err != nil && first == nil {
+				first = err
+			}
+		}
+		runs = nil
+		return first
+	}
+
+	run := make([]Interface, 0, runSize)
+	pos := make([]int, 0, runSize)
+	for eof := false; !eof; {
+		run = run[:0]
+		for len(run) < runSize {
+			item, err := next()
+			if err == io.EOF {
+				eof = true
+				break
+			}
+			if err != nil {
+				_ = close()
+				return nil, nil, err
+			}
+			run = append(run, item)
+		}
+		_ = sortItems(run, pos[:len(run)], config{})
+
+		// all in memory, no spill
+		if eof && runs == nil {
+			return SliceIterator(run), close, nil
+		}
+		if len(run) == 0 {
+			break
+		}
+
+		f, err := spill(run, c, dir)
+		if f != nil {
+			runs = append(runs, f)
+		}
+		if err != nil {
+			_ = close()
+			return nil, nil, err
+		}
+	}
+
+	m, err := newMerger(runs, c)
+	if err != nil {
+		_ = close()
+		return nil, nil, err
+	}
+	return m.next, close, nil
+}
+
+// spill writes the sorted run to a temporary file, rewound for reading
+func spill(run []Interface, c Codec, dir string) (*os.File, error) {
+	f, err := os.CreateTemp(dir, "tree-sort-*")
+	if err != nil {
+		return nil, err
+	}
+
+	w := bufio.NewWriter(f)
+	for _, item := range run {
+		if err := c.Encode(w, item); err != nil {
+			return f, err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return f, err
+	}
+	_, err = f.Seek(0, io.SeekStart)
+	return f, err
+}
+
+// merger, k-way merge of the sorted runs
+type merger struct {
+	c     Codec
+	heads mergeHeap
+}
+
+// mergeHead, the current item of a run
+type mergeHead struct {
+	item Interface
+	run  int
+	r    *bufio.Reader
+}
+
+// mergeHeap, ties are broken by the run index, the runs are in input order
+type mergeHeap []mergeHead
+
+func (h mergeHeap) Len() int      { return len(h) }
+func (h mergeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h mergeHeap) Less(i, j int) bool {
+	a, b := h[i], h[j]
+	if a.item.Less(b.item) {
+		return true
+	}
+	return !b.item.Less(a.item) && a.run < b.run
+}
+func (h *mergeHeap) Push(x interface{}) { *h = append(*h, x.(mergeHead)) }
+func (h *mergeHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+func newMerger(runs []*os.File, c Codec) (*merger, error) {
+	m := &merger{c: c}
+	for i, f := range runs {
+		r := bufio.NewReader(f)
+		item, err := c.Decode(r)
+		if err != nil {
+			return nil, err
+		}
+		m.heads = append(m.heads, mergeHead{item, i, r})
+	}
+	heap.Init(&m.heads)
+	return m, nil
+}
+
+// next item of the merged runs
+func (m *merger) next() (Interface, error) {
+	if len(m.heads) == 0 {
+		return nil, io.EOF
+	}
+
+	head := &m.heads[0]
+	item := head.item
+
+	// refill from the same run
+	next, err := m.c.Decode(head.r)
+	switch {
+	case err == io.EOF:
+		heap.Pop(&m.heads)
+	case err != nil:
+		return nil, err
+	default:
+		head.item = next
+		heap.Fix(&m.heads, 0)
+	}
+	return item, nil
+}
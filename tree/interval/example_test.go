@@ -0,0 +1,26 @@
+package interval_test
+
+import (
+	"fmt"
+
+	"github.com/gaissmai/go-inet/v2/tree"
+	"github.com/gaissmai/go-inet/v2/tree/interval"
+)
+
+func ExampleInt64() {
+	t, _ := tree.New([]tree.Interface{
+		interval.Int64{Lo: 1000, Hi: 1999, Value: "ports internal"},
+		interval.Int64{Lo: 1024, Hi: 1099, Value: "ports lab"},
+		interval.Int64{Lo: 8000, Hi: 8999, Value: "ports public"},
+	})
+	fmt.Print(t)
+
+	fmt.Println(t.Lookup(interval.Int64{Lo: 1080, Hi: 1080}))
+
+	// Output:
+	// ▼
+	// ├─ 1000...1999 ports internal
+	// │  └─ 1024...1099 ports lab
+	// └─ 8000...8999 ports public
+	// 1024...1099 ports lab
+}
@@ -0,0 +1,139 @@
+// Package interval implements the tree.Interface for closed intervals [Lo, Hi]
+// over int64 and time.Time, the tree beyond IP blocks without boilerplate.
+//
+// The intervals implement the tree.Overlapper interface, partially overlapping
+// intervals are rejected by tree.New with a *tree.OverlapError, see tree.Tree.Overlaps.
+package interval
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+// compiler check, Int64 and Time implement tree.Interface, tree.Overlapper and tree.Payloader
+var _ tree.Interface = Int64{}
+var _ tree.Overlapper = Int64{}
+var _ tree.Payloader = Int64{}
+var _ tree.Interface = Time{}
+var _ tree.Overlapper = Time{}
+var _ tree.Payloader = Time{}
+
+// Int64 is the closed interval [Lo, Hi] over int64 with payload, Lo <= Hi.
+type Int64 struct {
+	Lo, Hi int64
+
+	// any payload, printed by String and exported by the tree exporters
+	Value interface{}
+}
+
+// Less implements the tree.Interface for Int64, covering intervals sort first.
+func (a Int64) Less(i tree.Interface) bool {
+	b := i.(Int64)
+	if a.Lo == b.Lo {
+		return a.Hi > b.Hi
+	}
+	return a.Lo < b.Lo
+}
+
+// Equals implements the tree.Interface for Int64, the payload is ignored.
+func (a Int64) Equals(i tree.Interface) bool {
+	b := i.(Int64)
+	return a.Lo == b.Lo && a.Hi == b.Hi
+}
+
+// Covers implements the tree.Interface for Int64
+func (a Int64) Covers(i tree.Interface) bool {
+	b := i.(Int64)
+	if a.Equals(b) {
+		return false
+	}
+	return a.Lo <= b.Lo && a.Hi >= b.Hi
+}
+
+// Overlaps implements the tree.Overlapper interface for Int64,
+// the intervals intersect but neither covers the other nor are they equal.
+func (a Int64) Overlaps(i tree.Interface) bool {
+	b := i.(Int64)
+	if a.Lo == b.Lo || a.Hi == b.Hi {
+		return false
+	}
+	if a.Lo > b.Lo {
+		a, b = b, a
+	}
+	return b.Lo <= a.Hi && a.Hi < b.Hi
+}
+
+// Payload implements the tree.Payloader interface for Int64
+func (a Int64) Payload() interface{} {
+	return a.Value
+}
+
+// String implements the tree.Interface for Int64
+func (a Int64) String() string {
+	if a.Value == nil {
+		return fmt.Sprintf("%d...%d", a.Lo, a.Hi)
+	}
+	return fmt.Sprintf("%d...%d %v", a.Lo, a.Hi, a.Value)
+}
+
+// Time is the closed interval [Lo, Hi] over time.Time with payload, !Hi.Before(Lo).
+// The times are compared as instants, the location and the monotonic clock are ignored.
+type Time struct {
+	Lo, Hi time.Time
+
+	// any payload, printed by String and exported by the tree exporters
+	Value interface{}
+}
+
+// Less implements the tree.Interface for Time, covering intervals sort first.
+func (a Time) Less(i tree.Interface) bool {
+	b := i.(Time)
+	if a.Lo.Equal(b.Lo) {
+		return a.Hi.After(b.Hi)
+	}
+	return a.Lo.Before(b.Lo)
+}
+
+// Equals implements the tree.Interface for Time, the payload is ignored.
+func (a Time) Equals(i tree.Interface) bool {
+	b := i.(Time)
+	return a.Lo.Equal(b.Lo) && a.Hi.Equal(b.Hi)
+}
+
+// Covers implements the tree.Interface for Time
+func (a Time) Covers(i tree.Interface) bool {
+	b := i.(Time)
+	if a.Equals(b) {
+		return false
+	}
+	return !a.Lo.After(b.Lo) && !a.Hi.Before(b.Hi)
+}
+
+// Overlaps implements the tree.Overlapper interface for Time,
+// the intervals intersect but neither covers the other nor are they equal.
+func (a Time) Overlaps(i tree.Interface) bool {
+	b := i.(Time)
+	if a.Lo.Equal(b.Lo) || a.Hi.Equal(b.Hi) {
+		return false
+	}
+	if a.Lo.After(b.Lo) {
+		a, b = b, a
+	}
+	return !b.Lo.After(a.Hi) && a.Hi.Before(b.Hi)
+}
+
+// Payload implements the tree.Payloader interface for Time
+func (a Time) Payload() interface{} {
+	return a.Value
+}
+
+// String implements the tree.Interface for Time, the times formatted as RFC 3339.
+func (a Time) String() string {
+	s := a.Lo.Format(time.RFC3339) + "..." + a.Hi.Format(time.RFC3339)
+	if a.Value == nil {
+		return s
+	}
+	return fmt.Sprintf("%s %v", s, a.Value)
+}
@@ -0,0 +1,116 @@
+package interval
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+func TestInt64(t *testing.T) {
+	items := []tree.Interface{
+		Int64{Lo: 200, Hi: 300},
+		Int64{Lo: 1, Hi: 100, Value: "outer"},
+		Int64{Lo: 50, Hi: 80},
+		Int64{Lo: 1, Hi: 10},
+		Int64{Lo: -5, Hi: 0},
+	}
+
+	tr, err := tree.New(items, tree.WithValidation(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `▼
+├─ -5...0
+├─ 1...100 outer
+│  ├─ 1...10
+│  └─ 50...80
+└─ 200...300
+`
+	if got := tr.String(); got != want {
+		t.Errorf("tree, got:\n%swant:\n%s", got, want)
+	}
+
+	if got := tr.Lookup(Int64{Lo: 60, Hi: 60}); !got.Equals(Int64{Lo: 50, Hi: 80}) {
+		t.Errorf("Lookup(60...60), got %v, want 50...80", got)
+	}
+	if got := tr.Lookup(Int64{Lo: 150, Hi: 150}); got != nil {
+		t.Errorf("Lookup(150...150), got %v, want nil", got)
+	}
+}
+
+func TestTime(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	// same instant, other location
+	cet := time.FixedZone("CET", 3600)
+
+	items := []tree.Interface{
+		Time{Lo: day(1), Hi: day(31), Value: "january"},
+		Time{Lo: day(8), Hi: day(14), Value: "week 2"},
+		Time{Lo: day(10).In(cet), Hi: day(10).In(cet)},
+	}
+
+	tr, err := tree.New(items, tree.WithValidation(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := Time{Lo: day(10), Hi: day(10)}
+	if got := tr.Lookup(q); !got.Equals(q) {
+		t.Errorf("Lookup(%v), got %v, want equal", q, got)
+	}
+
+	q = Time{Lo: day(20), Hi: day(21)}
+	if got := tr.Lookup(q); got.(Time).Value != "january" {
+		t.Errorf("Lookup(%v), got %v, want january", q, got)
+	}
+
+	if got, want := items[1].String(), "2024-01-08T00:00:00Z...2024-01-14T00:00:00Z week 2"; got != want {
+		t.Errorf("String, got %q, want %q", got, want)
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	tests := []struct {
+		a, b Int64
+		want bool
+	}{
+		{Int64{Lo: 1, Hi: 10}, Int64{Lo: 5, Hi: 20}, true},
+		{Int64{Lo: 5, Hi: 20}, Int64{Lo: 1, Hi: 10}, true},
+		{Int64{Lo: 1, Hi: 10}, Int64{Lo: 10, Hi: 20}, true},
+		{Int64{Lo: 1, Hi: 10}, Int64{Lo: 11, Hi: 20}, false},
+		{Int64{Lo: 1, Hi: 10}, Int64{Lo: 1, Hi: 20}, false},
+		{Int64{Lo: 1, Hi: 10}, Int64{Lo: 5, Hi: 10}, false},
+		{Int64{Lo: 1, Hi: 10}, Int64{Lo: 2, Hi: 9}, false},
+		{Int64{Lo: 1, Hi: 10}, Int64{Lo: 1, Hi: 10}, false},
+	}
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	for _, tt := range tests {
+		if got := tt.a.Overlaps(tt.b); got != tt.want {
+			t.Errorf("%v.Overlaps(%v), got %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+
+		// same for Time
+		a := Time{Lo: day(int(tt.a.Lo)), Hi: day(int(tt.a.Hi))}
+		b := Time{Lo: day(int(tt.b.Lo)), Hi: day(int(tt.b.Hi))}
+		if got := a.Overlaps(b); got != tt.want {
+			t.Errorf("%v.Overlaps(%v), got %v, want %v", a, b, got, tt.want)
+		}
+	}
+
+	// rejected by New
+	tr, err := tree.New([]tree.Interface{Int64{Lo: 1, Hi: 10}, Int64{Lo: 5, Hi: 20}, Int64{Lo: 30, Hi: 40}})
+	var oerr *tree.OverlapError
+	if !errors.As(err, &oerr) || len(tr.Overlaps()) != 1 {
+		t.Errorf("New, Int64 overlaps, got %v, %v, want *OverlapError", err, tr.Overlaps())
+	}
+
+	_, err = tree.New([]tree.Interface{Time{Lo: day(1), Hi: day(10)}, Time{Lo: day(5), Hi: day(20)}})
+	if !errors.As(err, &oerr) {
+		t.Errorf("New, Time overlaps, got %v, want *OverlapError", err)
+	}
+}
@@ -0,0 +1,51 @@
+package tree
+
+import "time"
+
+// Metrics receives the lookup and build observations of a tree, see WithMetrics.
+// Bridge it to Prometheus, expvar or whatever the service uses,
+// the methods are called synchronously and concurrently, they must be cheap and safe for concurrent use.
+type Metrics interface {
+	// Lookup is called for every lookup, hit reports a match,
+	// depth is the nesting level of the match, root items are level 1, 0 for misses.
+	Lookup(hit bool, depth int)
+
+	// Build is called for every build of a tree with the number of items,
+	// the nesting depth and the duration of the build.
+	Build(items, depth int, d time.Duration)
+}
+
+// WithMetrics sets the metrics, they are inherited by all trees derived from the tree returned by New.
+func WithMetrics(m Metrics) Option {
+	return func(c *config) { c.metrics = m }
+}
+
+// Metrics returns the metrics set by WithMetrics, or nil.
+func (t *Tree) Metrics() Metrics {
+	if t == nil {
+		return nil
+	}
+	return t.metrics
+}
+
+// Depth returns the nesting depth of the tree, root items are level 1, 0 for an empty tree.
+func (t *Tree) Depth() int {
+	if t == nil {
+		return 0
+	}
+	return t.depth
+}
+
+// measuredBuild calls build, the metrics set and notified, if any
+func measuredBuild(sorted []Interface, c config) (*Tree, error) {
+	if c.metrics == nil {
+		return build(sorted, c)
+	}
+
+	start := time.Now()
+	t, err := build(sorted, c)
+	c.metrics.Build(len(sorted), t.depth, time.Since(start))
+
+	t.metrics = c.metrics
+	return t, err
+}
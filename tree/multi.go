@@ -0,0 +1,157 @@
+package tree
+
+import "strings"
+
+// compiler check, *Multi implements the optional interfaces
+var _ Overlapper = (*Multi)(nil)
+var _ Payloader = (*Multi)(nil)
+
+// Multi holds all equal items of a multi-value tree under one key, see NewMulti.
+// Multi implements the Interface by its first item.
+type Multi struct {
+	items []Interface
+}
+
+// NewMulti builds a multi-value tree, equal items are no duplicates but grouped as *Multi,
+// e.g. several records (ticket, owner, VLAN) attached to the same block.
+// The groups keep the input order. Query the tree with plain items, LookupAll returns the
+// items of the matching group, the other queries return the *Multi groups.
+// Insert adds the items to their groups, Remove removes whole groups.
+func NewMulti(items []Interface, opts ...Option) (*Tree, error) {
+	sorted := sortedCopy(items)
+
+	groups := make([]Interface, 0, len(sorted))
+	for i := 0; i < len(sorted); {
+		j := i + 1
+		for j < len(sorted) && sorted[i].Equals(sorted[j]) {
+			j++
+		}
+		groups = append(groups, &Multi{sorted[i:j:j]})
+		i = j
+	}
+	return New(groups, opts...)
+}
+
+// Items returns the grouped items in input order.
+func (m *Multi) Items() []Interface {
+	return append([]Interface(nil), m.items...)
+}
+
+// unwrap the key of multi items
+func unwrap(i Interface) Interface {
+	if m, ok := i.(*Multi); ok {
+		return m.items[0]
+	}
+	return i
+}
+
+// Less implements the Interface by the first item.
+func (m *Multi) Less(i Interface) bool { return m.items[0].Less(unwrap(i)) }
+
+// Equals implements the Interface by the first item.
+func (m *Multi) Equals(i Interface) bool { return m.items[0].Equals(unwrap(i)) }
+
+// Covers implements the Interface by the first item.
+func (m *Multi) Covers(i Interface) bool { return m.items[0].Covers(unwrap(i)) }
+
+// Overlaps implements the Overlapper interface by the first item, false if it's no Overlapper.
+func (m *Multi) Overlaps(i Interface) bool {
+	o, ok := m.items[0].(Overlapper)
+	return ok && o.Overlaps(unwrap(i))
+}
+
+// String joins the strings of the items by " | ".
+func (m *Multi) String() string {
+	ss := make([]string, 0, len(m.items))
+	for _, item := range m.items {
+		ss = append(ss, item.String())
+	}
+	return strings.Join(ss, " | ")
+}
+
+// Payload implements the Payloader interface, the payloads of the items as slice,
+// nil if no item implements the Payloader interface.
+func (m *Multi) Payload() interface{} {
+	var pls []interface{}
+	for _, item := range m.items {
+		if pl, ok := item.(Payloader); ok {
+			pls = append(pls, pl.Payload())
+		}
+	}
+	if pls == nil {
+		return nil
+	}
+	return pls
+}
+
+// isMulti reports whether t is a multi-value tree, see NewMulti
+func (t *Tree) isMulti() bool {
+	if t == nil || len(t.items) == 0 {
+		return false
+	}
+	_, ok := t.items[0].(*Multi)
+	return ok
+}
+
+// wrap returns the query item as *Multi for multi-value trees, the stored
+// *Multi groups are compared with items of the same type, see Multi.Less
+func (t *Tree) wrap(item Interface) Interface {
+	if item == nil || !t.isMulti() {
+		return item
+	}
+	if _, ok := item.(*Multi); ok {
+		return item
+	}
+	return &Multi{[]Interface{item}}
+}
+
+// wrapAll returns the items wrapped, see wrap
+func (t *Tree) wrapAll(items []Interface) []Interface {
+	if !t.isMulti() {
+		return items
+	}
+	out := make([]Interface, len(items))
+	for i, item := range items {
+		out[i] = t.wrap(item)
+	}
+	return out
+}
+
+// insertMulti adds the items to the groups of the multi-value tree t, behind the items already in tree
+func (t *Tree) insertMulti(items []Interface) (*Tree, error) {
+	var all []Interface
+	for _, group := range t.items {
+		all = append(all, group.(*Multi).items...)
+	}
+	for _, item := range items {
+		if m, ok := item.(*Multi); ok {
+			all = append(all, m.items...)
+			continue
+		}
+		all = append(all, item)
+	}
+
+	nt, err := NewMulti(all, WithMetrics(t.metrics))
+	if err != nil {
+		return t.inherit(nt), err
+	}
+	return t.changed("Insert", items, nt), nil
+}
+
+// LookupAll returns all items equal to the match of Lookup, in input order.
+// For multi-value trees these are all items of the *Multi group, see NewMulti,
+// for other trees just the match. Returns nil if item is not covered at all by tree.
+func (t *Tree) LookupAll(item Interface) []Interface {
+	if t == nil || item == nil {
+		return nil
+	}
+
+	switch match := t.Lookup(item).(type) {
+	case nil:
+		return nil
+	case *Multi:
+		return match.Items()
+	default:
+		return []Interface{match}
+	}
+}
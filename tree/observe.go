@@ -0,0 +1,42 @@
+package tree
+
+// Event describes a change, the new tree After derived from the tree Before, see WithObserver.
+type Event struct {
+	// the operation, "Insert", "Remove", "Replace" or "Prune"
+	Op string
+
+	// the inserted, removed or pruned items, for Replace the old and the new item
+	Items []Interface
+
+	// the receiver and the returned tree
+	Before, After *Tree
+}
+
+// Observer is called for every change, e.g. for audit trails or metrics.
+type Observer func(Event)
+
+// WithObserver sets the observer, it's inherited by all trees derived from the tree returned by New.
+// The observer is called synchronously after every successful Insert, Remove, Replace and Prune.
+func WithObserver(fn Observer) Option {
+	return func(c *config) { c.observer = fn }
+}
+
+// inherit passes the observer of t on to the derived tree nt, also to the trees returned with an error
+func (t *Tree) inherit(nt *Tree) *Tree {
+	if t != nil && nt != nil {
+		nt.observer = t.observer
+	}
+	return nt
+}
+
+// changed passes the observer of t on to the derived tree nt and notifies it
+func (t *Tree) changed(op string, items []Interface, nt *Tree) *Tree {
+	if t == nil || nt == nil {
+		return nt
+	}
+	t.inherit(nt)
+	if t.observer != nil {
+		t.observer(Event{Op: op, Items: items, Before: t, After: nt})
+	}
+	return nt
+}
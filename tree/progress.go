@@ -0,0 +1,109 @@
+package tree
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// the phases of New, see WithPhaseProgress
+const (
+	PhaseValidate = "validate"
+	PhaseSort     = "sort"
+	PhaseBuild    = "build"
+)
+
+// WithPhaseProgress reports the progress of all phases of New, fn is called about every n steps
+// of a phase and once at its end with done == total, n <= 0 reports just the ends.
+//
+// The phases are PhaseValidate with the sampled items as steps, only with WithValidation,
+// PhaseSort with the comparisons as steps, total is the estimate n*log2(n), and PhaseBuild
+// with the items as steps, same as WithProgress.
+func WithPhaseProgress(n int, fn func(phase string, done, total int)) Option {
+	return func(c *config) {
+		if n <= 0 {
+			n = math.MaxInt32
+		}
+		c.every = n
+		c.progress = fn
+	}
+}
+
+// canceled returns the error of the canceled context, nil if not canceled or without context
+func (c config) canceled(phase string) error {
+	if c.ctx == nil {
+		return nil
+	}
+	if err := c.ctx.Err(); err != nil {
+		return fmt.Errorf("tree: %s canceled: %w", phase, err)
+	}
+	return nil
+}
+
+// report the progress of phase, if configured
+func (c config) report(phase string, done, total int) {
+	if c.progress != nil {
+		c.progress(phase, done, total)
+	}
+}
+
+// watchedSort sorts x, the comparisons are counted, every cancelCheck comparisons
+// c.ctx is checked and the progress reported, about every c.every comparisons.
+func (c config) watchedSort(x byLess) (err error) {
+	w := &watched{byLess: x, c: c}
+	if n := x.Len(); n > 1 {
+		w.total = n * bits.Len(uint(n))
+	}
+
+	// abort the sort on cancellation, sort.Sort can't be stopped otherwise
+	defer func() {
+		if r := recover(); r != nil {
+			stop, ok := r.(sortStop)
+			if !ok {
+				panic(r)
+			}
+			err = stop.err
+		}
+	}()
+
+	sort.Sort(w)
+	c.report(PhaseSort, w.total, w.total)
+	return nil
+}
+
+// sortStop, the panic value of canceled sorts
+type sortStop struct{ err error }
+
+// watched, byLess with counted comparisons
+type watched struct {
+	byLess
+	c          config
+	n, total   int
+	nextReport int
+}
+
+func (w *watched) Less(i, j int) bool {
+	w.n++
+	if w.n&(cancelCheck-1) == 0 {
+		w.check()
+	}
+	return w.items[i].Less(w.items[j])
+}
+
+// check for cancellation and report the progress
+func (w *watched) check() {
+	if err := w.c.canceled(PhaseSort); err != nil {
+		panic(sortStop{err})
+	}
+	if w.c.progress != nil && w.n >= w.nextReport+w.c.every {
+		w.nextReport = w.n
+
+		// the estimate may be exceeded, done == total only at the end
+		done := w.n
+		if done >= w.total {
+			done = w.total - 1
+		}
+		w.c.report(PhaseSort, done, w.total)
+	}
+}
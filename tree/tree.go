@@ -1,14 +1,26 @@
 package tree
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"runtime/pprof"
 	"sort"
 	"strings"
+	"unicode/utf8"
 )
 
 // parent index of all childs
 const root = -1
 
+// no parent or child index
+const none = -2
+
+// the build checks for cancellation every cancelCheck items, power of 2
+const cancelCheck = 1024
+
 // An Interface for various methods on intervals.
 type Interface interface {
 	// Covers returns true if and only if the receiver truly covers item.
@@ -27,23 +39,65 @@ type Interface interface {
 	String() string
 }
 
+// Overlapper is an optional interface for items that may partially overlap.
+//
+// Partially overlapping items, neither covering the other, are not supported by the tree,
+// the results of Lookup and Superset are undefined for them.
+// If the items implement the Overlapper interface, New reports them with an *OverlapError.
+type Overlapper interface {
+	// Overlaps reports whether the receiver and item overlap partially,
+	// neither covers the other nor are they equal.
+	Overlaps(Interface) bool
+}
+
+// OverlapError is returned by New for partially overlapping items.
+type OverlapError struct {
+	// Pairs of partially overlapping items, at least one pair for every conflicting region.
+	Pairs [][2]Interface
+}
+
+// Error implements the error interface.
+func (e *OverlapError) Error() string {
+	return fmt.Sprintf("tree: partially overlapping items: %v", e.Pairs)
+}
+
 // Tree partially implements an interval tree.
 type Tree struct {
 	// the sorted items, immutable, stored as slice, not as tree
 	items []Interface
 
-	// top-down parentIdx -> []childIdx tree
-	tree map[int][]int
+	// top-down parentIdx -> []childIdx tree in compressed form, two flat slices instead of a slice per item:
+	// the child indexes of parentIdx are kids[offs[parentIdx+1]:offs[parentIdx+2]], see childs()
+	offs []int
+	kids []int
 
 	// the duplicate items
 	dups []Interface
+
+	// the partially overlapping item pairs
+	overlaps [][2]Interface
+
+	// the max nesting level, root items are level 1
+	depth int
+
+	// notified on changes, see WithObserver
+	observer Observer
+
+	// notified on lookups and builds, see WithMetrics
+	metrics Metrics
 }
 
-// Duplicates returns the conflicting items. Returns nil if there was no error during New().
+// Duplicates returns the conflicting items, the later ones in input order, see New.
+// Returns nil if there was no error during New().
 func (t *Tree) Duplicates() []Interface {
 	return t.dups
 }
 
+// Overlaps returns the partially overlapping item pairs found during New, see Overlapper.
+func (t *Tree) Overlaps() [][2]Interface {
+	return t.overlaps
+}
+
 // Len returns the number of items in tree.
 func (t *Tree) Len() int {
 	return len(t.items)
@@ -51,101 +105,493 @@ func (t *Tree) Len() int {
 
 // New builds and returns an immutable tree.
 // Returns an error != nil on duplicate items.
-func New(items []Interface) (*Tree, error) {
-	t := &Tree{}
-	if items == nil {
-		return t, nil
+//
+// Equal items are resolved deterministically, regardless of the sort algorithm and of
+// further fields like payloads: the first item in input order wins and is found by
+// Lookup and friends, the later ones are returned by Duplicates.
+//
+// If the items implement the Overlapper interface, partially overlapping items
+// are rejected with an *OverlapError. As with duplicates, the tree is returned anyway.
+//
+// With the option WithValidation the items are checked for Interface contract violations,
+// returned as *ContractError.
+//
+// With the options WithMaxItems and WithMaxDepth untrusted input is limited, returned as *LimitError.
+// Long builds are reported with the options WithProgress and WithPhaseProgress, see also NewContext for cancellation.
+func New(items []Interface, opts ...Option) (*Tree, error) {
+	return newTree(nil, items, opts)
+}
+
+// NewContext is New, the build canceled with ctx, e.g. for huge imports with deadlines.
+// All phases are canceled, the validation, the sort and the build of the index.
+// On cancellation the empty tree and an error wrapping ctx.Err() are returned.
+//
+// The build runs with the pprof label "tree" = "build", long rebuilds show up in the profiles.
+func NewContext(ctx context.Context, items []Interface, opts ...Option) (t *Tree, err error) {
+	if err := ctx.Err(); err != nil {
+		return &Tree{}, fmt.Errorf("tree: build canceled: %w", err)
 	}
 
-	t.items = make([]Interface, len(items))
-	t.tree = make(map[int][]int)
+	pprof.Do(ctx, pprof.Labels("tree", "build"), func(ctx context.Context) {
+		t, err = newTree(ctx, items, opts)
+	})
+	return
+}
+
+// newTree, see New and NewContext, ctx may be nil
+func newTree(ctx context.Context, items []Interface, opts []Option) (*Tree, error) {
+	c := config{ctx: ctx}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	if items == nil {
+		return &Tree{observer: c.observer, metrics: c.metrics}, nil
+	}
+	if c.maxItems > 0 && len(items) > c.maxItems {
+		return &Tree{}, &LimitError{Limit: "max items", Max: c.maxItems}
+	}
+	if err := c.validateItems(items); err != nil {
+		return &Tree{}, err
+	}
 
 	// copy/clone and sort input, decouple from caller
-	copy(t.items, items)
-	sort.Slice(t.items, func(i, j int) bool { return t.items[i].Less(t.items[j]) })
+	sorted, err := c.arena.sortedCopy(items, c)
+	if err != nil {
+		return &Tree{}, err
+	}
+	t, err := measuredBuild(sorted, c)
+	t.observer = c.observer
+	return t, err
+}
+
+// sortedCopy returns the items cloned and sorted, equal items keep the input order, see sortItems.
+func sortedCopy(items []Interface) []Interface {
+	sorted, _ := sortedCopyWith(items, config{})
+	return sorted
+}
+
+// sortedCopyWith is sortedCopy, canceled with c.ctx, the progress reported, see sortItems.
+func sortedCopyWith(items []Interface, c config) ([]Interface, error) {
+	sorted := make([]Interface, len(items))
+	copy(sorted, items)
+	if err := sortItems(sorted, make([]int, len(items)), c); err != nil {
+		return nil, err
+	}
+	return sorted, nil
+}
+
+// sortItems sorts the items, equal items keep the input order, the first one wins, see New.
+// pos is the scratch for the input positions, len(items).
+// With c.ctx the sort stops on cancellation, progress is reported by c.progress.
+//
+// Cheaper than a stable sort, the runs of equal items are rare and short,
+// only they are sorted again by input position.
+func sortItems(items []Interface, pos []int, c config) error {
+	for i := range pos {
+		pos[i] = i
+	}
+	if c.ctx == nil && c.progress == nil {
+		sort.Sort(byLess{items, pos})
+	} else if err := c.watchedSort(byLess{items, pos}); err != nil {
+		return err
+	}
+
+	for i := 1; i < len(items); {
+		if !items[i-1].Equals(items[i]) {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(items) && items[i-1].Equals(items[j]) {
+			j++
+		}
+		sort.Sort(byPos{byLess{items[i-1 : j], pos[i-1 : j]}})
+		i = j
+	}
+	return nil
+}
+
+// byLess sorts the items with their input positions
+type byLess struct {
+	items []Interface
+	pos   []int
+}
+
+func (x byLess) Len() int           { return len(x.items) }
+func (x byLess) Less(i, j int) bool { return x.items[i].Less(x.items[j]) }
+func (x byLess) Swap(i, j int) {
+	x.items[i], x.items[j] = x.items[j], x.items[i]
+	x.pos[i], x.pos[j] = x.pos[j], x.pos[i]
+}
+
+// byPos sorts the items by input position
+type byPos struct{ byLess }
+
+func (x byPos) Less(i, j int) bool { return x.pos[i] < x.pos[j] }
+
+// build the tree from sorted items, the items are not copied.
+// With c.maxDepth > 0 the build stops at the first item nested deeper,
+// with c.ctx the build stops on cancellation, progress is reported by c.progress.
+// The index storage is taken from c.arena, if any.
+func build(sorted []Interface, c config) (*Tree, error) {
+	t := &Tree{}
+	t.items = sorted
+
+	// the scratch of the build
+	x := &indexer{items: sorted}
+	x.parent, x.last = c.arena.scratch(len(sorted))
+	for i := range x.last {
+		x.last[i] = none
+	}
+
+	maxDepth := c.maxDepth
+
+	// nil for non cancelable contexts
+	var done <-chan struct{}
+	if c.ctx != nil {
+		done = c.ctx.Done()
+	}
 
 	// items are sorted, build the index tree, O(n), collect but skip duplicates
 	for i := range t.items {
+		if done != nil && i&(cancelCheck-1) == 0 {
+			select {
+			case <-done:
+				return &Tree{}, fmt.Errorf("tree: build canceled after %d of %d items: %w", i, len(sorted), c.ctx.Err())
+			default:
+			}
+		}
+		if c.progress != nil && i > 0 && i%c.every == 0 {
+			c.progress(PhaseBuild, i, len(sorted))
+		}
 
 		// collect the dups
 		if i > 0 && t.items[i-1].Equals(t.items[i]) {
 			t.dups = append(t.dups, t.items[i])
+			x.parent[i] = none
 			continue
 		}
-		t.buildIndexTree(root, i)
+		d := x.buildIndexTree(root, i, 1)
+		if maxDepth > 0 && d > maxDepth {
+			return &Tree{}, &LimitError{Limit: "max depth", Max: maxDepth, Item: t.items[i]}
+		}
+		if d > t.depth {
+			t.depth = d
+		}
 	}
 
+	c.report(PhaseBuild, len(sorted), len(sorted))
+
+	t.overlaps = x.overlaps
+	t.offs, t.kids = x.compress(c.arena)
+
 	if t.dups != nil {
 		return t, errors.New("some items are duplicate")
 	}
 
+	if t.overlaps != nil {
+		return t, &OverlapError{t.overlaps}
+	}
+
 	return t, nil
 }
 
-// buildIndexTree, parent->child map, rec-descent algo.
-// Just building the tree with the slice indices, the items itself are not moved.
-func (t *Tree) buildIndexTree(p, c int) {
-	// if child index slice is empty, just append the childs index
-	if t.tree[p] == nil {
-		t.tree[p] = append(t.tree[p], c)
-		return
+// Insert returns a new tree with the items added, the receiver is not modified.
+// Returns an error != nil on duplicate items, items already in tree win against inserted items.
+//
+// The items are merged into the already sorted items of the tree, O(n + k log k).
+func (t *Tree) Insert(items ...Interface) (*Tree, error) {
+	if t == nil || t.items == nil {
+		nt, err := New(items, WithMetrics(t.Metrics()))
+		if err != nil {
+			return t.inherit(nt), err
+		}
+		return t.changed("Insert", items, nt), nil
+	}
+	if t.isMulti() {
+		return t.insertMulti(items)
+	}
+	add := sortedCopy(items)
+
+	// merge sorted slices
+	merged := make([]Interface, 0, len(t.items)+len(add))
+	i, j := 0, 0
+	for i < len(t.items) && j < len(add) {
+		if add[j].Less(t.items[i]) {
+			merged = append(merged, add[j])
+			j++
+			continue
+		}
+		merged = append(merged, t.items[i])
+		i++
+	}
+	merged = append(merged, t.items[i:]...)
+	merged = append(merged, add[j:]...)
+
+	nt, err := measuredBuild(merged, config{metrics: t.metrics})
+	if err != nil {
+		return t.inherit(nt), err
+	}
+	return t.changed("Insert", items, nt), nil
+}
+
+// Remove returns a new tree without the items equal to any of the given items,
+// the receiver is not modified. The childs of removed items move up to the next ancestor.
+// Items not in tree are ignored.
+func (t *Tree) Remove(items ...Interface) *Tree {
+	if t == nil || t.items == nil {
+		return &Tree{}
+	}
+	del := sortedCopy(t.wrapAll(items))
+
+	// merge-like filter of sorted slices
+	kept := make([]Interface, 0, len(t.items))
+	var removed []Interface
+	j := 0
+	for _, item := range t.items {
+		// skip del items sorted before item
+		for j < len(del) && del[j].Less(item) {
+			j++
+		}
+		if j < len(del) && del[j].Equals(item) {
+			removed = append(removed, item)
+			continue
+		}
+		kept = append(kept, item)
+	}
+
+	if len(kept) == 0 {
+		return t.changed("Remove", removed, &Tree{metrics: t.metrics})
+	}
+
+	// build can't fail here, dups are only collected
+	nt, _ := measuredBuild(kept, config{metrics: t.metrics})
+	return t.changed("Remove", removed, nt)
+}
+
+// Replace returns a new tree with the item old replaced by new, the receiver is not modified.
+// The items must be equal, e.g. same interval but different payload.
+// The index tree is shared, just the item slice is copied, O(n) without a rebuild.
+// Returns an error if old isn't in tree or new doesn't equal old.
+func (t *Tree) Replace(old, new Interface) (*Tree, error) {
+	old, new = t.wrap(old), t.wrap(new)
+	i, _, ok := t.find(old)
+	if !ok {
+		return t, fmt.Errorf("tree: replace, item not in tree: %v", old)
+	}
+	if new == nil || !new.Equals(old) {
+		return t, fmt.Errorf("tree: replace, items not equal: %v, %v", old, new)
+	}
+
+	items := make([]Interface, len(t.items))
+	copy(items, t.items)
+	items[i] = new
+
+	nt := &Tree{items: items, offs: t.offs, kids: t.kids, dups: t.dups, overlaps: t.overlaps, depth: t.depth, metrics: t.metrics}
+	return t.changed("Replace", []Interface{old, new}, nt), nil
+}
+
+// Subtree returns a new tree with item as the single root item and all its descendants,
+// the receiver is not modified. ok is false if item isn't in tree.
+func (t *Tree) Subtree(item Interface) (sub *Tree, ok bool) {
+	i, _, ok := t.find(item)
+	if !ok {
+		return &Tree{}, false
+	}
+
+	// pre-order of the index tree is the sort order, dups skipped
+	nt, _ := measuredBuild(t.collect(i, nil), config{metrics: t.metrics})
+	nt.observer = t.observer
+	return nt, true
+}
+
+// collect the item at index i and all its descendants in pre-order
+func (t *Tree) collect(i int, out []Interface) []Interface {
+	out = append(out, t.items[i])
+	for _, c := range t.childs(i) {
+		out = t.collect(c, out)
+	}
+	return out
+}
+
+// Prune returns a new tree without the items matching the predicate, the receiver is not modified.
+// The childs of pruned items move up to the next ancestor.
+func (t *Tree) Prune(predicate func(Interface) bool) *Tree {
+	if t == nil || t.items == nil {
+		return &Tree{}
+	}
+
+	kept := make([]Interface, 0, len(t.items))
+	var pruned []Interface
+	for _, item := range t.items {
+		if predicate(item) {
+			pruned = append(pruned, item)
+			continue
+		}
+		kept = append(kept, item)
+	}
+
+	if len(kept) == 0 {
+		return t.changed("Prune", pruned, &Tree{metrics: t.metrics})
+	}
+
+	// build can't fail here, dups are only collected
+	nt, _ := measuredBuild(kept, config{metrics: t.metrics})
+	return t.changed("Prune", pruned, nt)
+}
+
+// childs returns the child indexes of parent index p, root included.
+func (t *Tree) childs(p int) []int {
+	if p+2 >= len(t.offs) {
+		return nil
 	}
+	lo, hi := t.offs[p+1], t.offs[p+2]
+	return t.kids[lo:hi:hi]
+}
+
+// search returns the position of the first child in cs sorted after item.
+// Hand-rolled sort.Search, the closure costs too much in the hot path.
+func (t *Tree) search(cs []int, item Interface) int {
+	l, r := 0, len(cs)
+	for l < r {
+		m := int(uint(l+r) >> 1)
+		if item.Less(t.items[cs[m]]) {
+			r = m
+		} else {
+			l = m + 1
+		}
+	}
+	return l
+}
+
+// indexer, the scratch of build, parents and last childs in flat slices
+type indexer struct {
+	items []Interface
+
+	// the parent index of every item, none for dups
+	parent []int
+
+	// the last child index of parent index p, indexed by p+1, none if childless
+	last []int
 
+	// the partially overlapping item pairs
+	overlaps [][2]Interface
+}
+
+// buildIndexTree, child->parent map, rec-descent algo.
+// Just building the tree with the slice indices, the items itself are not moved.
+// Returns the nesting level of the child, d is the level below p.
+func (x *indexer) buildIndexTree(p, c, d int) int {
 	// everything is sorted, just compare with last child index
-	cs := t.tree[p] // dereference
+	cLast := x.last[p+1]
 
-	// get last child index of this parent
-	cLast := cs[len(cs)-1]
+	// no child yet, c is the first one
+	if cLast == none {
+		x.parent[c], x.last[p+1] = p, c
+		return d
+	}
 
 	// item is covered by last child, rec-descent down in tree
 	// last child is new parent
-	if t.items[cLast].Covers(t.items[c]) {
-		t.buildIndexTree(cLast, c)
-		return
+	if x.items[cLast].Covers(x.items[c]) {
+		return x.buildIndexTree(cLast, c, d+1)
+	}
+
+	// not covered by any child, c is the new last child at this level
+	x.parent[c], x.last[p+1] = p, c
+
+	// items are sorted, a partial overlap with any prior item shows up with the prior sibling
+	if o, ok := x.items[cLast].(Overlapper); ok && o.Overlaps(x.items[c]) {
+		x.overlaps = append(x.overlaps, [2]Interface{x.items[cLast], x.items[c]})
+	}
+	return d
+}
+
+// compress the child->parent map into the parent->childs index tree, a counting sort by parent,
+// the childs of a parent stay in ascending order. The storage is taken from a, if any.
+func (x *indexer) compress(a *arena) (offs, kids []int) {
+	n := len(x.items)
+
+	// count the childs of parent p in offs[p+2]
+	offs = a.offsets(n + 2)
+	for i := range offs {
+		offs[i] = 0
+	}
+	for _, p := range x.parent {
+		if p != none {
+			offs[p+2]++
+		}
 	}
 
-	// not covered by any child, just append at this level the child index
-	t.tree[p] = append(t.tree[p], c)
+	// prefix sums, the childs of p start at offs[p+1]
+	for i := 1; i < len(offs); i++ {
+		offs[i] += offs[i-1]
+	}
+
+	// fill, x.last is reused as write cursor
+	kids = a.childs(offs[n+1])
+	pos := x.last
+	copy(pos, offs[:n+1])
+	for c, p := range x.parent {
+		if p != none {
+			kids[pos[p+1]] = c
+			pos[p+1]++
+		}
+	}
+	return offs, kids
 }
 
-// Lookup returns the item itself or the *smallest* superset (bottom-up).
+// Lookup returns the item of the tree equal to item or the *smallest* superset (bottom-up).
 // If item is not covered at all by tree, then the returned item is nil.
+// The equal item is returned as stored in the tree, with its payload, not the query item.
 //
 // Example: Can be used in IP-ranges or IP-CIDRs to find the so called longest-prefix-match.
+// See also LookupLPM, LookupShortest and LookupK, named in routing terms.
 func (t *Tree) Lookup(item Interface) Interface {
-	if t.items == nil || item == nil {
-		return nil
+	match, depth := t.lookup(item)
+	if t.metrics != nil {
+		t.metrics.Lookup(match != nil, depth)
 	}
-	// rec-descent
-	return t.lookup(root, item)
+	return match
 }
 
-func (t *Tree) lookup(p int, item Interface) Interface {
-	// dereference
-	cs := t.tree[p]
+// lookup returns the match and its nesting level
+func (t *Tree) lookup(item Interface) (Interface, int) {
+	if t.items == nil || item == nil {
+		return nil, 0
+	}
+	item = t.wrap(item)
+
+	// descent, iterative
+	p, d := root, 0
+	for {
+		cs := t.childs(p)
 
-	// find pos in slice on this level
-	idx := sort.Search(len(cs), func(i int) bool { return item.Less(t.items[cs[i]]) })
+		// find pos in slice on this level
+		idx := t.search(cs, item)
 
-	// child before idx may be equal or covers item
-	if idx > 0 {
-		idx--
-		if t.items[cs[idx]].Equals(item) {
-			return item
-		}
-		if t.items[cs[idx]].Covers(item) {
-			return t.lookup(cs[idx], item)
+		// child before idx may be equal or covers item
+		if idx > 0 {
+			c := t.items[cs[idx-1]]
+			if c.Equals(item) {
+				return c, d + 1
+			}
+			if c.Covers(item) {
+				p, d = cs[idx-1], d+1
+				continue
+			}
 		}
-	}
 
-	// return parent at this level
-	if p != root {
-		return t.items[p]
+		// return parent at this level
+		if p != root {
+			return t.items[p], d
+		}
+		return nil, 0
 	}
-	return nil
 }
 
-// Superset returns the *biggest* superset (top-down) or the item itself.
+// Superset returns the *biggest* superset (top-down) or the item of the tree equal to item.
 // Find first interval in sort order covering item in root level.
 // If item is not contained at all in tree, then the returned item is nil.
 // Extremely degraded trees with heavy interval overlaps may result in O(n).
@@ -153,12 +599,13 @@ func (t *Tree) Superset(item Interface) Interface {
 	if item == nil {
 		return nil
 	}
+	item = t.wrap(item)
 
 	// dereference root level slice
-	rs := t.tree[root]
+	rs := t.childs(root)
 
 	// find pos in slice on root level
-	idx := sort.Search(len(rs), func(i int) bool { return item.Less(t.items[rs[i]]) })
+	idx := t.search(rs, item)
 
 	if idx == 0 {
 		return nil
@@ -168,7 +615,7 @@ func (t *Tree) Superset(item Interface) Interface {
 	if t.items[rs[idx-1]].Equals(item) {
 		// the items on root level are disjunct, maybe overlapping, BUT NOT covering each other
 		// therefore we can return here, no element before can overlap this item
-		return item
+		return t.items[rs[idx-1]]
 	}
 
 	// item isn't equal to any root level interval, find and return leftmost superset
@@ -193,25 +640,404 @@ func (t *Tree) Superset(item Interface) Interface {
 	return match
 }
 
+// LookupLPM returns the longest-prefix-match, the most specific item equal to or covering item, same as Lookup.
+// If item is not covered at all by tree, then the returned item is nil.
+func (t *Tree) LookupLPM(item Interface) Interface {
+	return t.Lookup(item)
+}
+
+// LookupShortest returns the shortest-prefix-match, the least specific item equal to or covering item, same as Superset.
+// If item is not covered at all by tree, then the returned item is nil.
+func (t *Tree) LookupShortest(item Interface) Interface {
+	return t.Superset(item)
+}
+
+// LookupK returns up to k items of the tree equal to or covering item, the most specific first.
+// The first item is the longest-prefix-match, followed by its ancestors, e.g. for fallback routes.
+// Returns nil if item is not covered at all by tree or k < 1.
+func (t *Tree) LookupK(item Interface, k int) []Interface {
+	if t == nil || t.items == nil || item == nil || k < 1 {
+		return nil
+	}
+	item = t.wrap(item)
+
+	// the path of the descent, top-down
+	var path []Interface
+
+	p := root
+	for {
+		cs := t.childs(p)
+		idx := t.search(cs, item)
+
+		// child before idx may be equal or covers item
+		if idx > 0 {
+			c := t.items[cs[idx-1]]
+			if c.Equals(item) {
+				path = append(path, c)
+				break
+			}
+			if c.Covers(item) {
+				path = append(path, c)
+				p = cs[idx-1]
+				continue
+			}
+		}
+		break
+	}
+
+	// bottom-up, most specific first
+	if len(path) > k {
+		path = path[len(path)-k:]
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// Within returns all items in tree equal to or covered by window, in sort order, O(log n + k).
+// Returns nil if there are no such items. As for all queries, window must not overlap items partially.
+func (t *Tree) Within(window Interface) []Interface {
+	if t == nil || window == nil {
+		return nil
+	}
+	window = t.wrap(window)
+
+	// the covered items follow window in sort order, contiguous
+	i := sort.Search(len(t.items), func(i int) bool { return !t.items[i].Less(window) })
+
+	var out []Interface
+	for ; i < len(t.items); i++ {
+		item := t.items[i]
+		if !window.Equals(item) && !window.Covers(item) {
+			break
+		}
+
+		// skip the dups, like Walk
+		if len(out) > 0 && out[len(out)-1].Equals(item) {
+			continue
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+// Min returns the first item in sort order, nil for an empty tree.
+func (t *Tree) Min() Interface {
+	if t == nil || len(t.items) == 0 {
+		return nil
+	}
+	return t.items[0]
+}
+
+// Max returns the last item in sort order, nil for an empty tree.
+func (t *Tree) Max() Interface {
+	if t == nil || len(t.items) == 0 {
+		return nil
+	}
+	return t.items[len(t.items)-1]
+}
+
+// All returns an iterator over the items in sort order, duplicates skipped, like Walk.
+// The iteration stops when yield returns false.
+//
+// The signature is compatible with iter.Seq[Interface], with go1.23 and later:
+//
+//  for item := range t.All() {
+//  	...
+//  }
+func (t *Tree) All() func(yield func(Interface) bool) {
+	return func(yield func(Interface) bool) {
+		if t == nil {
+			return
+		}
+		for i, item := range t.items {
+			if i > 0 && t.items[i-1].Equals(item) {
+				continue
+			}
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// Leaves returns the items without childs in sort order, the most specific items.
+// Duplicates are skipped.
+func (t *Tree) Leaves() []Interface {
+	if t == nil {
+		return nil
+	}
+
+	var leaves []Interface
+	for i, item := range t.items {
+		if i > 0 && t.items[i-1].Equals(item) {
+			continue
+		}
+		if len(t.childs(i)) == 0 {
+			leaves = append(leaves, item)
+		}
+	}
+	return leaves
+}
+
+// LeafCount returns the number of items without childs, see Leaves.
+func (t *Tree) LeafCount() int {
+	if t == nil {
+		return 0
+	}
+
+	n := 0
+	for i, item := range t.items {
+		if i > 0 && t.items[i-1].Equals(item) {
+			continue
+		}
+		if len(t.childs(i)) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// Children returns the direct descendants of item in tree.
+// Returns nil if item isn't in tree or has no children.
+func (t *Tree) Children(item Interface) []Interface {
+	i, _, ok := t.find(item)
+	if !ok {
+		return nil
+	}
+
+	var childs []Interface
+	for _, c := range t.childs(i) {
+		childs = append(childs, t.items[c])
+	}
+	return childs
+}
+
+// Parent returns the direct ancestor of item in tree.
+// ok is false if item isn't in tree or is a root item.
+func (t *Tree) Parent(item Interface) (parent Interface, ok bool) {
+	_, p, ok := t.find(item)
+	if !ok || p == root {
+		return nil, false
+	}
+	return t.items[p], true
+}
+
+// Precedes returns the closest item in tree entirely before item, neither covering nor equal to item.
+// On ties, e.g. a parent and its last child, the outermost item is returned.
+// ok is false if there is no such item.
+func (t *Tree) Precedes(item Interface) (pred Interface, ok bool) {
+	if t == nil || item == nil {
+		return
+	}
+	item = t.wrap(item)
+
+	// descent along the covering items, deeper candidates are closer
+	p := root
+	for {
+		cs := t.childs(p)
+		idx := t.search(cs, item)
+		if idx == 0 {
+			return
+		}
+
+		// child before idx may be equal or covers item, the sibling before is disjunct
+		c := cs[idx-1]
+		if !t.items[c].Equals(item) && !t.items[c].Covers(item) {
+			return t.items[c], true
+		}
+		if idx > 1 {
+			pred, ok = t.items[cs[idx-2]], true
+		}
+		if t.items[c].Equals(item) {
+			return
+		}
+		p = c
+	}
+}
+
+// Follows returns the closest item in tree entirely after item, neither covered by nor equal to item.
+// On ties, e.g. a parent and its first child, the outermost item is returned.
+// ok is false if there is no such item.
+func (t *Tree) Follows(item Interface) (succ Interface, ok bool) {
+	if t == nil || item == nil {
+		return
+	}
+	item = t.wrap(item)
+
+	// descent along the covering items, deeper candidates are closer
+	p := root
+	for {
+		cs := t.childs(p)
+		idx := t.search(cs, item)
+
+		// skip the childs covered by item, item needn't be in tree
+		j := idx + sort.Search(len(cs)-idx, func(k int) bool { return !item.Covers(t.items[cs[idx+k]]) })
+		if j < len(cs) {
+			succ, ok = t.items[cs[j]], true
+		}
+
+		// child before idx may be equal or covers item
+		if idx == 0 {
+			return
+		}
+		c := cs[idx-1]
+		if !t.items[c].Covers(item) {
+			return
+		}
+		p = c
+	}
+}
+
+// find returns the index of item and the index of its parent, rec-descent from root.
+func (t *Tree) find(item Interface) (i, p int, ok bool) {
+	if t == nil || item == nil {
+		return
+	}
+	item = t.wrap(item)
+
+	p = root
+	for {
+		cs := t.childs(p)
+
+		// find pos in slice on this level
+		idx := t.search(cs, item)
+		if idx == 0 {
+			return
+		}
+
+		// child before idx may be equal or covers item
+		c := cs[idx-1]
+		if t.items[c].Equals(item) {
+			return c, p, true
+		}
+		if !t.items[c].Covers(item) {
+			return
+		}
+		p = c
+	}
+}
+
+// Columner is an optional interface for items with columnar payload, see String.
+type Columner interface {
+	// Columns returns the payload fields of the item.
+	Columns() []string
+}
+
+// Appender is an optional interface for items, see String.
+type Appender interface {
+	// AppendTo appends the same text as String to dst and returns the extended buffer.
+	AppendTo(dst []byte) []byte
+}
+
+// appendItem appends the text of the item, without intermediate string if possible
+func appendItem(dst []byte, item Interface) []byte {
+	if a, ok := item.(Appender); ok {
+		return a.AppendTo(dst)
+	}
+	return append(dst, item.String()...)
+}
+
 // String returns the ordered tree as a directory graph.
-// The items are stringified using their fmt.Stringer interface.
+// The items are stringified using their fmt.Stringer interface,
+// or rendered directly into the output if they implement the Appender interface,
+// making full dumps of large trees feasible.
+//
+// If items implement the Columner interface, the fields are printed behind the graph,
+// aligned in columns across the whole tree.
 func (t *Tree) String() string {
-	str := t.walkAndStringify(root, new(strings.Builder), "").String()
+	return string(t.render(nil))
+}
+
+// Fprint writes the tree as directory graph to w, like String, but the items are
+// labeled by the label function, e.g. to print the same tree with different verbosity.
+// A nil label function renders the items like String.
+func (t *Tree) Fprint(w io.Writer, label func(Interface) string) error {
+	_, err := w.Write(t.render(label))
+	return err
+}
 
-	if str == "" {
-		return ""
+// render the tree as directory graph, see String and Fprint
+func (t *Tree) render(label func(Interface) string) []byte {
+	lines := t.walkAndStringify(root, nil, "")
+	if len(lines) == 0 {
+		return nil
+	}
+
+	// column widths in runes, graph and item string in column 0
+	var widths []int
+	var graph []byte
+	hasColumns := false
+	for _, l := range lines {
+		c, ok := l.item.(Columner)
+		if !ok {
+			continue
+		}
+		hasColumns = true
+
+		graph = l.appendGraph(graph[:0], label)
+		for j, cell := range append([]string{string(graph)}, c.Columns()...) {
+			if j == len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := utf8.RuneCountInString(cell); n > widths[j] {
+				widths[j] = n
+			}
+		}
+	}
+
+	buf := make([]byte, 0, 64*len(lines))
+	buf = append(buf, "▼\n"...)
+	for _, l := range lines {
+		c, ok := l.item.(Columner)
+		if !hasColumns || !ok {
+			buf = append(l.appendGraph(buf, label), '\n')
+			continue
+		}
+
+		row := len(buf)
+		buf = l.appendGraph(buf, label)
+		width := utf8.RuneCount(buf[row:])
+		for j, cell := range c.Columns() {
+			buf = append(buf, strings.Repeat(" ", widths[j]-width+2)...)
+			buf = append(buf, cell...)
+			width = utf8.RuneCountInString(cell)
+		}
+		buf = append(bytes.TrimRight(buf, " "), '\n')
+	}
+	return buf
+}
+
+// treeLine, the padding, the branch and the item, rendered late
+type treeLine struct {
+	pad  string
+	last bool
+	item Interface
+}
+
+// appendGraph appends the graph with the rendered item, labeled by label if not nil
+func (l treeLine) appendGraph(dst []byte, label func(Interface) string) []byte {
+	dst = append(dst, l.pad...)
+	if l.last {
+		dst = append(dst, "└─ "...)
+	} else {
+		dst = append(dst, "├─ "...)
+	}
+	if label != nil {
+		return append(dst, label(l.item)...)
 	}
-	return "▼\n" + str
+	return appendItem(dst, l.item)
 }
 
 // walkAndStringify rec-descent, top-down
-func (t *Tree) walkAndStringify(p int, buf *strings.Builder, pad string) *strings.Builder {
-	cs := t.tree[p]
+func (t *Tree) walkAndStringify(p int, lines []treeLine, pad string) []treeLine {
+	cs := t.childs(p)
 	l := len(cs)
 
 	// stop condition, no more childs
 	if l == 0 {
-		return buf
+		return lines
 	}
 
 	// !!! stop before last child (<= l-2)
@@ -219,15 +1045,15 @@ func (t *Tree) walkAndStringify(p int, buf *strings.Builder, pad string) *string
 	for ; i <= l-2; i++ {
 		v := cs[i] // dereference
 
-		buf.WriteString(pad + "├─ " + t.items[v].String() + "\n")
-		buf = t.walkAndStringify(v, buf, pad+"│  ")
+		lines = append(lines, treeLine{pad, false, t.items[v]})
+		lines = t.walkAndStringify(v, lines, pad+"│  ")
 	}
 
 	// treat last child special
 	v := cs[i] // dereference
 
-	buf.WriteString(pad + "└─ " + t.items[v].String() + "\n")
-	return t.walkAndStringify(v, buf, pad+"   ")
+	lines = append(lines, treeLine{pad, true, t.items[v]})
+	return t.walkAndStringify(v, lines, pad+"   ")
 }
 
 // WalkFunc is the type of the function called by Walk to visit each item.
@@ -253,7 +1079,7 @@ func (t *Tree) Walk(fn WalkFunc) error {
 	}
 
 	// for all child indexes of the root item...
-	for _, v := range t.tree[root] {
+	for _, v := range t.childs(root) {
 		if err := t.walk(fn, 0, v, root); err != nil {
 			return err
 		}
@@ -271,7 +1097,7 @@ func (t *Tree) walk(fn WalkFunc, d, i, p int) error {
 		parent = t.items[p]
 	}
 
-	cs := t.tree[i]
+	cs := t.childs(i)
 	for _, v := range cs {
 		childs = append(childs, t.items[v])
 	}
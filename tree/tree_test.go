@@ -1,10 +1,17 @@
 package tree
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 // simple test interval
@@ -177,6 +184,43 @@ func TestTreeSuperset(t *testing.T) {
 	}
 }
 
+func TestTreeLookupK(t *testing.T) {
+	tree, _ := New([]Interface{ival{0, 0}, ival{1, 100}, ival{50, 80}, ival{60, 70}, ival{200, 300}})
+
+	item := ival{65, 66}
+	if got := tree.LookupLPM(item); got != (ival{60, 70}) {
+		t.Errorf("LookupLPM(%v) = %v, want %v", item, got, ival{60, 70})
+	}
+	if got := tree.LookupShortest(item); got != (ival{1, 100}) {
+		t.Errorf("LookupShortest(%v) = %v, want %v", item, got, ival{1, 100})
+	}
+
+	tests := []struct {
+		item Interface
+		k    int
+		want []Interface
+	}{
+		{ival{65, 66}, 5, []Interface{ival{60, 70}, ival{50, 80}, ival{1, 100}}},
+		{ival{65, 66}, 2, []Interface{ival{60, 70}, ival{50, 80}}},
+		{ival{50, 80}, 3, []Interface{ival{50, 80}, ival{1, 100}}},
+		{ival{250, 250}, 3, []Interface{ival{200, 300}}},
+		{ival{65, 66}, 0, nil},
+		{ival{101, 101}, 3, nil},
+		{nil, 3, nil},
+	}
+
+	for _, tt := range tests {
+		if got := tree.LookupK(tt.item, tt.k); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("LookupK(%v, %d) = %v, want %v", tt.item, tt.k, got, tt.want)
+		}
+	}
+
+	var nilTree *Tree
+	if got := nilTree.LookupK(item, 1); got != nil {
+		t.Errorf("nil tree, LookupK(%v, 1) = %v, want nil", item, got)
+	}
+}
+
 func TestTreeWalk(t *testing.T) {
 	var tree *Tree
 	if i := tree.Walk(nil); i != nil {
@@ -256,3 +300,1320 @@ func TestTreeRandom(t *testing.T) {
 		}
 	}
 }
+
+func TestTreeChildrenParent(t *testing.T) {
+	is := []Interface{
+		ival{1, 100},
+		ival{2, 50},
+		ival{3, 4},
+		ival{5, 8},
+		ival{60, 70},
+		ival{200, 300},
+	}
+
+	tree, err := New(is)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		item   ival
+		childs []Interface
+		parent Interface
+	}{
+		{ival{1, 100}, []Interface{ival{2, 50}, ival{60, 70}}, nil},
+		{ival{2, 50}, []Interface{ival{3, 4}, ival{5, 8}}, ival{1, 100}},
+		{ival{5, 8}, nil, ival{2, 50}},
+		{ival{200, 300}, nil, nil},
+		{ival{3, 5}, nil, nil}, // not in tree
+	}
+
+	for _, tt := range tests {
+		childs := tree.Children(tt.item)
+		if fmt.Sprint(childs) != fmt.Sprint(tt.childs) {
+			t.Errorf("Children(%v), got %v, want %v", tt.item, childs, tt.childs)
+		}
+
+		parent, ok := tree.Parent(tt.item)
+		if ok != (tt.parent != nil) || parent != tt.parent {
+			t.Errorf("Parent(%v), got %v, %v, want %v", tt.item, parent, ok, tt.parent)
+		}
+	}
+
+	var nilTree *Tree
+	if _, ok := nilTree.Parent(ival{1, 2}); ok {
+		t.Errorf("Parent() on nil tree, expected ok == false")
+	}
+}
+
+// generateLaminar, random intervals without partial overlaps
+func generateLaminar(prng *rand.Rand, n, max int) []Interface {
+	var is []Interface
+	for len(is) < n {
+		a, b := prng.Intn(max), prng.Intn(max)
+		if a > b {
+			a, b = b, a
+		}
+		q := ival{a, b}
+		if !partialOverlap(is, q) {
+			is = append(is, q)
+		}
+	}
+	return is
+}
+
+// partialOverlap, q overlaps any of is partially, equal counts as well
+func partialOverlap(is []Interface, q ival) bool {
+	for _, x := range is {
+		v := x.(ival)
+		if v == q {
+			return true
+		}
+		if v.Covers(q) || q.Covers(v) {
+			continue
+		}
+		if v.lo <= q.hi && q.lo <= v.hi {
+			return true
+		}
+	}
+	return false
+}
+
+func TestTreePrecedesFollows(t *testing.T) {
+	prng := rand.New(rand.NewSource(1))
+
+	for n := 0; n < 300; n++ {
+		is := generateLaminar(prng, 1+prng.Intn(30), 100)
+		tree, _ := New(is)
+
+		for k := 0; k < 30; k++ {
+			// query in tree or not overlapping partially
+			q := is[prng.Intn(len(is))].(ival)
+			if k%2 == 0 {
+				a, b := prng.Intn(100), prng.Intn(100)
+				if a > b {
+					a, b = b, a
+				}
+				q = ival{a, b}
+				if partialOverlap(is, q) && !contains(is, q) {
+					continue
+				}
+			}
+
+			// brute force, closest end before, closest start after, outermost on ties
+			var pred, succ Interface
+			for _, x := range is {
+				v := x.(ival)
+				if v.hi < q.lo && (pred == nil || v.hi > pred.(ival).hi || v.hi == pred.(ival).hi && v.lo < pred.(ival).lo) {
+					pred = v
+				}
+				if v.lo > q.hi && (succ == nil || v.lo < succ.(ival).lo || v.lo == succ.(ival).lo && v.hi > succ.(ival).hi) {
+					succ = v
+				}
+			}
+
+			if got, ok := tree.Precedes(q); got != pred || ok != (pred != nil) {
+				t.Fatalf("Precedes(%v), got %v, %v, want %v\n%v", q, got, ok, pred, tree)
+			}
+			if got, ok := tree.Follows(q); got != succ || ok != (succ != nil) {
+				t.Fatalf("Follows(%v), got %v, %v, want %v\n%v", q, got, ok, succ, tree)
+			}
+		}
+	}
+}
+
+func contains(is []Interface, q Interface) bool {
+	for _, x := range is {
+		if x == q {
+			return true
+		}
+	}
+	return false
+}
+
+func TestTreeWithin(t *testing.T) {
+	prng := rand.New(rand.NewSource(1))
+
+	for n := 0; n < 300; n++ {
+		is := generateLaminar(prng, 1+prng.Intn(30), 100)
+		tree, _ := New(append(is, is[0]))
+
+		for k := 0; k < 30; k++ {
+			a, b := prng.Intn(100), prng.Intn(100)
+			if a > b {
+				a, b = b, a
+			}
+			w := ival{a, b}
+			if partialOverlap(is, w) && !contains(is, w) {
+				continue
+			}
+
+			// brute force, sorted like the tree
+			var want []Interface
+			for _, x := range sortedCopy(is) {
+				if w.Equals(x) || w.Covers(x) {
+					want = append(want, x)
+				}
+			}
+
+			if got := tree.Within(w); fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Fatalf("Within(%v), got %v, want %v", w, got, want)
+			}
+		}
+	}
+
+	if got := (&Tree{}).Within(ival{1, 2}); got != nil {
+		t.Errorf("Within() on empty tree, got %v, want nil", got)
+	}
+}
+
+func TestTreeMinMaxAll(t *testing.T) {
+	tree, _ := New([]Interface{ival{5, 8}, ival{1, 100}, ival{2, 50}, ival{5, 8}, ival{200, 300}})
+
+	if got := tree.Min(); got != (ival{1, 100}) {
+		t.Errorf("Min(), got %v, want %v", got, ival{1, 100})
+	}
+	if got := tree.Max(); got != (ival{200, 300}) {
+		t.Errorf("Max(), got %v, want %v", got, ival{200, 300})
+	}
+
+	var got []Interface
+	tree.All()(func(item Interface) bool {
+		got = append(got, item)
+		return true
+	})
+	want := []Interface{ival{1, 100}, ival{2, 50}, ival{5, 8}, ival{200, 300}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("All(), got %v, want %v", got, want)
+	}
+
+	// stop early
+	got = nil
+	tree.All()(func(item Interface) bool {
+		got = append(got, item)
+		return len(got) < 2
+	})
+	if len(got) != 2 {
+		t.Errorf("All(), stop after 2, got %v", got)
+	}
+
+	var empty *Tree
+	if empty.Min() != nil || empty.Max() != nil {
+		t.Errorf("Min(), Max() on nil tree, want nil")
+	}
+	empty.All()(func(Interface) bool {
+		t.Errorf("All() on nil tree, want no items")
+		return true
+	})
+}
+
+func TestTreeLeaves(t *testing.T) {
+	tree, _ := New([]Interface{ival{1, 100}, ival{50, 80}, ival{60, 70}, ival{60, 70}, ival{90, 95}, ival{200, 300}})
+
+	want := []Interface{ival{60, 70}, ival{90, 95}, ival{200, 300}}
+	if got := tree.Leaves(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Leaves(), got %v, want %v", got, want)
+	}
+	if got := tree.LeafCount(); got != 3 {
+		t.Errorf("LeafCount(), got %d, want 3", got)
+	}
+
+	var nilTree *Tree
+	if nilTree.Leaves() != nil || nilTree.LeafCount() != 0 {
+		t.Errorf("nil tree, want no leaves")
+	}
+
+	// brute force
+	is := generateIvals(1_000)
+	tree, _ = New(is)
+	n := 0
+	tree.Walk(func(_ int, _, _ Interface, childs []Interface) error {
+		if childs == nil {
+			n++
+		}
+		return nil
+	})
+	if got := tree.LeafCount(); got != n || len(tree.Leaves()) != n {
+		t.Errorf("LeafCount(), got %d, want %d", got, n)
+	}
+}
+
+func TestTreeInsertRemove(t *testing.T) {
+	tree, err := New([]Interface{ival{1, 100}, ival{60, 70}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree2, err := tree.Insert(ival{50, 80}, ival{200, 300}, ival{0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tree.Len() != 2 {
+		t.Errorf("Insert modified receiver, Len() = %d, want 2", tree.Len())
+	}
+
+	want := `▼
+├─ 0...0
+├─ 1...100
+│  └─ 50...80
+│     └─ 60...70
+└─ 200...300
+`
+	if got := tree2.String(); got != want {
+		t.Errorf("Insert, got:\n%swant:\n%s", got, want)
+	}
+
+	// dups
+	if _, err := tree2.Insert(ival{60, 70}); err == nil {
+		t.Errorf("Insert duplicate, expected error")
+	}
+
+	// remove parent, childs move up
+	tree3 := tree2.Remove(ival{50, 80}, ival{0, 0}, ival{7, 7})
+
+	if tree2.Len() != 5 {
+		t.Errorf("Remove modified receiver, Len() = %d, want 5", tree2.Len())
+	}
+
+	want = `▼
+├─ 1...100
+│  └─ 60...70
+└─ 200...300
+`
+	if got := tree3.String(); got != want {
+		t.Errorf("Remove, got:\n%swant:\n%s", got, want)
+	}
+
+	if got := tree3.Remove(ival{1, 100}, ival{60, 70}, ival{200, 300}).Len(); got != 0 {
+		t.Errorf("Remove all, Len() = %d, want 0", got)
+	}
+
+	// insert into empty tree
+	var nilTree *Tree
+	if got, _ := nilTree.Insert(ival{1, 2}); got.Len() != 1 {
+		t.Errorf("Insert into nil tree, Len() = %d, want 1", got.Len())
+	}
+}
+
+func TestTreeSubtreePrune(t *testing.T) {
+	tree, _ := New([]Interface{ival{0, 0}, ival{1, 100}, ival{50, 80}, ival{60, 70}, ival{90, 95}, ival{200, 300}})
+
+	sub, ok := tree.Subtree(ival{50, 80})
+	if !ok {
+		t.Fatalf("Subtree(50...80), want ok")
+	}
+	want := `▼
+└─ 50...80
+   └─ 60...70
+`
+	if got := sub.String(); got != want {
+		t.Errorf("Subtree, got:\n%swant:\n%s", got, want)
+	}
+
+	if _, ok := tree.Subtree(ival{50, 81}); ok {
+		t.Errorf("Subtree(50...81), want !ok")
+	}
+
+	// prune parent, childs move up
+	pruned := tree.Prune(func(item Interface) bool {
+		v := item.(ival)
+		return v.hi-v.lo == 30 || v.lo == 0
+	})
+	want = `▼
+├─ 1...100
+│  ├─ 60...70
+│  └─ 90...95
+└─ 200...300
+`
+	if got := pruned.String(); got != want {
+		t.Errorf("Prune, got:\n%swant:\n%s", got, want)
+	}
+
+	if tree.Len() != 6 {
+		t.Errorf("Prune modified receiver, Len() = %d, want 6", tree.Len())
+	}
+
+	if got := tree.Prune(func(Interface) bool { return true }).Len(); got != 0 {
+		t.Errorf("Prune all, Len() = %d, want 0", got)
+	}
+}
+
+func TestTreeObserver(t *testing.T) {
+	var got []string
+	observer := func(e Event) {
+		got = append(got, fmt.Sprintf("%s %v %d->%d", e.Op, e.Items, e.Before.Len(), e.After.Len()))
+	}
+
+	tree, _ := New(nil, WithObserver(observer))
+	tree, _ = tree.Insert(ival{1, 100}, ival{60, 70})
+	tree, _ = tree.Insert(ival{200, 300})
+
+	// errors are not observed, but the returned tree keeps the observer
+	bad, err := tree.Insert(ival{60, 70})
+	if err == nil {
+		t.Errorf("Insert duplicate, expected error")
+	}
+	if bad == nil || bad.observer == nil {
+		t.Errorf("Insert duplicate, observer lost")
+	}
+
+	tree = tree.Remove(ival{60, 70}, ival{7, 7})
+	tree, _ = tree.Replace(ival{1, 100}, ival{1, 100})
+
+	// the observer is inherited by subtrees
+	sub, _ := tree.Subtree(ival{200, 300})
+	sub.Prune(func(Interface) bool { return true })
+
+	want := []string{
+		"Insert [1...100 60...70] 0->2",
+		"Insert [200...300] 2->3",
+		"Remove [60...70] 3->2",
+		"Replace [1...100 1...100] 2->2",
+		"Prune [200...300] 1->0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events, got:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+// recMetrics records the metrics
+type recMetrics struct {
+	lookups []string
+	builds  []string
+}
+
+func (m *recMetrics) Lookup(hit bool, depth int) {
+	m.lookups = append(m.lookups, fmt.Sprintf("%v/%d", hit, depth))
+}
+
+func (m *recMetrics) Build(items, depth int, _ time.Duration) {
+	m.builds = append(m.builds, fmt.Sprintf("%d/%d", items, depth))
+}
+
+func TestTreeMetrics(t *testing.T) {
+	m := &recMetrics{}
+	tree, _ := New([]Interface{ival{1, 100}, ival{50, 80}, ival{60, 70}}, WithMetrics(m))
+
+	if tree.Metrics() != m {
+		t.Errorf("Metrics, got %v, want %v", tree.Metrics(), m)
+	}
+	if got := tree.Depth(); got != 3 {
+		t.Errorf("Depth, got %d, want 3", got)
+	}
+
+	tree.Lookup(ival{65, 65})
+	tree.Lookup(ival{1, 100})
+	tree.Lookup(ival{90, 90})
+	tree.Lookup(ival{200, 200})
+
+	// inherited by derived trees
+	tree, _ = tree.Insert(ival{200, 300})
+	tree = tree.Remove(ival{50, 80})
+	tree.Lookup(ival{65, 65})
+
+	wantLookups := []string{"true/3", "true/1", "true/1", "false/0", "true/2"}
+	if !reflect.DeepEqual(m.lookups, wantLookups) {
+		t.Errorf("lookups, got %v, want %v", m.lookups, wantLookups)
+	}
+
+	wantBuilds := []string{"3/3", "4/3", "3/2"}
+	if !reflect.DeepEqual(m.builds, wantBuilds) {
+		t.Errorf("builds, got %v, want %v", m.builds, wantBuilds)
+	}
+}
+
+func TestTreeInsertRandom(t *testing.T) {
+	is := generateIvals(1_000)
+
+	want, _ := New(is)
+
+	half, _ := New(is[:len(is)/2])
+	got, err := half.Insert(is[len(is)/2:]...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.String() != want.String() {
+		t.Errorf("Insert, tree differs from New")
+	}
+
+	if got.Remove(is[len(is)/2:]...).String() != half.String() {
+		t.Errorf("Remove, tree differs from New")
+	}
+}
+
+// broken Less, not transitive, for contract validation
+type brokenIval struct {
+	ival
+}
+
+func (a brokenIval) Equals(i Interface) bool { return a.ival == i.(brokenIval).ival }
+func (a brokenIval) Covers(i Interface) bool { return a.ival.Covers(i.(brokenIval).ival) }
+
+// Less, forgot to sort containers to the left
+func (a brokenIval) Less(i Interface) bool {
+	b := i.(brokenIval)
+	if a.lo == b.lo {
+		return a.hi < b.hi
+	}
+	return a.lo < b.lo
+}
+
+func TestTreeLimits(t *testing.T) {
+	// nested ivals, depth 10
+	var is []Interface
+	for i := 0; i < 10; i++ {
+		is = append(is, ival{i, 100 - i})
+	}
+	is = append(is, ival{200, 300})
+
+	var le *LimitError
+	if _, err := New(is, WithMaxItems(10)); !errors.As(err, &le) || le.Limit != "max items" {
+		t.Errorf("WithMaxItems(10), got %v, want *LimitError", err)
+	}
+
+	_, err := New(is, WithMaxDepth(9))
+	if !errors.As(err, &le) || le.Limit != "max depth" || le.Item != (ival{9, 91}) {
+		t.Errorf("WithMaxDepth(9), got %v, want *LimitError for 9...91", err)
+	}
+
+	if _, err := New(is, WithMaxItems(11), WithMaxDepth(10)); err != nil {
+		t.Errorf("limits not exceeded, got %v", err)
+	}
+}
+
+func TestTreeNewContext(t *testing.T) {
+	is := generateIvals(3000)
+
+	var got [][2]int
+	progress := func(done, total int) { got = append(got, [2]int{done, total}) }
+
+	tree, err := NewContext(context.Background(), is, WithProgress(1000, progress))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Len() != len(is) {
+		t.Errorf("Len, got %d, want %d", tree.Len(), len(is))
+	}
+
+	// generateIvals drops duplicates, less than 3000 items
+	n := len(is)
+	want := [][2]int{{1000, n}, {2000, n}, {n, n}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("progress, got %v, want %v", got, want)
+	}
+
+	// canceled before
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewContext(ctx, is); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled context, got %v, want context.Canceled", err)
+	}
+
+	// canceled during the build
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	got = nil
+	stop := func(done, total int) {
+		got = append(got, [2]int{done, total})
+		cancel()
+	}
+	tree, err = NewContext(ctx, is, WithProgress(1000, stop))
+	if !errors.Is(err, context.Canceled) || tree.Len() != 0 {
+		t.Errorf("canceled during build, got %v, Len %d, want context.Canceled, Len 0", err, tree.Len())
+	}
+	if len(got) != 1 {
+		t.Errorf("progress after cancel, got %v", got)
+	}
+}
+
+func TestTreePhaseProgress(t *testing.T) {
+	is := generateIvals(3000)
+
+	// the ends of the phases, in order, the validation sorts the items first
+	var ends []string
+	steps := map[string]int{}
+	progress := func(phase string, done, total int) {
+		steps[phase]++
+		if done == total {
+			ends = append(ends, phase)
+		}
+	}
+
+	if _, err := NewContext(context.Background(), is, WithValidation(100), WithPhaseProgress(1000, progress)); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{PhaseSort, PhaseValidate, PhaseSort, PhaseBuild}; !reflect.DeepEqual(ends, want) {
+		t.Errorf("phase ends, got %v, want %v", ends, want)
+	}
+	if steps[PhaseSort] < 4 {
+		t.Errorf("sort progress, got %d reports, want more", steps[PhaseSort])
+	}
+
+	// canceled during the sort and during the validation
+	for _, phase := range []string{PhaseSort, PhaseValidate} {
+		ctx, cancel := context.WithCancel(context.Background())
+		stop := func(p string, done, total int) {
+			if p == phase && done < total {
+				cancel()
+			}
+		}
+
+		tree, err := NewContext(ctx, generateIvals(20000), WithValidation(5000), WithPhaseProgress(100, stop))
+		if !errors.Is(err, context.Canceled) || !strings.Contains(err.Error(), phase+" canceled") || tree.Len() != 0 {
+			t.Errorf("canceled during %s, got %v, Len %d, want context.Canceled, Len 0", phase, err, tree.Len())
+		}
+		cancel()
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	is := generateIvals(500)
+	want, _ := New(is)
+
+	b := NewBuilder(WithMaxDepth(100))
+	t1, err := b.Build(is)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if t1.String() != want.String() {
+		t.Errorf("Build differs from New")
+	}
+
+	// without Reset, t1 stays valid
+	t2, _ := b.Build(is[:100])
+	if t1.String() != want.String() {
+		t.Errorf("Build without Reset modified the last tree")
+	}
+	if &t2.kids[0] == &t1.kids[0] {
+		t.Errorf("Build without Reset, storage shared")
+	}
+
+	// with Reset, the storage of t2 is reused
+	b.Reset()
+	t3, _ := b.Build(is[:50])
+	if &t3.kids[0] != &t2.kids[0] || &t3.items[0] != &t2.items[0] {
+		t.Errorf("Build after Reset, storage not reused")
+	}
+
+	small, _ := New(is[:50])
+	if t3.String() != small.String() {
+		t.Errorf("Build after Reset differs from New")
+	}
+
+	// the options of the builder are applied
+	var le *LimitError
+	if _, err := NewBuilder(WithMaxItems(10)).Build(is); !errors.As(err, &le) {
+		t.Errorf("Build with WithMaxItems(10), got %v, want *LimitError", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(generateIvals(200)); err != nil {
+		t.Errorf("Validate(), unexpected error: %v", err)
+	}
+
+	is := []Interface{brokenIval{ival{1, 5}}, brokenIval{ival{1, 100}}}
+	err := Validate(is)
+
+	var cerr *ContractError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("Validate(), expected ContractError, got: %v", err)
+	}
+	if cerr.Rule != "Covers implies Less" {
+		t.Errorf("Validate(), got rule %q, want %q", cerr.Rule, "Covers implies Less")
+	}
+
+	if _, err := New(is, WithValidation(0)); err == nil {
+		t.Errorf("New(WithValidation), expected error")
+	}
+
+	if _, err := New(is); err != nil {
+		t.Errorf("New() without validation, unexpected error: %v", err)
+	}
+
+	if _, err := New(generateIvals(10_000), WithValidation(100)); err != nil {
+		t.Errorf("New(WithValidation(100)), unexpected error: %v", err)
+	}
+}
+
+// ival implementing the Overlapper interface
+type ovIval struct {
+	ival
+}
+
+func (a ovIval) Equals(i Interface) bool { return a.ival.Equals(i.(ovIval).ival) }
+func (a ovIval) Covers(i Interface) bool { return a.ival.Covers(i.(ovIval).ival) }
+func (a ovIval) Less(i Interface) bool   { return a.ival.Less(i.(ovIval).ival) }
+
+func (a ovIval) Overlaps(i Interface) bool {
+	b := i.(ovIval)
+	if a == b || a.Covers(b) || b.Covers(a) {
+		return false
+	}
+	return a.lo <= b.hi && b.lo <= a.hi
+}
+
+func TestTreeOverlaps(t *testing.T) {
+	is := []Interface{
+		ovIval{ival{0, 30}},
+		ovIval{ival{5, 8}},
+		ovIval{ival{6, 40}},
+	}
+
+	tree, err := New(is)
+
+	var oerr *OverlapError
+	if !errors.As(err, &oerr) {
+		t.Fatalf("New(), expected OverlapError, got: %v", err)
+	}
+	if len(oerr.Pairs) != 1 || len(tree.Overlaps()) != 1 {
+		t.Errorf("New(), expected one overlapping pair, got: %v", oerr.Pairs)
+	}
+
+	// brute force, detection must match
+	prng := rand.New(rand.NewSource(42))
+	for n := 0; n < 500; n++ {
+		var is []Interface
+		set := map[ival]bool{}
+		for i := 0; i < 8; i++ {
+			a, b := prng.Intn(50), prng.Intn(50)
+			if a > b {
+				a, b = b, a
+			}
+			if !set[ival{a, b}] {
+				set[ival{a, b}] = true
+				is = append(is, ovIval{ival{a, b}})
+			}
+		}
+
+		want := false
+		for i := range is {
+			for j := range is {
+				if is[i].(ovIval).Overlaps(is[j]) {
+					want = true
+				}
+			}
+		}
+
+		tree, _ := New(is)
+		if got := tree.Overlaps() != nil; got != want {
+			t.Fatalf("New(%v), overlaps detected: %v, want %v", is, got, want)
+		}
+		for _, pair := range tree.Overlaps() {
+			if !pair[0].(ovIval).Overlaps(pair[1]) {
+				t.Fatalf("New(%v), false positive: %v", is, pair)
+			}
+		}
+	}
+}
+
+// ival implementing the Columner interface
+type colIval struct {
+	ival
+	cols []string
+}
+
+func (a colIval) Equals(i Interface) bool { return a.ival.Equals(i.(colIval).ival) }
+func (a colIval) Covers(i Interface) bool { return a.ival.Covers(i.(colIval).ival) }
+func (a colIval) Less(i Interface) bool   { return a.ival.Less(i.(colIval).ival) }
+func (a colIval) Columns() []string       { return a.cols }
+
+func TestTreeStringColumns(t *testing.T) {
+	is := []Interface{
+		colIval{ival{0, 100}, []string{"root", "a"}},
+		colIval{ival{0, 10}, []string{"longer text", "b"}},
+		colIval{ival{1, 2}, []string{"x"}},
+		colIval{ival{200, 123456}, []string{"ü", "", "c"}},
+	}
+	tree, _ := New(is)
+
+	want := `▼
+├─ 0...100       root         a
+│  └─ 0...10     longer text  b
+│     └─ 1...2   x
+└─ 200...123456  ü               c
+`
+	if got := tree.String(); got != want {
+		t.Errorf("String(), got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// ival implementing the Appender interface, counting the calls
+type appIval struct {
+	ival
+	calls *int
+}
+
+func (a appIval) Equals(i Interface) bool { return a.ival.Equals(i.(appIval).ival) }
+func (a appIval) Covers(i Interface) bool { return a.ival.Covers(i.(appIval).ival) }
+func (a appIval) Less(i Interface) bool   { return a.ival.Less(i.(appIval).ival) }
+
+func (a appIval) AppendTo(dst []byte) []byte {
+	*a.calls++
+	return strconv.AppendInt(append(strconv.AppendInt(dst, int64(a.lo), 10), "..."...), int64(a.hi), 10)
+}
+
+func TestTreeStringAppender(t *testing.T) {
+	var calls int
+	var is, plain []Interface
+	for _, iv := range generateIvals(100) {
+		is = append(is, appIval{iv.(ival), &calls})
+		plain = append(plain, iv)
+	}
+
+	tree, _ := New(is)
+	plainTree, _ := New(plain)
+
+	if got, want := tree.String(), plainTree.String(); got != want {
+		t.Errorf("String() with Appender, got:\n%s\nwant:\n%s", got, want)
+	}
+	if calls != len(tree.items) {
+		t.Errorf("String(), AppendTo called %d times, want %d", calls, len(tree.items))
+	}
+}
+
+func TestTreeFprint(t *testing.T) {
+	is := []Interface{
+		colIval{ival{0, 100}, []string{"root"}},
+		colIval{ival{0, 10}, []string{"leaf"}},
+		colIval{ival{200, 300}, nil},
+	}
+	tree, _ := New(is)
+
+	short := func(i Interface) string { return strconv.Itoa(i.(colIval).lo) }
+
+	want := `▼
+├─ 0     root
+│  └─ 0  leaf
+└─ 200
+`
+	buf := new(strings.Builder)
+	if err := tree.Fprint(buf, short); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("Fprint(short), got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// nil label, same as String
+	buf.Reset()
+	if err := tree.Fprint(buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), tree.String(); got != want {
+		t.Errorf("Fprint(nil), got:\n%s\nwant:\n%s", got, want)
+	}
+
+	empty, _ := New(nil)
+	buf.Reset()
+	if err := empty.Fprint(buf, short); err != nil || buf.Len() != 0 {
+		t.Errorf("empty tree, Fprint, got %q, %v, want empty", buf.String(), err)
+	}
+}
+
+// ival with a tag, equal ivals with different tags are duplicates
+type tagIval struct {
+	ival
+	tag int
+}
+
+func (a tagIval) Equals(i Interface) bool { return a.ival.Equals(i.(tagIval).ival) }
+func (a tagIval) Covers(i Interface) bool { return a.ival.Covers(i.(tagIval).ival) }
+func (a tagIval) Less(i Interface) bool   { return a.ival.Less(i.(tagIval).ival) }
+
+func TestTreeDuplicatesFirstWins(t *testing.T) {
+	prng := rand.New(rand.NewSource(1))
+
+	// every ival three times, tagged in input order
+	var is []Interface
+	for _, iv := range generateIvals(300) {
+		for tag := 0; tag < 3; tag++ {
+			is = append(is, tagIval{iv.(ival), tag})
+		}
+	}
+
+	// shuffle, but keep the tags in input order per ival
+	prng.Shuffle(len(is), func(i, j int) { is[i], is[j] = is[j], is[i] })
+	next := make(map[ival]int)
+	for i, item := range is {
+		iv := item.(tagIval).ival
+		is[i] = tagIval{iv, next[iv]}
+		next[iv]++
+	}
+
+	check := func(name string, tree *Tree) {
+		for _, item := range is {
+			iv := item.(tagIval).ival
+			got := tree.Lookup(tagIval{iv, -1})
+			if got == nil || got.(tagIval).tag != 0 {
+				t.Fatalf("%s: Lookup(%v), got %v, want the first in input order", name, iv, got)
+			}
+		}
+		if len(tree.Duplicates()) != 2*len(next) {
+			t.Fatalf("%s: Duplicates(), got %d, want %d", name, len(tree.Duplicates()), 2*len(next))
+		}
+		for _, d := range tree.Duplicates() {
+			if d.(tagIval).tag == 0 {
+				t.Fatalf("%s: Duplicates(), got the first in input order %v", name, d)
+			}
+		}
+	}
+
+	tree, _ := New(is)
+	check("New", tree)
+
+	tree, _ = NewBuilder().Build(is)
+	check("Builder", tree)
+
+	// items already in tree win against inserted items
+	base, _ := New(is[:1])
+	first := is[0].(tagIval)
+	tree, _ = base.Insert(tagIval{first.ival, 99})
+	if got := tree.Lookup(first); got.(tagIval).tag != first.tag {
+		t.Errorf("Insert, got %v, want %v", got, first)
+	}
+}
+
+// ival implementing the Payloader interface
+type payIval struct {
+	ival
+	owner string
+}
+
+func (a payIval) Equals(i Interface) bool { return a.ival.Equals(i.(payIval).ival) }
+func (a payIval) Covers(i Interface) bool { return a.ival.Covers(i.(payIval).ival) }
+func (a payIval) Less(i Interface) bool   { return a.ival.Less(i.(payIval).ival) }
+
+func (a payIval) Payload() interface{} {
+	if a.owner == "" {
+		return nil
+	}
+	return map[string]string{"owner": a.owner}
+}
+
+func TestTreeExport(t *testing.T) {
+	is := []Interface{
+		payIval{ival{0, 100}, "<alice>"},
+		payIval{ival{0, 10}, ""},
+		payIval{ival{0, 10}, "dup"},
+		payIval{ival{200, 300}, "bob"},
+	}
+	tree, _ := New(is)
+
+	js, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"item":"0...100","payload":{"owner":"\u003calice\u003e"},"childs":[{"item":"0...10"}]},{"item":"200...300","payload":{"owner":"bob"}}]`
+	if string(js) != want {
+		t.Errorf("MarshalJSON(), got:\n%s\nwant:\n%s", js, want)
+	}
+
+	buf := new(strings.Builder)
+	if err := tree.WriteDOT(buf); err != nil {
+		t.Fatal(err)
+	}
+	want = `digraph tree {
+  node [shape=box];
+  n0 [label="0...100", tooltip="{\"owner\":\"\\u003calice\\u003e\"}"];
+  n1 [label="0...10"];
+  n0 -> n1;
+  n3 [label="200...300", tooltip="{\"owner\":\"bob\"}"];
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteDOT(), got:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := tree.WriteHTML(buf); err != nil {
+		t.Fatal(err)
+	}
+	want = `<ul>
+  <li data-payload="{&#34;owner&#34;:&#34;\u003calice\u003e&#34;}">0...100
+    <ul>
+      <li>0...10</li>
+    </ul>
+  </li>
+  <li data-payload="{&#34;owner&#34;:&#34;bob&#34;}">200...300</li>
+</ul>
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteHTML(), got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTreeReplace(t *testing.T) {
+	is := []Interface{
+		colIval{ival{0, 100}, []string{"root"}},
+		colIval{ival{0, 10}, []string{"old"}},
+		colIval{ival{200, 300}, []string{"c"}},
+	}
+	tree, _ := New(is)
+
+	tree2, err := tree.Replace(colIval{ival{0, 10}, nil}, colIval{ival{0, 10}, []string{"new"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `▼
+├─ 0...100    root
+│  └─ 0...10  new
+└─ 200...300  c
+`
+	if got := tree2.String(); got != want {
+		t.Errorf("Replace, got:\n%swant:\n%s", got, want)
+	}
+	if got := tree.Lookup(colIval{ival{5, 5}, nil}).(colIval).cols[0]; got != "old" {
+		t.Errorf("Replace modified receiver, got %q, want %q", got, "old")
+	}
+
+	if _, err := tree.Replace(colIval{ival{0, 10}, nil}, colIval{ival{0, 11}, nil}); err == nil {
+		t.Errorf("Replace with unequal item, want error")
+	}
+	if _, err := tree.Replace(colIval{ival{0, 11}, nil}, colIval{ival{0, 11}, nil}); err == nil {
+		t.Errorf("Replace missing item, want error")
+	}
+}
+
+func TestLookupCache(t *testing.T) {
+	tree, _ := New([]Interface{ival{1, 100}, ival{45, 60}})
+	c := NewLookupCache(tree, 2)
+
+	for _, tt := range []struct {
+		item ival
+		want Interface
+	}{
+		{ival{47, 50}, ival{45, 60}},
+		{ival{47, 50}, ival{45, 60}}, // hit
+		{ival{2, 3}, ival{1, 100}},
+		{ival{0, 3}, nil},            // evicts 47...50
+		{ival{47, 50}, ival{45, 60}}, // miss again
+	} {
+		if got := c.Lookup(tt.item); got != tt.want {
+			t.Errorf("Lookup(%v) = %v, want %v", tt.item, got, tt.want)
+		}
+	}
+
+	if hits, misses, entries := c.Stats(); hits != 1 || misses != 4 || entries != 2 {
+		t.Errorf("Stats() = %d, %d, %d, want 1, 4, 2", hits, misses, entries)
+	}
+
+	// rebuild
+	tree, _ = New([]Interface{ival{1, 100}})
+	c.Reset(tree)
+
+	if got := c.Lookup(ival{47, 50}); got != (ival{1, 100}) {
+		t.Errorf("Lookup after Reset = %v, want %v", got, ival{1, 100})
+	}
+	if _, _, entries := c.Stats(); entries != 1 {
+		t.Errorf("Stats() after Reset, entries = %d, want 1", entries)
+	}
+
+	if got := c.Lookup(nil); got != nil {
+		t.Errorf("Lookup(nil) = %v, want nil", got)
+	}
+}
+
+// benchSizes, the 10M tree only without -short, it takes a while to build.
+func benchSizes() []int {
+	if testing.Short() {
+		return []int{1_000, 100_000, 1_000_000}
+	}
+	return []int{1_000, 100_000, 1_000_000, 10_000_000}
+}
+
+func BenchmarkBuild(b *testing.B) {
+	for _, n := range benchSizes() {
+		is := generateIvals(n)
+
+		b.Run(fmt.Sprintf("New/n=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = New(is)
+			}
+		})
+
+		b.Run(fmt.Sprintf("Builder/n=%d", n), func(b *testing.B) {
+			bld := NewBuilder()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				bld.Reset()
+				_, _ = bld.Build(is)
+			}
+		})
+	}
+}
+
+func BenchmarkLookup(b *testing.B) {
+	for _, n := range benchSizes() {
+		is := generateIvals(n)
+		tree, _ := New(is)
+		prng := rand.New(rand.NewSource(1))
+
+		// box the queries in advance, measure just the tree
+		queries := make([]Interface, 1024)
+		for i := range queries {
+			lo := prng.Intn(n)
+			queries[i] = ival{lo, lo}
+		}
+
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = tree.Lookup(queries[i%len(queries)])
+			}
+		})
+	}
+}
+
+func BenchmarkSuperset(b *testing.B) {
+	for _, n := range benchSizes() {
+		is := generateIvals(n)
+		tree, _ := New(is)
+		prng := rand.New(rand.NewSource(1))
+
+		// box the queries in advance, measure just the tree
+		queries := make([]Interface, 1024)
+		for i := range queries {
+			lo := prng.Intn(n)
+			queries[i] = ival{lo, lo}
+		}
+
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = tree.Superset(queries[i%len(queries)])
+			}
+		})
+	}
+}
+
+func TestMulti(t *testing.T) {
+	is := []Interface{
+		tagIval{ival{0, 100}, 1},
+		tagIval{ival{0, 10}, 1},
+		tagIval{ival{0, 100}, 2},
+		tagIval{ival{200, 300}, 1},
+		tagIval{ival{0, 100}, 3},
+		tagIval{ival{0, 10}, 2},
+	}
+
+	tree, err := NewMulti(is)
+	if err != nil {
+		t.Fatalf("NewMulti(), unexpected error: %v", err)
+	}
+	if tree.Len() != 3 {
+		t.Errorf("Len(), got %d, want 3", tree.Len())
+	}
+
+	tags := func(items []Interface) (out []int) {
+		for _, item := range items {
+			out = append(out, item.(tagIval).tag)
+		}
+		return
+	}
+
+	tests := []struct {
+		query ival
+		want  []int
+	}{
+		{ival{0, 100}, []int{1, 2, 3}},
+		{ival{0, 10}, []int{1, 2}},
+		{ival{1, 5}, []int{1, 2}},
+		{ival{50, 60}, []int{1, 2, 3}},
+		{ival{250, 260}, []int{1}},
+		{ival{400, 500}, nil},
+	}
+	for _, tt := range tests {
+		if got := tags(tree.LookupAll(tagIval{tt.query, 0})); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("LookupAll(%v), got %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	want := `▼
+├─ 0...100 | 0...100 | 0...100
+│  └─ 0...10 | 0...10
+└─ 200...300
+`
+	if got := tree.String(); got != want {
+		t.Errorf("String(), got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// plain trees return just the match
+	plain, _ := New([]Interface{ival{0, 100}, ival{0, 10}})
+	if got := plain.LookupAll(ival{1, 5}); !reflect.DeepEqual(got, []Interface{ival{0, 10}}) {
+		t.Errorf("plain tree, LookupAll, got %v, want [0...10]", got)
+	}
+
+	empty, _ := NewMulti(nil)
+	if got := empty.LookupAll(ival{1, 5}); got != nil {
+		t.Errorf("empty tree, LookupAll, got %v, want nil", got)
+	}
+}
+
+func TestMultiPlainQueries(t *testing.T) {
+	tree, _ := NewMulti([]Interface{
+		tagIval{ival{0, 100}, 1},
+		tagIval{ival{0, 10}, 1},
+		tagIval{ival{0, 100}, 2},
+		tagIval{ival{200, 300}, 1},
+	})
+
+	// the stored items are *Multi, the queries plain items
+	q := func(lo, hi int) Interface { return tagIval{ival{lo, hi}, 0} }
+
+	if got := tree.Lookup(q(1, 5)); got == nil || got.String() != "0...10" {
+		t.Errorf("Lookup, got %v, want 0...10", got)
+	}
+	if got := tree.Lookup(q(0, 100)); got == nil || len(got.(*Multi).Items()) != 2 {
+		t.Errorf("Lookup, exact match, got %v, want the group of 2 items", got)
+	}
+	if got := tree.Lookup(q(400, 500)); got != nil {
+		t.Errorf("Lookup, got %v, want nil", got)
+	}
+	if got := tree.Superset(q(1, 5)); got == nil || !got.Equals(q(0, 100)) {
+		t.Errorf("Superset, got %v, want 0...100", got)
+	}
+	if got := tree.LookupK(q(1, 5), 2); len(got) != 2 {
+		t.Errorf("LookupK, got %v, want 2 items", got)
+	}
+	if got := tree.Within(q(0, 100)); len(got) != 2 {
+		t.Errorf("Within, got %v, want 2 items", got)
+	}
+	if got := tree.Children(q(0, 100)); len(got) != 1 {
+		t.Errorf("Children, got %v, want 1 item", got)
+	}
+	if _, ok := tree.Parent(q(0, 10)); !ok {
+		t.Errorf("Parent, want ok")
+	}
+	if _, ok := tree.Precedes(q(200, 300)); !ok {
+		t.Errorf("Precedes, want ok")
+	}
+	if _, ok := tree.Follows(q(0, 100)); !ok {
+		t.Errorf("Follows, want ok")
+	}
+	if _, ok := tree.Subtree(q(0, 100)); !ok {
+		t.Errorf("Subtree, want ok")
+	}
+
+	// Insert adds to the groups, in input order
+	ins, err := tree.Insert(tagIval{ival{0, 10}, 7}, tagIval{ival{400, 500}, 8})
+	if err != nil {
+		t.Fatalf("Insert, unexpected error: %v", err)
+	}
+	if got := ins.LookupAll(q(1, 5)); len(got) != 2 || got[1].(tagIval).tag != 7 {
+		t.Errorf("Insert, LookupAll, got %v, want 2 items, tag 7 last", got)
+	}
+	if ins.Len() != 4 {
+		t.Errorf("Insert, Len, got %d, want 4", ins.Len())
+	}
+
+	// Remove removes whole groups
+	if got := tree.Remove(q(0, 100)); got.Len() != 2 || got.Lookup(q(50, 60)) != nil {
+		t.Errorf("Remove, got %v", got)
+	}
+
+	// Replace with a plain item, the group of one
+	rep, err := tree.Replace(q(200, 300), tagIval{ival{200, 300}, 9})
+	if err != nil {
+		t.Fatalf("Replace, unexpected error: %v", err)
+	}
+	if got := rep.LookupAll(q(250, 260)); len(got) != 1 || got[0].(tagIval).tag != 9 {
+		t.Errorf("Replace, LookupAll, got %v, want tag 9", got)
+	}
+}
+
+// tagCodec, text codec for tagIval
+type tagCodec struct{}
+
+func (tagCodec) Encode(w io.Writer, i Interface) error {
+	a := i.(tagIval)
+	_, err := fmt.Fprintln(w, a.lo, a.hi, a.tag)
+	return err
+}
+
+func (tagCodec) Decode(r io.Reader) (Interface, error) {
+	var a tagIval
+	_, err := fmt.Fscanln(r, &a.lo, &a.hi, &a.tag)
+	return a, err
+}
+
+func TestExternalSort(t *testing.T) {
+	prng := rand.New(rand.NewSource(1))
+
+	// every ival twice, tagged in input order
+	var is []Interface
+	for _, iv := range generateIvals(500) {
+		is = append(is, tagIval{iv.(ival), 0}, tagIval{iv.(ival), 0})
+	}
+	prng.Shuffle(len(is), func(i, j int) { is[i], is[j] = is[j], is[i] })
+	next := make(map[ival]int)
+	for i, item := range is {
+		iv := item.(tagIval).ival
+		is[i] = tagIval{iv, next[iv]}
+		next[iv]++
+	}
+
+	want, _ := New(is)
+
+	for _, runSize := range []int{1, 3, 100, len(is)} {
+		sorted, done, err := ExternalSort(SliceIterator(is), tagCodec{}, runSize, t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := NewFromSorted(sorted)
+		if err := done(); err != nil {
+			t.Errorf("runSize %d, close, unexpected error: %v", runSize, err)
+		}
+
+		if !reflect.DeepEqual(got.items, want.items) {
+			t.Errorf("runSize %d, items differ from New", runSize)
+		}
+		if !reflect.DeepEqual(got.Duplicates(), want.Duplicates()) {
+			t.Errorf("runSize %d, Duplicates differ from New", runSize)
+		}
+	}
+
+	if _, _, err := ExternalSort(SliceIterator(is), tagCodec{}, 0, ""); err == nil {
+		t.Errorf("ExternalSort, runSize 0, want error")
+	}
+
+	// the errors of the input are returned
+	boom := errors.New("boom")
+	failing := func() (Interface, error) { return nil, boom }
+	if _, _, err := ExternalSort(failing, tagCodec{}, 10, t.TempDir()); err != boom {
+		t.Errorf("ExternalSort, failing input, got %v, want %v", err, boom)
+	}
+}
+
+func TestNewFromSorted(t *testing.T) {
+	is := generateIvals(1000)
+	want, _ := New(is)
+
+	got, err := NewFromSorted(SliceIterator(want.items))
+	if err != nil {
+		t.Fatalf("NewFromSorted, unexpected error: %v", err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("NewFromSorted, tree differs from New")
+	}
+
+	unsorted := []Interface{ival{10, 20}, ival{0, 5}}
+	if _, err := NewFromSorted(SliceIterator(unsorted)); !errors.Is(err, ErrUnsorted) {
+		t.Errorf("NewFromSorted, unsorted, got %v, want ErrUnsorted", err)
+	}
+
+	var le *LimitError
+	if _, err := NewFromSorted(SliceIterator(want.items), WithMaxItems(10)); !errors.As(err, &le) {
+		t.Errorf("NewFromSorted, WithMaxItems, got %v, want *LimitError", err)
+	}
+
+	empty, err := NewFromSorted(SliceIterator(nil))
+	if err != nil || empty.Len() != 0 {
+		t.Errorf("NewFromSorted, empty, got %v, %v", empty.Len(), err)
+	}
+}
@@ -0,0 +1,123 @@
+// Package treetest provides golden tests for trees, the way the tree package tests itself.
+//
+// Render prints the tree deterministically, one item per line indented by depth,
+// independent of optional payload columns. Equal and EqualFile compare the rendering
+// against golden strings or files.
+package treetest
+
+import (
+	"flag"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+// Update rewrites the golden files in EqualFile with the actual rendering instead of comparing.
+//
+//  go test ./... -treetest.update
+var Update = flag.Bool("treetest.update", false, "update the golden files of treetest")
+
+// Render returns the items of t in pre-order, one item per line, as stringified by the fmt.Stringer,
+// indented by two blanks per nesting level. Duplicate items are skipped.
+func Render(t *tree.Tree) string {
+	var buf strings.Builder
+	_ = t.Walk(func(depth int, item, _ tree.Interface, _ []tree.Interface) error {
+		buf.WriteString(strings.Repeat("  ", depth) + item.String() + "\n")
+		return nil
+	})
+	return buf.String()
+}
+
+// Equal reports an error on tb if the rendering of t differs from golden.
+// The common indentation of golden, e.g. in a raw string literal, and surrounding empty lines are ignored.
+func Equal(tb testing.TB, t *tree.Tree, golden string) {
+	tb.Helper()
+
+	got, want := Render(t), dedent(golden)
+	if got != want {
+		tb.Errorf("tree differs from golden:\n%s", diff(got, want))
+	}
+}
+
+// EqualFile reports an error on tb if the rendering of t differs from the golden file at path.
+// With the -treetest.update flag the golden file is written instead.
+func EqualFile(tb testing.TB, t *tree.Tree, path string) {
+	tb.Helper()
+
+	got := Render(t)
+	if *Update {
+		if err := ioutil.WriteFile(path, []byte(got), 0o644); err != nil {
+			tb.Fatal(err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if got != string(want) {
+		tb.Errorf("tree differs from golden file %s:\n%s", path, diff(got, string(want)))
+	}
+}
+
+// dedent removes surrounding empty lines and the common indentation of all non-empty lines
+func dedent(s string) string {
+	lines := strings.Split(s, "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	indent := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+
+	var buf strings.Builder
+	for _, l := range lines {
+		if len(l) >= indent && indent > 0 {
+			l = l[indent:]
+		}
+		buf.WriteString(strings.TrimRight(l, " \t") + "\n")
+	}
+	return buf.String()
+}
+
+// diff returns the lines of got and want side by side, marked where they differ
+func diff(got, want string) string {
+	gs := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	ws := strings.Split(strings.TrimSuffix(want, "\n"), "\n")
+
+	var buf strings.Builder
+	for i := 0; i < len(gs) || i < len(ws); i++ {
+		var g, w string
+		if i < len(gs) {
+			g = gs[i]
+		}
+		if i < len(ws) {
+			w = ws[i]
+		}
+		if g == w {
+			buf.WriteString("    " + g + "\n")
+			continue
+		}
+		if i < len(ws) {
+			buf.WriteString("  - " + w + "\n")
+		}
+		if i < len(gs) {
+			buf.WriteString("  + " + g + "\n")
+		}
+	}
+	return buf.String()
+}
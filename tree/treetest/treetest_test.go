@@ -0,0 +1,100 @@
+package treetest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/gaissmai/go-inet/v2/tree"
+)
+
+// simple test interval
+type ival struct {
+	lo, hi int
+}
+
+func (a ival) Equals(i tree.Interface) bool { return a == i.(ival) }
+
+func (a ival) Covers(i tree.Interface) bool {
+	b := i.(ival)
+	return a != b && a.lo <= b.lo && a.hi >= b.hi
+}
+
+func (a ival) Less(i tree.Interface) bool {
+	b := i.(ival)
+	if a.lo == b.lo {
+		return a.hi > b.hi
+	}
+	return a.lo < b.lo
+}
+
+func (a ival) String() string { return fmt.Sprintf("%d...%d", a.lo, a.hi) }
+
+// recorder records the failures of the helpers
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Helper()                                   {}
+func (r *recorder) Errorf(format string, args ...interface{}) { r.failed = true }
+
+func mkTree() *tree.Tree {
+	t, _ := tree.New([]tree.Interface{ival{200, 300}, ival{1, 100}, ival{50, 80}, ival{60, 70}, ival{90, 95}})
+	return t
+}
+
+func TestRender(t *testing.T) {
+	want := `1...100
+  50...80
+    60...70
+  90...95
+200...300
+`
+	if got := Render(mkTree()); got != want {
+		t.Errorf("Render, got:\n%swant:\n%s", got, want)
+	}
+
+	if got := Render(nil); got != "" {
+		t.Errorf("Render(nil), got %q", got)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	Equal(t, mkTree(), `
+		1...100
+		  50...80
+		    60...70
+		  90...95
+		200...300
+	`)
+
+	r := &recorder{TB: t}
+	Equal(r, mkTree(), `
+		1...100
+		  50...80
+		200...300
+	`)
+	if !r.failed {
+		t.Errorf("Equal with wrong golden, want failure")
+	}
+
+	empty, _ := tree.New(nil)
+	Equal(t, empty, "")
+}
+
+func TestEqualFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.golden")
+	if err := ioutil.WriteFile(path, []byte(Render(mkTree())), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	EqualFile(t, mkTree(), path)
+
+	r := &recorder{TB: t}
+	other, _ := tree.New([]tree.Interface{ival{1, 2}})
+	EqualFile(r, other, path)
+	if !r.failed {
+		t.Errorf("EqualFile with wrong golden, want failure")
+	}
+}
@@ -0,0 +1,201 @@
+package tree
+
+import (
+	"context"
+	"fmt"
+)
+
+// ContractError reports a violation of the documented invariants of the Interface.
+type ContractError struct {
+	// Rule is the violated invariant.
+	Rule string
+
+	// A and B are the offending items, B is nil for rules on a single item.
+	A, B Interface
+}
+
+// Error implements the error interface.
+func (e *ContractError) Error() string {
+	if e.B == nil {
+		return fmt.Sprintf("tree: contract violation, %s: %v", e.Rule, e.A)
+	}
+	return fmt.Sprintf("tree: contract violation, %s: %v, %v", e.Rule, e.A, e.B)
+}
+
+// Validate checks the documented invariants of the Interface for all items and pairs of items:
+//
+//	Less is irreflexive and asymmetric and sorts the items in a consistent (transitive) order
+//	Equals is reflexive and symmetric, equal items are not less than each other
+//	items neither less than each other must be equal
+//	Covers implies Less and not Equals
+//
+// Returns the first violation as *ContractError.
+//
+// Beware, Validate is O(n²), use the WithValidation option for large sets.
+func Validate(items []Interface) error {
+	return validate(items, 1, config{})
+}
+
+// validate every stride'th item of the sorted items.
+// With c.ctx the validation stops on cancellation, progress is reported by c.progress.
+func validate(items []Interface, stride int, c config) error {
+	sorted, err := sortedCopyWith(items, c)
+	if err != nil {
+		return err
+	}
+
+	var sample []Interface
+	for i := 0; i < len(sorted); i += stride {
+		sample = append(sample, sorted[i])
+	}
+
+	// the pairs checked since the last check for cancellation
+	pairs := 0
+	for i, a := range sample {
+		if pairs += len(sample) - i; pairs >= cancelCheck {
+			pairs = 0
+			if err := c.canceled(PhaseValidate); err != nil {
+				return err
+			}
+		}
+		if c.progress != nil && i > 0 && i%c.every == 0 {
+			c.report(PhaseValidate, i, len(sample))
+		}
+
+		if err := validateItem(a); err != nil {
+			return err
+		}
+		for _, b := range sample[i+1:] {
+			if err := validatePair(a, b); err != nil {
+				return err
+			}
+		}
+	}
+	c.report(PhaseValidate, len(sample), len(sample))
+	return nil
+}
+
+func validateItem(a Interface) error {
+	switch {
+	case a.Less(a):
+		return &ContractError{"Less is not irreflexive", a, nil}
+	case !a.Equals(a):
+		return &ContractError{"Equals is not reflexive", a, nil}
+	case a.Covers(a):
+		return &ContractError{"item covers itself", a, nil}
+	}
+	return nil
+}
+
+// validatePair, a is sorted before b
+func validatePair(a, b Interface) error {
+	ab, ba := a.Less(b), b.Less(a)
+	eq := a.Equals(b)
+
+	switch {
+	case ab && ba:
+		return &ContractError{"Less is not asymmetric", a, b}
+	case ba:
+		return &ContractError{"Less is not transitive", a, b}
+	case eq != b.Equals(a):
+		return &ContractError{"Equals is not symmetric", a, b}
+	case eq && ab:
+		return &ContractError{"equal items are less", a, b}
+	case !eq && !ab:
+		return &ContractError{"unequal items are not less", a, b}
+	case a.Covers(b) && eq:
+		return &ContractError{"Covers implies not Equals", a, b}
+	case b.Covers(a) && !eq:
+		return &ContractError{"Covers implies Less", b, a}
+	}
+	return nil
+}
+
+// Option configures New.
+type Option func(*config)
+
+type config struct {
+	validate bool
+	stride   int
+	maxItems int
+	maxDepth int
+	observer Observer
+	metrics  Metrics
+
+	// set by NewContext
+	ctx context.Context
+
+	// called every steps of the phases
+	every    int
+	progress func(phase string, done, total int)
+
+	// the storage of the build, set by Builder
+	arena *arena
+}
+
+// WithValidation validates the items in New, see Validate.
+// If sample > 0 and there are more items, only about sample items,
+// evenly spread over the sorted items, are checked against each other.
+func WithValidation(sample int) Option {
+	return func(c *config) {
+		c.validate = true
+		c.stride = sample
+	}
+}
+
+// WithMaxItems limits the number of items in New to n, more items are rejected with a *LimitError.
+// Guards services building trees from untrusted input, n <= 0 means no limit.
+func WithMaxItems(n int) Option {
+	return func(c *config) { c.maxItems = n }
+}
+
+// WithMaxDepth limits the nesting in New to n levels, root items are the first level.
+// Items nested deeper are rejected with a *LimitError, the build stops at the first one.
+// Deeply nested items degrade the lookups to O(n), n <= 0 means no limit.
+func WithMaxDepth(n int) Option {
+	return func(c *config) { c.maxDepth = n }
+}
+
+// WithProgress reports the progress of the build in New, fn is called every n items
+// and once at the end with done == total, e.g. for multi-million item imports.
+// n <= 0 reports just the end. The sort and the validation before are reported by WithPhaseProgress.
+func WithProgress(n int, fn func(done, total int)) Option {
+	return WithPhaseProgress(n, func(phase string, done, total int) {
+		if phase == PhaseBuild {
+			fn(done, total)
+		}
+	})
+}
+
+// LimitError is returned by New if the items exceed a limit, see WithMaxItems and WithMaxDepth.
+type LimitError struct {
+	// Limit is the exceeded limit, "max items" or "max depth".
+	Limit string
+
+	// Max is the configured limit.
+	Max int
+
+	// Item is the first item nested too deep, nil for max items.
+	Item Interface
+}
+
+// Error implements the error interface.
+func (e *LimitError) Error() string {
+	if e.Item == nil {
+		return fmt.Sprintf("tree: %s %d exceeded", e.Limit, e.Max)
+	}
+	return fmt.Sprintf("tree: %s %d exceeded: %v", e.Limit, e.Max, e.Item)
+}
+
+// validateItems runs the configured validation.
+func (c config) validateItems(items []Interface) error {
+	if !c.validate {
+		return nil
+	}
+
+	stride := 1
+	if c.stride > 0 && len(items) > c.stride {
+		stride = len(items) / c.stride
+	}
+	return validate(items, stride, c)
+}